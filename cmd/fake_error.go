@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/toVersus/wbtemporal/pkg/executor/jupyterhubapi"
+)
+
+// parseGoogleAPIFakeErrors converts the values of --fake-error flag in "<method>=<code>[:<message>]" format into gRPC status errors,
+// which are classified into typed errors like googleapi.ErrPermissionDenied by the fake executor.
+// The code is the name of gRPC status code, e.g. "PermissionDenied" or "PERMISSION_DENIED".
+// The method must be one of the methods of the executor interface given by iface.
+func parseGoogleAPIFakeErrors(specs map[string]string, iface reflect.Type) (map[string]error, error) {
+	errs := map[string]error{}
+	for method, spec := range specs {
+		if err := validateFakeErrorMethod(method, iface); err != nil {
+			return nil, err
+		}
+		name, message, _ := strings.Cut(spec, ":")
+		code, ok := parseStatusCode(name)
+		if !ok {
+			return nil, fmt.Errorf("invalid gRPC status code %q found in --fake-error for %s", name, method)
+		}
+		if message == "" {
+			message = code.String()
+		}
+		errs[method] = status.Error(code, message)
+	}
+	return errs, nil
+}
+
+// parseJupyterHubFakeErrors converts the values of --fake-error flag in "<method>=<code>[:<message>]" format into jupyterhubapi.HTTPError,
+// which unwraps to the sentinel errors like jupyterhubapi.ErrForbidden. The code is the HTTP status code, e.g. "403" or "429".
+func parseJupyterHubFakeErrors(specs map[string]string) (map[string]error, error) {
+	errs := map[string]error{}
+	for method, spec := range specs {
+		if err := validateFakeErrorMethod(method, reflect.TypeOf((*jupyterhubapi.Executor)(nil)).Elem()); err != nil {
+			return nil, err
+		}
+		name, message, _ := strings.Cut(spec, ":")
+		code, err := strconv.Atoi(name)
+		// JupyterHub のエラーレスポンスを模擬するので 4xx と 5xx のみ許可する
+		if err != nil || code < http.StatusBadRequest || code > 599 {
+			return nil, fmt.Errorf("invalid HTTP status code %q found in --fake-error for %s", name, method)
+		}
+		errs[method] = &jupyterhubapi.HTTPError{
+			Operation:  method,
+			StatusCode: code,
+			Message:    message,
+		}
+	}
+	return errs, nil
+}
+
+// validateFakeErrorMethod rejects the method which the fake executor does not implement,
+// because the injected error would be silently ignored.
+func validateFakeErrorMethod(method string, iface reflect.Type) error {
+	if _, ok := iface.MethodByName(method); !ok {
+		return fmt.Errorf("method %q in --fake-error is not implemented by %s", method, iface.Name())
+	}
+	return nil
+}
+
+// parseStatusCode parses the name of gRPC status code in either CamelCase or UPPER_SNAKE_CASE.
+func parseStatusCode(name string) (codes.Code, bool) {
+	normalized := strings.ReplaceAll(name, "_", "")
+	// OK はエラーではないので除外する
+	for c := codes.Canceled; c <= codes.Unauthenticated; c++ {
+		if strings.EqualFold(normalized, c.String()) {
+			return c, true
+		}
+	}
+	return codes.OK, false
+}
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/spf13/cobra"
+	"github.com/toVersus/wbtemporal/pkg/logger"
+	"github.com/toVersus/wbtemporal/pkg/workflow"
+	"go.temporal.io/api/enums/v1"
+	historypb "go.temporal.io/api/history/v1"
+	"go.temporal.io/api/workflowservice/v1"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/worker"
+)
+
+var (
+	replayCmd = &cobra.Command{
+		Use:   "replay",
+		Short: "Replay workflow histories to detect non-deterministic changes in workflows",
+		Run:   replay,
+	}
+)
+
+// replayHistory is the workflow history to replay and where it came from
+type replayHistory struct {
+	source  string
+	history *historypb.History
+}
+
+func replay(cmd *cobra.Command, args []string) {
+	logger := logger.NewDefaultLogger(logLevel)
+
+	ctx, shutdown := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer shutdown()
+
+	histories, err := loadHistoryFiles(replayHistoryFiles, replayHistoryDir)
+	if err != nil {
+		logger.Fatal("Failed to load workflow histories from files", "Error", err)
+	}
+
+	if replayWorkflowID != "" || replayQuery != "" {
+		logger.Debug(fmt.Sprintf("Trying to connect to temporal frontend: %s", frontendAddr))
+		c, err := client.Dial(client.Options{
+			HostPort:  fmt.Sprintf("dns:///%s", frontendAddr),
+			Namespace: replayNamespace,
+			Logger:    logger,
+		})
+		if err != nil {
+			logger.Fatal("Failed to create Temporal client", "Error", err)
+		}
+		defer c.Close()
+		logger.Info(fmt.Sprintf("Successfully connected to temporal frontend: %s", frontendAddr))
+
+		downloaded, err := downloadHistories(ctx, c)
+		if err != nil {
+			logger.Fatal("Failed to download workflow histories", "Error", err)
+		}
+		histories = append(histories, downloaded...)
+	}
+
+	if len(histories) == 0 {
+		logger.Fatal("No workflow histories to replay, specify --history-file, --history-dir, --workflow-id or --query")
+	}
+
+	replayer := worker.NewWorkflowReplayer()
+	workflow.RegisterAll(replayer)
+
+	var failed int
+	for _, h := range histories {
+		if err := replayer.ReplayWorkflowHistory(logger, h.history); err != nil {
+			logger.Error("Failed to replay workflow history", "source", h.source, "Error", err)
+			failed++
+			continue
+		}
+		logger.Info("Successfully replayed workflow history", "source", h.source)
+	}
+	if failed > 0 {
+		logger.Fatal(fmt.Sprintf("%d of %d workflow histories could not be replayed", failed, len(histories)))
+	}
+	logger.Info(fmt.Sprintf("All %d workflow histories replayed successfully!", len(histories)))
+}
+
+// loadHistoryFiles reads workflow histories exported in JSON format, e.g. by "temporal workflow show --output json".
+func loadHistoryFiles(files []string, dir string) ([]replayHistory, error) {
+	if dir != "" {
+		matches, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list history files in %s: %w", dir, err)
+		}
+		files = append(files, matches...)
+	}
+
+	histories := make([]replayHistory, 0, len(files))
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open history file: %w", err)
+		}
+		history, err := client.HistoryFromJSON(f, client.HistoryJSONOptions{})
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse history file %s: %w", file, err)
+		}
+		histories = append(histories, replayHistory{source: file, history: history})
+	}
+	return histories, nil
+}
+
+// downloadHistories fetches workflow histories of the specified workflow execution or the executions matching the query.
+// The histories are saved into --save-dir if it is specified, so that they can be added to the replay corpus.
+func downloadHistories(ctx context.Context, c client.Client) ([]replayHistory, error) {
+	var executions []*workflowExecution
+	if replayWorkflowID != "" {
+		executions = append(executions, &workflowExecution{id: replayWorkflowID, runID: replayRunID})
+	}
+	if replayQuery != "" {
+		listed, err := listExecutions(ctx, c, replayQuery, replayMaxExecutions)
+		if err != nil {
+			return nil, err
+		}
+		executions = append(executions, listed...)
+	}
+
+	histories := make([]replayHistory, 0, len(executions))
+	for _, execution := range executions {
+		history := &historypb.History{}
+		iter := c.GetWorkflowHistory(ctx, execution.id, execution.runID, false, enums.HISTORY_EVENT_FILTER_TYPE_ALL_EVENT)
+		for iter.HasNext() {
+			event, err := iter.Next()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get history of workflow %s: %w", execution.id, err)
+			}
+			history.Events = append(history.Events, event)
+		}
+
+		source := fmt.Sprintf("workflow %s (run %s)", execution.id, execution.runID)
+		if replaySaveDir != "" {
+			file, err := saveHistory(replaySaveDir, execution, history)
+			if err != nil {
+				return nil, err
+			}
+			source = file
+		}
+		histories = append(histories, replayHistory{source: source, history: history})
+	}
+	return histories, nil
+}
+
+type workflowExecution struct {
+	id    string
+	runID string
+}
+
+func listExecutions(ctx context.Context, c client.Client, query string, max int) ([]*workflowExecution, error) {
+	var executions []*workflowExecution
+	var nextPageToken []byte
+	for {
+		resp, err := c.ListWorkflow(ctx, &workflowservice.ListWorkflowExecutionsRequest{
+			Namespace:     replayNamespace,
+			Query:         query,
+			NextPageToken: nextPageToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list workflow executions: %w", err)
+		}
+		for _, info := range resp.GetExecutions() {
+			if max > 0 && len(executions) >= max {
+				return executions, nil
+			}
+			executions = append(executions, &workflowExecution{
+				id:    info.GetExecution().GetWorkflowId(),
+				runID: info.GetExecution().GetRunId(),
+			})
+		}
+		nextPageToken = resp.GetNextPageToken()
+		if len(nextPageToken) == 0 {
+			return executions, nil
+		}
+	}
+}
+
+func saveHistory(dir string, execution *workflowExecution, history *historypb.History) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create directory to save histories: %w", err)
+	}
+	runID := execution.runID
+	if runID == "" && len(history.Events) > 0 {
+		runID = history.Events[0].GetWorkflowExecutionStartedEventAttributes().GetOriginalExecutionRunId()
+	}
+	file := filepath.Join(dir, fmt.Sprintf("%s_%s.json", execution.id, runID))
+	f, err := os.Create(file)
+	if err != nil {
+		return "", fmt.Errorf("failed to create history file: %w", err)
+	}
+	defer f.Close()
+	marshaler := jsonpb.Marshaler{Indent: "  "}
+	if err := marshaler.Marshal(f, history); err != nil {
+		return "", fmt.Errorf("failed to write history file %s: %w", file, err)
+	}
+	return file, nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/toVersus/wbtemporal/pkg/executor/googleapi"
@@ -28,15 +29,91 @@ var (
 	wait        bool
 	silent      bool
 
+	imageProject        string
+	imageFamily         string
+	imageName           string
+	containerRepository string
+	containerTag        string
+	bootDiskType        string
+	bootDiskSizeGB      int64
+	dataDiskType        string
+	dataDiskSizeGB      int64
+	diskEncryption      string
+	kmsKey              string
+	acceleratorType     string
+	acceleratorCount    int64
+	installGpuDriver    bool
+	labels              map[string]string
+	metadata            map[string]string
+
+	noPublicIP                  bool
+	serviceAccount              string
+	tags                        []string
+	shieldedSecureBoot          bool
+	shieldedVtpm                bool
+	shieldedIntegrityMonitoring bool
+
+	postStartupScript string
+	gitRepositories   []string
+	gitConfig         map[string]string
+	pipPackages       []string
+	condaPackages     []string
+	bootstrapEnv      map[string]string
+
+	idleTimeout time.Duration
+	snapshot    string
+
+	inputNotebookFile       string
+	outputNotebookFolder    string
+	notebookParameters      map[string]string
+	executionContainerImage string
+	kernelSpec              string
+
+	cronSchedule string
+	timeZone     string
+	description  string
+
+	environment string
+	displayName string
+
+	diagnosticBucket        string
+	diagnosticPath          string
+	diagnosticRepair        bool
+	diagnosticPacketCapture bool
+	diagnosticCopyHomeFiles bool
+
 	jupyterHubUser   string
 	jupyterHubServer string
 
 	// worker flags
-	executorName string
+	executorName       string
+	fakeOperationDelay time.Duration
+	fakeErrors         map[string]string
+	fakeSpawnDelay     time.Duration
+	fakeStopDelay      time.Duration
+
+	defaultNoPublicIP                  bool
+	defaultServiceAccount              string
+	defaultTags                        []string
+	defaultShieldedSecureBoot          bool
+	defaultShieldedVtpm                bool
+	defaultShieldedIntegrityMonitoring bool
+	defaultDiskEncryption              string
+	defaultKmsKey                      string
 
 	jupyterHubBaseURL  string
 	jupyterHubAPIToken string
 
+	// replay flags
+	replayHistoryFiles  []string
+	replayHistoryDir    string
+	replayWorkflowID    string
+	replayRunID         string
+	replayQuery         string
+	replayMaxExecutions int
+	replayNamespace     string
+	replaySaveDir       string
+
 	rootCmd = &cobra.Command{
 		Use:   "wbtemporal",
 		Short: "A tool to manage Workspace instances",
@@ -53,14 +130,18 @@ func Execute() error {
 func init() {
 	rootCmd.AddCommand(workerCmd)
 	rootCmd.AddCommand(starterCmd)
+	rootCmd.AddCommand(replayCmd)
 
 	starterCmd.AddCommand(starterWorkbenchCmd)
 	starterCmd.AddCommand(starterJupyterHubCmd)
+	starterCmd.AddCommand(starterRuntimeCmd)
 	workerCmd.AddCommand(workerWorkbenchCmd)
 	workerCmd.AddCommand(workerJupyterHubCmd)
+	workerCmd.AddCommand(workerRuntimeCmd)
 
 	workerWorkbenchCmd.AddCommand(workerWorkbenchRunCmd)
 	workerJupyterHubCmd.AddCommand(workerJupyterHubRunCmd)
+	workerRuntimeCmd.AddCommand(workerRuntimeRunCmd)
 
 	starterJupyterHubCmd.AddCommand(starterJupyterHubCreateCmd)
 	starterJupyterHubCmd.AddCommand(starterJupyterHubDeleteCmd)
@@ -69,6 +150,33 @@ func init() {
 	starterWorkbenchCmd.AddCommand(starterWorkbenchDeleteCmd)
 	starterWorkbenchCmd.AddCommand(starterWorkbenchStartCmd)
 	starterWorkbenchCmd.AddCommand(starterWorkbenchStopCmd)
+	starterWorkbenchCmd.AddCommand(starterWorkbenchSetLabelsCmd)
+	starterWorkbenchCmd.AddCommand(starterWorkbenchSetIdleTimeoutCmd)
+	starterWorkbenchCmd.AddCommand(starterWorkbenchResizeCmd)
+	starterWorkbenchCmd.AddCommand(starterWorkbenchSetAcceleratorCmd)
+	starterWorkbenchCmd.AddCommand(starterWorkbenchUpgradeCmd)
+	starterWorkbenchCmd.AddCommand(starterWorkbenchRollbackCmd)
+	starterWorkbenchCmd.AddCommand(starterWorkbenchResetCmd)
+	starterWorkbenchCmd.AddCommand(starterWorkbenchDiagnoseCmd)
+	starterWorkbenchCmd.AddCommand(starterWorkbenchExecuteCmd)
+	starterWorkbenchCmd.AddCommand(starterWorkbenchScheduleCmd)
+
+	starterWorkbenchCmd.AddCommand(starterWorkbenchEnvironmentCmd)
+
+	starterWorkbenchEnvironmentCmd.AddCommand(starterWorkbenchEnvironmentCreateCmd)
+	starterWorkbenchEnvironmentCmd.AddCommand(starterWorkbenchEnvironmentListCmd)
+	starterWorkbenchEnvironmentCmd.AddCommand(starterWorkbenchEnvironmentDeleteCmd)
+
+	starterWorkbenchScheduleCmd.AddCommand(starterWorkbenchScheduleCreateCmd)
+	starterWorkbenchScheduleCmd.AddCommand(starterWorkbenchScheduleListCmd)
+	starterWorkbenchScheduleCmd.AddCommand(starterWorkbenchScheduleDeleteCmd)
+	starterWorkbenchScheduleCmd.AddCommand(starterWorkbenchScheduleTriggerCmd)
+
+	starterRuntimeCmd.AddCommand(starterRuntimeCreateCmd)
+	starterRuntimeCmd.AddCommand(starterRuntimeDeleteCmd)
+	starterRuntimeCmd.AddCommand(starterRuntimeStartCmd)
+	starterRuntimeCmd.AddCommand(starterRuntimeStopCmd)
+	starterRuntimeCmd.AddCommand(starterRuntimeSwitchCmd)
 
 	rootCmd.PersistentFlags().StringVar(&frontendAddr, "frontend-addr", "localhost:7233",
 		`temporal frontend addr to connect, use "<host>:<port>" format`)
@@ -85,6 +193,17 @@ func init() {
 	starterWorkbenchCmd.PersistentFlags().BoolVar(&silent, "silent", false, "silent mode, do not print periodic activity status")
 	starterWorkbenchCmd.MarkPersistentFlagRequired("name")
 
+	// list ではスケジュール名を使わないので、Workbench の必須の --name を上書きして各コマンドで検証する
+	starterWorkbenchScheduleCmd.PersistentFlags().StringVar(&name, "name", "", "ID of the notebook schedule, required except for list")
+
+	starterWorkbenchEnvironmentCmd.PersistentFlags().StringVar(&name, "name", "", "ID of the notebook environment, required except for list")
+
+	starterRuntimeCmd.PersistentFlags().StringVar(&name, "name", "", "name of the runtime")
+	starterRuntimeCmd.PersistentFlags().StringVar(&location, "location", "asia-northeast1", "region of the runtime")
+	starterRuntimeCmd.PersistentFlags().StringVar(&projectID, "project-id", "gcp-sample", "Google Cloud project ID")
+	starterRuntimeCmd.PersistentFlags().BoolVar(&silent, "silent", false, "silent mode, do not print periodic activity status")
+	starterRuntimeCmd.MarkPersistentFlagRequired("name")
+
 	starterJupyterHubCmd.PersistentFlags().StringVar(&jupyterHubUser, "user", "", "JupyterHub user name")
 	starterJupyterHubCmd.PersistentFlags().StringVar(&jupyterHubServer, "server", "", "JupyterHub user server name")
 
@@ -92,19 +211,190 @@ func init() {
 	starterWorkbenchCreateCmd.Flags().StringVar(&machineType, "machine-type", "n1-standard-1", "machine type of the Workspace instance")
 	starterWorkbenchCreateCmd.Flags().StringVar(&network, "network", "", "VPC network name that Workspace instance belongs to")
 	starterWorkbenchCreateCmd.Flags().StringVar(&subnet, "subnet", "", "VPC subnet name that Workspace instance belongs to")
+	starterWorkbenchCreateCmd.Flags().StringVar(&environment, "environment", "",
+		`notebook environment in the zone to create Workspace instance from, e.g. "pytorch-2", cannot be used with VM image or container image flags`)
+	starterWorkbenchCreateCmd.Flags().StringVar(&imageProject, "image-project", "",
+		fmt.Sprintf("Google Cloud project of the VM image, defaults to %q (%q for %q executor) unless container image is specified",
+			googleapi.DefaultImageProject, googleapi.DefaultImageProjectV2, googleapi.ExecutorNameGoogleAPIV2))
+	starterWorkbenchCreateCmd.Flags().StringVar(&imageFamily, "image-family", "",
+		fmt.Sprintf("VM image family, defaults to %q (%q for %q executor) unless image name, container image or environment is specified",
+			googleapi.DefaultImageFamily, googleapi.DefaultImageFamilyV2, googleapi.ExecutorNameGoogleAPIV2))
+	starterWorkbenchCreateCmd.Flags().StringVar(&imageName, "image-name", "", "VM image name, cannot be used with --image-family")
+	starterWorkbenchCreateCmd.Flags().StringVar(&containerRepository, "container-repository", "",
+		`custom container image repository, e.g. "gcr.io/deeplearning-platform-release/base-cpu", cannot be used with VM image flags`)
+	starterWorkbenchCreateCmd.Flags().StringVar(&containerTag, "container-tag", "", `tag of the custom container image, defaults to "latest"`)
+	starterWorkbenchCreateCmd.Flags().StringVar(&bootDiskType, "boot-disk-type", googleapi.DefaultDiskType, "boot disk type, one of PD_STANDARD, PD_SSD, PD_BALANCED or PD_EXTREME")
+	starterWorkbenchCreateCmd.Flags().Int64Var(&bootDiskSizeGB, "boot-disk-size", googleapi.DefaultBootDiskSizeGB, "boot disk size in GB")
+	starterWorkbenchCreateCmd.Flags().StringVar(&dataDiskType, "data-disk-type", googleapi.DefaultDiskType, "data disk type, one of PD_STANDARD, PD_SSD, PD_BALANCED or PD_EXTREME")
+	starterWorkbenchCreateCmd.Flags().Int64Var(&dataDiskSizeGB, "data-disk-size", googleapi.DefaultDataDiskSizeGB, "data disk size in GB")
+	starterWorkbenchCreateCmd.Flags().StringVar(&diskEncryption, "disk-encryption", "", `disk encryption, "GMEK" or "CMEK"`)
+	starterWorkbenchCreateCmd.Flags().StringVar(&kmsKey, "kms-key", "", "Cloud KMS key to encrypt disks, required for CMEK disk encryption")
+	starterWorkbenchCreateCmd.Flags().StringVar(&acceleratorType, "accelerator-type", "", `accelerator attached to the Workspace instance, e.g. "NVIDIA_TESLA_T4"`)
+	starterWorkbenchCreateCmd.Flags().Int64Var(&acceleratorCount, "accelerator-count", 1, "number of accelerators, only used with --accelerator-type")
+	starterWorkbenchCreateCmd.Flags().BoolVar(&installGpuDriver, "install-gpu-driver", false, "install NVIDIA GPU driver automatically")
+	starterWorkbenchCreateCmd.Flags().StringToStringVar(&labels, "label", nil, `label attached to the Workspace instance, use "<key>=<value>" format, can be specified multiple times`)
+	starterWorkbenchCreateCmd.Flags().StringToStringVar(&metadata, "metadata", nil, `custom metadata of the Workspace instance, use "<key>=<value>" format, can be specified multiple times`)
+	starterWorkbenchCreateCmd.Flags().BoolVar(&noPublicIP, "no-public-ip", false, "create the Workspace instance without public IP address, defaults to the security profile of the worker")
+	starterWorkbenchCreateCmd.Flags().StringVar(&serviceAccount, "service-account", "", "service account email that the Workspace instance runs as, defaults to the security profile of the worker")
+	starterWorkbenchCreateCmd.Flags().StringSliceVar(&tags, "tag", nil, "network tag attached to the Workspace instance, can be specified multiple times, defaults to the security profile of the worker")
+	starterWorkbenchCreateCmd.Flags().BoolVar(&shieldedSecureBoot, "shielded-secure-boot", false, "enable Shielded VM secure boot, defaults to the security profile of the worker")
+	starterWorkbenchCreateCmd.Flags().BoolVar(&shieldedVtpm, "shielded-vtpm", false, "enable Shielded VM vTPM, defaults to the security profile of the worker")
+	starterWorkbenchCreateCmd.Flags().BoolVar(&shieldedIntegrityMonitoring, "shielded-integrity-monitoring", false, "enable Shielded VM integrity monitoring, defaults to the security profile of the worker")
+	starterWorkbenchCreateCmd.Flags().StringVar(&postStartupScript, "post-startup-script", "",
+		`script run after the Workspace instance is set up, either local file path, "gs://" or "https://" URL`)
+	starterWorkbenchCreateCmd.Flags().StringArrayVar(&gitRepositories, "git-repo", nil,
+		`git repository cloned into the home directory, use "<url>[#<branch>]" format, can be specified multiple times`)
+	starterWorkbenchCreateCmd.Flags().StringToStringVar(&gitConfig, "git-config", nil, `system-wide git config, use "<key>=<value>" format, can be specified multiple times`)
+	starterWorkbenchCreateCmd.Flags().StringArrayVar(&pipPackages, "pip-package", nil, `package installed with pip, e.g. "pandas==2.0.3", can be specified multiple times`)
+	starterWorkbenchCreateCmd.Flags().StringArrayVar(&condaPackages, "conda-package", nil, "package installed with conda, can be specified multiple times")
+	starterWorkbenchCreateCmd.Flags().StringToStringVar(&bootstrapEnv, "env", nil, `environment variable exported in the Workspace instance, use "<key>=<value>" format, can be specified multiple times`)
+	starterWorkbenchCreateCmd.Flags().DurationVar(&idleTimeout, "idle-timeout", 0,
+		fmt.Sprintf("idle time before the Workspace instance is shut down automatically, between %s and %s, idle shutdown is disabled if omitted", googleapi.MinIdleTimeout, googleapi.MaxIdleTimeout))
 	starterWorkbenchCreateCmd.MarkPersistentFlagRequired("email")
 	starterWorkbenchCreateCmd.MarkPersistentFlagRequired("network")
 	starterWorkbenchCreateCmd.MarkPersistentFlagRequired("subnet")
 
+	starterWorkbenchSetLabelsCmd.Flags().StringToStringVar(&labels, "label", nil, `label attached to the Workspace instance, use "<key>=<value>" format, can be specified multiple times, existing labels not specified are removed`)
+
+	starterWorkbenchSetIdleTimeoutCmd.Flags().DurationVar(&idleTimeout, "idle-timeout", 0,
+		fmt.Sprintf("idle time before the Workspace instance is shut down automatically, between %s and %s", googleapi.MinIdleTimeout, googleapi.MaxIdleTimeout))
+	starterWorkbenchSetIdleTimeoutCmd.MarkFlagRequired("idle-timeout")
+
+	starterWorkbenchResizeCmd.Flags().StringVar(&machineType, "machine-type", "",
+		"new machine type of the Workspace instance, the running instance is restarted and rolled back if the machine type is rejected")
+	starterWorkbenchResizeCmd.MarkFlagRequired("machine-type")
+
+	starterWorkbenchSetAcceleratorCmd.Flags().StringVar(&acceleratorType, "accelerator-type", "",
+		`new accelerator attached to the Workspace instance, e.g. "NVIDIA_TESLA_T4", the running instance is restarted`)
+	starterWorkbenchSetAcceleratorCmd.Flags().Int64Var(&acceleratorCount, "accelerator-count", 1, "number of accelerators")
+	starterWorkbenchSetAcceleratorCmd.MarkFlagRequired("accelerator-type")
+
+	starterWorkbenchRollbackCmd.Flags().StringVar(&snapshot, "snapshot", "",
+		"snapshot in the upgrade history to restore the Workspace instance from, defaults to the one taken before the latest upgrade")
+
+	starterWorkbenchDiagnoseCmd.Flags().StringVar(&diagnosticBucket, "gcs-bucket", "", `Cloud Storage bucket name to upload diagnostic logs to, without "gs://" prefix`)
+	starterWorkbenchDiagnoseCmd.Flags().StringVar(&diagnosticPath, "relative-path", "", "path in the bucket to upload diagnostic logs to, defaults to the root of the bucket")
+	starterWorkbenchDiagnoseCmd.Flags().BoolVar(&diagnosticRepair, "repair", false, "run the repair service on the Workspace instance as well")
+	starterWorkbenchDiagnoseCmd.Flags().BoolVar(&diagnosticPacketCapture, "packet-capture", false, "capture packets of the Workspace instance for 30 seconds")
+	starterWorkbenchDiagnoseCmd.Flags().BoolVar(&diagnosticCopyHomeFiles, "copy-home-files", false, "include the contents of the home directory in diagnostic logs")
+	starterWorkbenchDiagnoseCmd.MarkFlagRequired("gcs-bucket")
+
+	// 他のコマンドと変数を共有しているので、既定値は ExecutionOption 側で補完する
+	starterWorkbenchExecuteCmd.Flags().StringVar(&inputNotebookFile, "input-notebook", "", `notebook to execute, use "gs://<bucket>/<path>.ipynb" format`)
+	starterWorkbenchExecuteCmd.Flags().StringVar(&outputNotebookFolder, "output-folder", "", `Cloud Storage folder the executed notebook is written to, use "gs://<bucket>/<path>" format`)
+	starterWorkbenchExecuteCmd.Flags().StringToStringVar(&notebookParameters, "parameter", nil,
+		`parameter passed to the cell tagged with "parameters" in the notebook, use "<key>=<value>" format, can be specified multiple times`)
+	starterWorkbenchExecuteCmd.Flags().StringVar(&machineType, "machine-type", "", fmt.Sprintf("machine type running the notebook, defaults to %q", googleapi.DefaultExecutionMachineType))
+	starterWorkbenchExecuteCmd.Flags().StringVar(&acceleratorType, "accelerator-type", "", `accelerator attached to the machine, e.g. "NVIDIA_TESLA_T4"`)
+	starterWorkbenchExecuteCmd.Flags().Int64Var(&acceleratorCount, "accelerator-count", 1, "number of accelerators, only used with --accelerator-type")
+	starterWorkbenchExecuteCmd.Flags().StringVar(&executionContainerImage, "container-image", "", fmt.Sprintf("container image running the notebook, defaults to %q", googleapi.DefaultExecutionContainerImage))
+	starterWorkbenchExecuteCmd.Flags().StringVar(&serviceAccount, "service-account", "", "service account email the notebook runs as, defaults to Compute Engine default service account")
+	starterWorkbenchExecuteCmd.Flags().StringVar(&kernelSpec, "kernel-spec", "", "kernel to run the notebook, required if it doesn't match the kernel in the notebook")
+	starterWorkbenchExecuteCmd.Flags().StringToStringVar(&labels, "label", nil, `label attached to the execution, use "<key>=<value>" format, can be specified multiple times`)
+	starterWorkbenchExecuteCmd.MarkFlagRequired("input-notebook")
+	starterWorkbenchExecuteCmd.MarkFlagRequired("output-folder")
+
+	// 環境ではイメージを明示させるので、既定値は設定しない
+	starterWorkbenchEnvironmentCreateCmd.Flags().StringVar(&displayName, "display-name", "", "human readable name of the notebook environment")
+	starterWorkbenchEnvironmentCreateCmd.Flags().StringVar(&description, "description", "", "description of the notebook environment")
+	starterWorkbenchEnvironmentCreateCmd.Flags().StringVar(&imageProject, "image-project", "",
+		fmt.Sprintf("Google Cloud project of the VM image, defaults to %q unless container image is specified", googleapi.DefaultImageProject))
+	starterWorkbenchEnvironmentCreateCmd.Flags().StringVar(&imageFamily, "image-family", "", "VM image family, the latest image in the family is used")
+	starterWorkbenchEnvironmentCreateCmd.Flags().StringVar(&imageName, "image-name", "", "VM image name, cannot be used with --image-family")
+	starterWorkbenchEnvironmentCreateCmd.Flags().StringVar(&containerRepository, "container-repository", "",
+		`custom container image repository, e.g. "gcr.io/deeplearning-platform-release/base-cpu", cannot be used with VM image flags`)
+	starterWorkbenchEnvironmentCreateCmd.Flags().StringVar(&containerTag, "container-tag", "", `tag of the custom container image, defaults to "latest"`)
+
+	// 他のコマンドと変数を共有しているので、既定値は ScheduleOption と ExecutionOption 側で補完する
+	starterWorkbenchScheduleCreateCmd.Flags().StringVar(&cronSchedule, "cron", "", `when the notebook is executed in unix-cron format, e.g. "0 9 * * 1-5"`)
+	starterWorkbenchScheduleCreateCmd.Flags().StringVar(&timeZone, "time-zone", "", fmt.Sprintf(`IANA time zone the cron schedule is interpreted in, e.g. "Asia/Tokyo", defaults to %q`, googleapi.DefaultScheduleTimeZone))
+	starterWorkbenchScheduleCreateCmd.Flags().StringVar(&description, "description", "", "description of the notebook schedule")
+	starterWorkbenchScheduleCreateCmd.Flags().StringVar(&inputNotebookFile, "input-notebook", "", `notebook to execute, use "gs://<bucket>/<path>.ipynb" format`)
+	starterWorkbenchScheduleCreateCmd.Flags().StringVar(&outputNotebookFolder, "output-folder", "", `Cloud Storage folder the executed notebooks are written to, use "gs://<bucket>/<path>" format`)
+	starterWorkbenchScheduleCreateCmd.Flags().StringToStringVar(&notebookParameters, "parameter", nil,
+		`parameter passed to the cell tagged with "parameters" in the notebook, use "<key>=<value>" format, can be specified multiple times`)
+	starterWorkbenchScheduleCreateCmd.Flags().StringVar(&machineType, "machine-type", "", fmt.Sprintf("machine type running the notebook, defaults to %q", googleapi.DefaultExecutionMachineType))
+	starterWorkbenchScheduleCreateCmd.Flags().StringVar(&acceleratorType, "accelerator-type", "", `accelerator attached to the machine, e.g. "NVIDIA_TESLA_T4"`)
+	starterWorkbenchScheduleCreateCmd.Flags().Int64Var(&acceleratorCount, "accelerator-count", 1, "number of accelerators, only used with --accelerator-type")
+	starterWorkbenchScheduleCreateCmd.Flags().StringVar(&executionContainerImage, "container-image", "", fmt.Sprintf("container image running the notebook, defaults to %q", googleapi.DefaultExecutionContainerImage))
+	starterWorkbenchScheduleCreateCmd.Flags().StringVar(&serviceAccount, "service-account", "", "service account email the notebook runs as, defaults to Compute Engine default service account")
+	starterWorkbenchScheduleCreateCmd.Flags().StringVar(&kernelSpec, "kernel-spec", "", "kernel to run the notebook, required if it doesn't match the kernel in the notebook")
+	starterWorkbenchScheduleCreateCmd.Flags().StringToStringVar(&labels, "label", nil, `label attached to the executions, use "<key>=<value>" format, can be specified multiple times`)
+	starterWorkbenchScheduleCreateCmd.MarkFlagRequired("cron")
+	starterWorkbenchScheduleCreateCmd.MarkFlagRequired("input-notebook")
+	starterWorkbenchScheduleCreateCmd.MarkFlagRequired("output-folder")
+
+	// 他のコマンドと変数を共有しているので、既定値は RuntimeOption 側で補完する
+	starterRuntimeCreateCmd.Flags().StringVar(&email, "email", "", "Google account email address of the runtime owner")
+	starterRuntimeCreateCmd.Flags().StringVar(&machineType, "machine-type", "", fmt.Sprintf("machine type of the runtime, defaults to %q", googleapi.DefaultRuntimeMachineType))
+	starterRuntimeCreateCmd.Flags().StringVar(&network, "network", "", "VPC network name that the runtime belongs to, Google-managed network is used if omitted")
+	starterRuntimeCreateCmd.Flags().StringVar(&subnet, "subnet", "", "VPC subnet name that the runtime belongs to")
+	starterRuntimeCreateCmd.Flags().StringVar(&dataDiskType, "data-disk-type", "",
+		fmt.Sprintf("data disk type, one of PD_STANDARD, PD_SSD, PD_BALANCED or PD_EXTREME, defaults to %s", googleapi.DefaultDiskType))
+	starterRuntimeCreateCmd.Flags().Int64Var(&dataDiskSizeGB, "data-disk-size", 0, fmt.Sprintf("data disk size in GB, defaults to %d", googleapi.DefaultRuntimeDataDiskSizeGB))
+	starterRuntimeCreateCmd.Flags().StringVar(&kmsKey, "kms-key", "", "Cloud KMS key to encrypt disks, Google-managed key is used if omitted")
+	starterRuntimeCreateCmd.Flags().StringVar(&acceleratorType, "accelerator-type", "", `accelerator attached to the runtime, e.g. "NVIDIA_TESLA_T4"`)
+	starterRuntimeCreateCmd.Flags().Int64Var(&acceleratorCount, "accelerator-count", 1, "number of accelerators, only used with --accelerator-type")
+	starterRuntimeCreateCmd.Flags().BoolVar(&installGpuDriver, "install-gpu-driver", false, "install NVIDIA GPU driver automatically")
+	starterRuntimeCreateCmd.Flags().BoolVar(&noPublicIP, "no-public-ip", false, "create the runtime without public IP address, requires --network and --subnet")
+	starterRuntimeCreateCmd.Flags().StringSliceVar(&tags, "tag", nil, "network tag attached to the runtime, can be specified multiple times")
+	starterRuntimeCreateCmd.Flags().StringToStringVar(&labels, "label", nil, `label attached to the runtime, use "<key>=<value>" format, can be specified multiple times`)
+	starterRuntimeCreateCmd.Flags().DurationVar(&idleTimeout, "idle-timeout", 0,
+		fmt.Sprintf("idle time before the runtime is shut down automatically, between %s and %s, idle shutdown is disabled if omitted", googleapi.MinIdleTimeout, googleapi.MaxIdleTimeout))
+	starterRuntimeCreateCmd.MarkFlagRequired("email")
+
+	starterRuntimeSwitchCmd.Flags().StringVar(&machineType, "machine-type", "", "new machine type of the runtime, the running runtime is restarted")
+	starterRuntimeSwitchCmd.Flags().StringVar(&acceleratorType, "accelerator-type", "", `new accelerator attached to the runtime, e.g. "NVIDIA_TESLA_T4", accelerator is detached if omitted`)
+	starterRuntimeSwitchCmd.Flags().Int64Var(&acceleratorCount, "accelerator-count", 1, "number of accelerators, only used with --accelerator-type")
+	starterRuntimeSwitchCmd.MarkFlagRequired("machine-type")
+
 	workerWorkbenchRunCmd.Flags().StringVar(&executorName, "executor-name", googleapi.ExecutorNameGoogleAPI,
+		fmt.Sprintf(`change backend implementation to intract with Google Cloud, current available executor is %q, %q for Workbench Instances and %q for testing`,
+			googleapi.ExecutorNameGoogleAPI, googleapi.ExecutorNameGoogleAPIV2, googleapi.ExecutorNameFakeClient))
+	workerWorkbenchRunCmd.Flags().DurationVar(&fakeOperationDelay, "fake-operation-delay", googleapi.DefaultFakeOperationDelay,
+		fmt.Sprintf("time it takes for long-running operations to finish, only used by %q executor", googleapi.ExecutorNameFakeClient))
+	workerWorkbenchRunCmd.Flags().StringToStringVar(&fakeErrors, "fake-error", nil,
+		fmt.Sprintf(`make executor method always fail with the gRPC status code, use "<method>=<code>[:<message>]" format, e.g. "StartNotebookInstance=ResourceExhausted:quota exceeded", only used by %q executor`,
+			googleapi.ExecutorNameFakeClient))
+	workerWorkbenchRunCmd.Flags().BoolVar(&defaultNoPublicIP, "default-no-public-ip", false, "create Workspace instances without public IP address unless requested otherwise")
+	workerWorkbenchRunCmd.Flags().StringVar(&defaultServiceAccount, "default-service-account", "", "service account email used by Workspace instances unless requested otherwise")
+	workerWorkbenchRunCmd.Flags().StringSliceVar(&defaultTags, "default-tag", nil, "network tag attached to Workspace instances unless requested otherwise, can be specified multiple times")
+	workerWorkbenchRunCmd.Flags().BoolVar(&defaultShieldedSecureBoot, "default-shielded-secure-boot", false, "enable Shielded VM secure boot unless requested otherwise")
+	workerWorkbenchRunCmd.Flags().BoolVar(&defaultShieldedVtpm, "default-shielded-vtpm", true, "enable Shielded VM vTPM unless requested otherwise")
+	workerWorkbenchRunCmd.Flags().BoolVar(&defaultShieldedIntegrityMonitoring, "default-shielded-integrity-monitoring", true, "enable Shielded VM integrity monitoring unless requested otherwise")
+	workerWorkbenchRunCmd.Flags().StringVar(&defaultDiskEncryption, "default-disk-encryption", "", `disk encryption used unless requested otherwise, "GMEK" or "CMEK"`)
+	workerWorkbenchRunCmd.Flags().StringVar(&defaultKmsKey, "default-kms-key", "", "Cloud KMS key to encrypt disks unless requested otherwise, required for CMEK disk encryption")
+
+	workerRuntimeRunCmd.Flags().StringVar(&executorName, "executor-name", googleapi.ExecutorNameGoogleAPI,
 		fmt.Sprintf(`change backend implementation to intract with Google Cloud, current available executor is %q and %q for testing`,
 			googleapi.ExecutorNameGoogleAPI, googleapi.ExecutorNameFakeClient))
+	workerRuntimeRunCmd.Flags().DurationVar(&fakeOperationDelay, "fake-operation-delay", googleapi.DefaultFakeOperationDelay,
+		fmt.Sprintf("time it takes for long-running operations to finish, only used by %q executor", googleapi.ExecutorNameFakeClient))
+	workerRuntimeRunCmd.Flags().StringToStringVar(&fakeErrors, "fake-error", nil,
+		fmt.Sprintf(`make executor method always fail with the gRPC status code, use "<method>=<code>[:<message>]" format, e.g. "CreateRuntime=ResourceExhausted:quota exceeded", only used by %q executor`,
+			googleapi.ExecutorNameFakeClient))
 
 	workerJupyterHubRunCmd.Flags().StringVar(&jupyterHubBaseURL, "base-url", "", "JupyterHub base URL")
 	workerJupyterHubRunCmd.Flags().StringVar(&jupyterHubAPIToken, "token", "", "JupyterHub API token")
-	workerJupyterHubRunCmd.Flags().StringVar(&executorName, "executor-name", googleapi.ExecutorNameGoogleAPI,
-		fmt.Sprintf(`change backend implementation to intract with Google Cloud, current available executor is %q and %q for testing`,
-			jupyterhubapi.ExecutorNameJupyterHub, googleapi.ExecutorNameFakeClient))
+	workerJupyterHubRunCmd.Flags().StringVar(&executorName, "executor-name", jupyterhubapi.ExecutorNameJupyterHub,
+		fmt.Sprintf(`change backend implementation to intract with JupyterHub, current available executor is %q and %q for testing`,
+			jupyterhubapi.ExecutorNameJupyterHub, jupyterhubapi.ExecutorNameFakeClient))
+	workerJupyterHubRunCmd.Flags().DurationVar(&fakeSpawnDelay, "fake-spawn-delay", jupyterhubapi.DefaultFakeSpawnDelay,
+		fmt.Sprintf("time it takes for user servers to become ready, only used by %q executor", jupyterhubapi.ExecutorNameFakeClient))
+	workerJupyterHubRunCmd.Flags().DurationVar(&fakeStopDelay, "fake-stop-delay", jupyterhubapi.DefaultFakeStopDelay,
+		fmt.Sprintf("time it takes for user servers to be stopped, only used by %q executor", jupyterhubapi.ExecutorNameFakeClient))
+	workerJupyterHubRunCmd.Flags().StringToStringVar(&fakeErrors, "fake-error", nil,
+		fmt.Sprintf(`make executor method always fail with the HTTP status code, use "<method>=<code>[:<message>]" format, e.g. "CreateUserServer=429:too many requests", only used by %q executor`,
+			jupyterhubapi.ExecutorNameFakeClient))
+
+	replayCmd.Flags().StringSliceVar(&replayHistoryFiles, "history-file", nil, "workflow history file in JSON format to replay, can be specified multiple times")
+	replayCmd.Flags().StringVar(&replayHistoryDir, "history-dir", "", "directory containing workflow history files in JSON format to replay")
+	replayCmd.Flags().StringVar(&replayWorkflowID, "workflow-id", "", "ID of the workflow execution to download history from Temporal frontend")
+	replayCmd.Flags().StringVar(&replayRunID, "run-id", "", "run ID of the workflow execution, latest run is used if omitted")
+	replayCmd.Flags().StringVar(&replayQuery, "query", "", `visibility query to list workflow executions to replay, e.g. "WorkflowType='CreateWorkbench'"`)
+	replayCmd.Flags().IntVar(&replayMaxExecutions, "max-executions", 100, "maximum number of workflow executions listed by --query")
+	replayCmd.Flags().StringVar(&replayNamespace, "namespace", "default", "Temporal namespace of the workflow executions")
+	replayCmd.Flags().StringVar(&replaySaveDir, "save-dir", "", "directory to save downloaded workflow histories, e.g. testdata/histories")
 
 	logger := logger.NewDefaultLogger(logLevel)
 
@@ -116,4 +406,21 @@ func init() {
 
 type ExecutorOpts struct {
 	Name string
+	// FakeOperationDelay is the time it takes for long-running operations of fake executor to finish
+	FakeOperationDelay time.Duration
+	// FakeErrors is the error message injected into each method of fake executor
+	FakeErrors map[string]string
+	// FakeSpawnDelay is the time it takes for user servers of fake executor to become ready
+	FakeSpawnDelay time.Duration
+	// FakeStopDelay is the time it takes for user servers of fake executor to be stopped
+	FakeStopDelay time.Duration
+}
+
+// changedBool returns the value of the bool flag, or nil if the flag is not specified
+// so that the default of the worker's security profile is applied.
+func changedBool(cmd *cobra.Command, name string, value bool) *bool {
+	if !cmd.Flags().Changed(name) {
+		return nil
+	}
+	return &value
 }
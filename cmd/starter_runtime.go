@@ -0,0 +1,10 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+var (
+	starterRuntimeCmd = &cobra.Command{
+		Use:   "runtime",
+		Short: "Trigger Temporal workflow to manage managed notebook runtime",
+	}
+)
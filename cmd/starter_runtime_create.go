@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toVersus/wbtemporal/pkg/executor/googleapi"
+	"github.com/toVersus/wbtemporal/pkg/logger"
+	"github.com/toVersus/wbtemporal/pkg/workflow"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+)
+
+var (
+	starterRuntimeCreateCmd = &cobra.Command{
+		Use:   "create",
+		Short: "Trigger Temporal workflow to create managed notebook runtime",
+		Run:   starterRuntimeCreate,
+	}
+)
+
+func starterRuntimeCreate(cmd *cobra.Command, args []string) {
+	logger := logger.NewDefaultLogger(logLevel)
+
+	logger.Debug(fmt.Sprintf("Trying to connect to temporal frontend: %s", frontendAddr))
+	c, err := client.Dial(client.Options{
+		HostPort: fmt.Sprintf("dns:///%s", frontendAddr),
+		Logger:   logger,
+	})
+	if err != nil {
+		logger.Fatal("Failed to create Temporal client", "Error", err)
+	}
+	defer c.Close()
+	logger.Info(fmt.Sprintf("Successfully connected to temporal frontend: %s", frontendAddr))
+
+	logger.Info("Register signal handler to shutdown starter process gracefully")
+	ctx, shutdown := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer shutdown()
+
+	options := &googleapi.RuntimeOption{
+		Name:             name,
+		Email:            email,
+		Location:         location,
+		ProjectId:        projectID,
+		MachineType:      machineType,
+		Network:          network,
+		Subnet:           subnet,
+		DataDiskType:     dataDiskType,
+		DataDiskSizeGB:   dataDiskSizeGB,
+		KmsKey:           kmsKey,
+		AcceleratorType:  acceleratorType,
+		InstallGpuDriver: installGpuDriver,
+		NoPublicIP:       noPublicIP,
+		Tags:             tags,
+		Labels:           labels,
+		IdleTimeout:      idleTimeout,
+	}
+	if acceleratorType != "" {
+		options.AcceleratorCount = acceleratorCount
+	}
+	if err := options.Validate(); err != nil {
+		logger.Fatal("Invalid option to create runtime", "Error", err)
+	}
+	// ワークフロー ID が Workbench のインスタンスと衝突しないように、リソースの種類を含める
+	workflowID := fmt.Sprintf("%s-runtime-create", name)
+	logger.Info("Trigger workflow to create runtime")
+	run, err := c.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: workflow.CreateRuntimeTaskQueue,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval: time.Minute,
+			MaximumAttempts: 3,
+		},
+	}, workflow.CreateRuntime, options)
+	if err != nil {
+		logger.Fatal("Could not trigger create runtime workflow", "Error", err)
+	}
+	if !wait {
+		logger.Info("Successfully triggered create runtime workflow!")
+		return
+	}
+
+	if !silent {
+		// Poll and print workflow status using separate goroutine
+		watcher := &workflowWatcher{c: c, id: workflowID}
+		logger.Info("Start workflow watcher")
+		watcher.run(ctx)
+	}
+
+	var status googleapi.RuntimeStatus
+	if err := run.Get(ctx, &status); err != nil {
+		logger.Fatal("Could not complete create runtime workflow", "Error", err)
+	}
+	logger.Info("Runtime workflow completed successfully", "name", status.Name, "url", status.URL, "status", status.Status)
+	// Just to be sure, sleep 3 seconds before exiting
+	time.Sleep(3 * time.Second)
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toVersus/wbtemporal/pkg/executor/googleapi"
+	"github.com/toVersus/wbtemporal/pkg/logger"
+	"github.com/toVersus/wbtemporal/pkg/workflow"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+)
+
+var (
+	starterRuntimeDeleteCmd = &cobra.Command{
+		Use:   "delete",
+		Short: "Trigger Temporal workflow to delete managed notebook runtime",
+		Run:   starterRuntimeDelete,
+	}
+)
+
+func starterRuntimeDelete(cmd *cobra.Command, args []string) {
+	logger := logger.NewDefaultLogger(logLevel)
+
+	logger.Debug(fmt.Sprintf("Trying to connect to temporal frontend: %s", frontendAddr))
+	c, err := client.Dial(client.Options{
+		HostPort: fmt.Sprintf("dns:///%s", frontendAddr),
+		Logger:   logger,
+	})
+	if err != nil {
+		logger.Fatal("Failed to create Temporal client", "Error", err)
+	}
+	defer c.Close()
+	logger.Info(fmt.Sprintf("Successfully connected to temporal frontend: %s", frontendAddr))
+
+	logger.Info("Register signal handler to shutdown starter process gracefully")
+	ctx, shutdown := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer shutdown()
+
+	options := &googleapi.RuntimeOption{
+		Name:      name,
+		Location:  location,
+		ProjectId: projectID,
+	}
+	// ワークフロー ID が Workbench のインスタンスと衝突しないように、リソースの種類を含める
+	workflowID := fmt.Sprintf("%s-runtime-delete", name)
+	logger.Info("Trigger workflow to delete runtime")
+	run, err := c.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: workflow.DeleteRuntimeTaskQueue,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval: time.Minute,
+			MaximumAttempts: 3,
+		},
+	}, workflow.DeleteRuntime, options)
+	if err != nil {
+		logger.Fatal("Could not trigger delete runtime workflow", "Error", err)
+	}
+	if !wait {
+		logger.Info("Successfully triggered delete runtime workflow!")
+		return
+	}
+
+	if !silent {
+		// Poll and print workflow status using separate goroutine
+		watcher := &workflowWatcher{c: c, id: workflowID}
+		logger.Info("Start workflow watcher")
+		watcher.run(ctx)
+	}
+
+	if err := run.Get(ctx, nil); err != nil {
+		logger.Fatal("Could not complete delete runtime workflow", "Error", err)
+	}
+	logger.Info("Successfully completed delete runtime workflow!")
+	// Just to be sure, sleep 3 seconds before exiting
+	time.Sleep(3 * time.Second)
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toVersus/wbtemporal/pkg/executor/googleapi"
+	"github.com/toVersus/wbtemporal/pkg/logger"
+	"github.com/toVersus/wbtemporal/pkg/workflow"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+)
+
+var (
+	starterRuntimeStartCmd = &cobra.Command{
+		Use:   "start",
+		Short: "Trigger Temporal workflow to start managed notebook runtime",
+		Run:   starterRuntimeStart,
+	}
+)
+
+func starterRuntimeStart(cmd *cobra.Command, args []string) {
+	logger := logger.NewDefaultLogger(logLevel)
+
+	logger.Debug(fmt.Sprintf("Trying to connect to temporal frontend: %s", frontendAddr))
+	c, err := client.Dial(client.Options{
+		HostPort: fmt.Sprintf("dns:///%s", frontendAddr),
+		Logger:   logger,
+	})
+	if err != nil {
+		logger.Fatal("Failed to create Temporal client", "Error", err)
+	}
+	defer c.Close()
+	logger.Info(fmt.Sprintf("Successfully connected to temporal frontend: %s", frontendAddr))
+
+	logger.Info("Register signal handler to shutdown starter process gracefully")
+	ctx, shutdown := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer shutdown()
+
+	options := &googleapi.RuntimeOption{
+		Name:      name,
+		Location:  location,
+		ProjectId: projectID,
+	}
+	// ワークフロー ID が Workbench のインスタンスと衝突しないように、リソースの種類を含める
+	workflowID := fmt.Sprintf("%s-runtime-start", name)
+	logger.Info("Trigger workflow to start runtime")
+	run, err := c.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: workflow.StartRuntimeTaskQueue,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval: time.Minute,
+			MaximumAttempts: 3,
+		},
+	}, workflow.StartRuntime, options)
+	if err != nil {
+		logger.Fatal("Could not trigger start runtime workflow", "Error", err)
+	}
+	if !wait {
+		logger.Info("Successfully triggered start runtime workflow!")
+		return
+	}
+
+	if !silent {
+		// Poll and print workflow status using separate goroutine
+		watcher := &workflowWatcher{c: c, id: workflowID}
+		logger.Info("Start workflow watcher")
+		watcher.run(ctx)
+	}
+
+	var status googleapi.RuntimeStatus
+	if err := run.Get(ctx, &status); err != nil {
+		logger.Fatal("Could not complete start runtime workflow", "Error", err)
+	}
+	logger.Info("Runtime workflow completed successfully", "name", status.Name, "url", status.URL, "status", status.Status)
+	// Just to be sure, sleep 3 seconds before exiting
+	time.Sleep(3 * time.Second)
+}
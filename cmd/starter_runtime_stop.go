@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toVersus/wbtemporal/pkg/executor/googleapi"
+	"github.com/toVersus/wbtemporal/pkg/logger"
+	"github.com/toVersus/wbtemporal/pkg/workflow"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+)
+
+var (
+	starterRuntimeStopCmd = &cobra.Command{
+		Use:   "stop",
+		Short: "Trigger Temporal workflow to stop managed notebook runtime",
+		Run:   starterRuntimeStop,
+	}
+)
+
+func starterRuntimeStop(cmd *cobra.Command, args []string) {
+	logger := logger.NewDefaultLogger(logLevel)
+
+	logger.Debug(fmt.Sprintf("Trying to connect to temporal frontend: %s", frontendAddr))
+	c, err := client.Dial(client.Options{
+		HostPort: fmt.Sprintf("dns:///%s", frontendAddr),
+		Logger:   logger,
+	})
+	if err != nil {
+		logger.Fatal("Failed to create Temporal client", "Error", err)
+	}
+	defer c.Close()
+	logger.Info(fmt.Sprintf("Successfully connected to temporal frontend: %s", frontendAddr))
+
+	logger.Info("Register signal handler to shutdown starter process gracefully")
+	ctx, shutdown := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer shutdown()
+
+	options := &googleapi.RuntimeOption{
+		Name:      name,
+		Location:  location,
+		ProjectId: projectID,
+	}
+	// ワークフロー ID が Workbench のインスタンスと衝突しないように、リソースの種類を含める
+	workflowID := fmt.Sprintf("%s-runtime-stop", name)
+	logger.Info("Trigger workflow to stop runtime")
+	run, err := c.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: workflow.StopRuntimeTaskQueue,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval: time.Minute,
+			MaximumAttempts: 3,
+		},
+	}, workflow.StopRuntime, options)
+	if err != nil {
+		logger.Fatal("Could not trigger stop runtime workflow", "Error", err)
+	}
+	if !wait {
+		logger.Info("Successfully triggered stop runtime workflow!")
+		return
+	}
+
+	if !silent {
+		// Poll and print workflow status using separate goroutine
+		watcher := &workflowWatcher{c: c, id: workflowID}
+		logger.Info("Start workflow watcher")
+		watcher.run(ctx)
+	}
+
+	if err := run.Get(ctx, nil); err != nil {
+		logger.Fatal("Could not complete stop runtime workflow", "Error", err)
+	}
+	logger.Info("Successfully completed stop runtime workflow!")
+	// Just to be sure, sleep 3 seconds before exiting
+	time.Sleep(3 * time.Second)
+}
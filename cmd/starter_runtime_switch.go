@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toVersus/wbtemporal/pkg/executor/googleapi"
+	"github.com/toVersus/wbtemporal/pkg/logger"
+	"github.com/toVersus/wbtemporal/pkg/workflow"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+)
+
+var (
+	starterRuntimeSwitchCmd = &cobra.Command{
+		Use:   "switch",
+		Short: "Trigger Temporal workflow to change machine type and accelerator of managed notebook runtime",
+		Run:   starterRuntimeSwitch,
+	}
+)
+
+func starterRuntimeSwitch(cmd *cobra.Command, args []string) {
+	logger := logger.NewDefaultLogger(logLevel)
+
+	logger.Debug(fmt.Sprintf("Trying to connect to temporal frontend: %s", frontendAddr))
+	c, err := client.Dial(client.Options{
+		HostPort: fmt.Sprintf("dns:///%s", frontendAddr),
+		Logger:   logger,
+	})
+	if err != nil {
+		logger.Fatal("Failed to create Temporal client", "Error", err)
+	}
+	defer c.Close()
+	logger.Info(fmt.Sprintf("Successfully connected to temporal frontend: %s", frontendAddr))
+
+	logger.Info("Register signal handler to shutdown starter process gracefully")
+	ctx, shutdown := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer shutdown()
+
+	options := &googleapi.RuntimeOption{
+		Name:            name,
+		Location:        location,
+		ProjectId:       projectID,
+		MachineType:     machineType,
+		AcceleratorType: acceleratorType,
+	}
+	if acceleratorType != "" {
+		options.AcceleratorCount = acceleratorCount
+	}
+	if err := options.ValidateSwitch(); err != nil {
+		logger.Fatal("Invalid option to switch runtime", "Error", err)
+	}
+	// ワークフロー ID が Workbench のインスタンスと衝突しないように、リソースの種類を含める
+	workflowID := fmt.Sprintf("%s-runtime-switch", name)
+	logger.Info("Trigger workflow to switch runtime")
+	run, err := c.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: workflow.SwitchRuntimeTaskQueue,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval: time.Minute,
+			MaximumAttempts: 3,
+		},
+	}, workflow.SwitchRuntime, options)
+	if err != nil {
+		logger.Fatal("Could not trigger switch runtime workflow", "Error", err)
+	}
+	if !wait {
+		logger.Info("Successfully triggered switch runtime workflow!")
+		return
+	}
+
+	if !silent {
+		// Poll and print workflow status using separate goroutine
+		watcher := &workflowWatcher{c: c, id: workflowID}
+		logger.Info("Start workflow watcher")
+		watcher.run(ctx)
+	}
+
+	var status googleapi.RuntimeStatus
+	if err := run.Get(ctx, &status); err != nil {
+		logger.Fatal("Could not complete switch runtime workflow", "Error", err)
+	}
+	logger.Info("Runtime workflow completed successfully", "name", status.Name, "machineType", status.MachineType, "acceleratorType", status.AcceleratorType, "acceleratorCount", status.AcceleratorCount, "status", status.Status)
+	// Just to be sure, sleep 3 seconds before exiting
+	time.Sleep(3 * time.Second)
+}
@@ -3,7 +3,9 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
@@ -50,6 +52,43 @@ func starterWorkbenchCreate(cmd *cobra.Command, args []string) {
 		MachineType: machineType,
 		Network:     network,
 		Subnet:      subnet,
+
+		Environment:         environment,
+		ImageProject:        imageProject,
+		ImageFamily:         imageFamily,
+		ImageName:           imageName,
+		ContainerRepository: containerRepository,
+		ContainerTag:        containerTag,
+		BootDiskType:        bootDiskType,
+		BootDiskSizeGB:      bootDiskSizeGB,
+		DataDiskType:        dataDiskType,
+		DataDiskSizeGB:      dataDiskSizeGB,
+		DiskEncryption:      diskEncryption,
+		KmsKey:              kmsKey,
+		AcceleratorType:     acceleratorType,
+		InstallGpuDriver:    installGpuDriver,
+		Labels:              labels,
+		Metadata:            metadata,
+
+		NoPublicIP:                  changedBool(cmd, "no-public-ip", noPublicIP),
+		ServiceAccount:              serviceAccount,
+		Tags:                        tags,
+		ShieldedSecureBoot:          changedBool(cmd, "shielded-secure-boot", shieldedSecureBoot),
+		ShieldedVtpm:                changedBool(cmd, "shielded-vtpm", shieldedVtpm),
+		ShieldedIntegrityMonitoring: changedBool(cmd, "shielded-integrity-monitoring", shieldedIntegrityMonitoring),
+
+		IdleTimeout: idleTimeout,
+	}
+	if acceleratorType != "" {
+		options.AcceleratorCount = acceleratorCount
+	}
+	bootstrap, err := newBootstrap()
+	if err != nil {
+		logger.Fatal("Invalid bootstrap option to create workspace instance", "Error", err)
+	}
+	options.Bootstrap = bootstrap
+	if err := options.Validate(); err != nil {
+		logger.Fatal("Invalid option to create workspace instance", "Error", err)
 	}
 	workflowID := fmt.Sprintf("%s-create", name)
 	logger.Info("Trigger workflow to create new workspace instance")
@@ -84,3 +123,31 @@ func starterWorkbenchCreate(cmd *cobra.Command, args []string) {
 	// Just to be sure, sleep 3 seconds before exiting
 	time.Sleep(3 * time.Second)
 }
+
+// newBootstrap builds the bootstrap from flags, or returns nil if no bootstrap flag is specified.
+func newBootstrap() (*googleapi.Bootstrap, error) {
+	if postStartupScript == "" && len(gitRepositories) == 0 && len(gitConfig) == 0 &&
+		len(pipPackages) == 0 && len(condaPackages) == 0 && len(bootstrapEnv) == 0 {
+		return nil, nil
+	}
+	bootstrap := &googleapi.Bootstrap{
+		GitConfig:     gitConfig,
+		PipPackages:   pipPackages,
+		CondaPackages: condaPackages,
+		Env:           bootstrapEnv,
+	}
+	for _, repo := range gitRepositories {
+		bootstrap.GitRepositories = append(bootstrap.GitRepositories, googleapi.ParseGitRepository(repo))
+	}
+	if strings.Contains(postStartupScript, "://") {
+		bootstrap.PostStartupScriptURL = postStartupScript
+	} else if postStartupScript != "" {
+		// ローカルのファイルはワーカーから読めるとは限らないので、内容をワークフローの入力に含める
+		b, err := os.ReadFile(postStartupScript)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read post-startup script: %w", err)
+		}
+		bootstrap.PostStartupScript = string(b)
+	}
+	return bootstrap, nil
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toVersus/wbtemporal/pkg/executor/googleapi"
+	"github.com/toVersus/wbtemporal/pkg/logger"
+	"github.com/toVersus/wbtemporal/pkg/workflow"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+)
+
+var (
+	starterWorkbenchDiagnoseCmd = &cobra.Command{
+		Use:   "diagnose",
+		Short: "Trigger Temporal workflow to collect diagnostic logs of Workspace instance",
+		Run:   starterWorkbenchDiagnose,
+	}
+)
+
+func starterWorkbenchDiagnose(cmd *cobra.Command, args []string) {
+	logger := logger.NewDefaultLogger(logLevel)
+
+	logger.Debug(fmt.Sprintf("Trying to connect to temporal frontend: %s", frontendAddr))
+	c, err := client.Dial(client.Options{
+		HostPort: fmt.Sprintf("dns:///%s", frontendAddr),
+		Logger:   logger,
+	})
+	if err != nil {
+		logger.Fatal("Failed to create Temporal client", "Error", err)
+	}
+	defer c.Close()
+	logger.Info(fmt.Sprintf("Successfully connected to temporal frontend: %s", frontendAddr))
+
+	logger.Info("Register signal handler to shutdown starter process gracefully")
+	ctx, shutdown := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer shutdown()
+
+	options := &googleapi.Option{
+		Name:      name,
+		Location:  location,
+		Zone:      zone,
+		ProjectId: projectID,
+		Diagnostic: &googleapi.Diagnostic{
+			GcsBucket:     diagnosticBucket,
+			RelativePath:  diagnosticPath,
+			Repair:        diagnosticRepair,
+			PacketCapture: diagnosticPacketCapture,
+			CopyHomeFiles: diagnosticCopyHomeFiles,
+		},
+	}
+	if err := options.Diagnostic.Validate(); err != nil {
+		logger.Fatal("Invalid diagnostic configuration to collect diagnostic logs of workspace instance", "Error", err)
+	}
+	workflowID := fmt.Sprintf("%s-diagnose", name)
+	logger.Info("Trigger workflow to diagnose workspace instance")
+	run, err := c.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: workflow.DiagnoseWorkbenchTaskQueue,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval: time.Minute,
+			MaximumAttempts: 3,
+		},
+	}, workflow.DiagnoseWorkbench, options)
+	if err != nil {
+		logger.Fatal("Could not trigger diagnose workspace workflow", "Error", err)
+	}
+	if !wait {
+		logger.Info("Successfully triggered diagnose workspace workflow!")
+		return
+	}
+
+	if !silent {
+		// Poll and print workflow status using separate goroutine
+		watcher := &workflowWatcher{c: c, id: workflowID}
+		logger.Info("Start workflow watcher")
+		watcher.run(ctx)
+	}
+
+	var location string
+	if err := run.Get(ctx, &location); err != nil {
+		logger.Fatal("Could not complete diagnose workspace workflow", "Error", err)
+	}
+	logger.Info("Workspace workflow completed successfully", "name", name, "location", location)
+	// Just to be sure, sleep 3 seconds before exiting
+	time.Sleep(3 * time.Second)
+}
@@ -0,0 +1,10 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+var (
+	starterWorkbenchEnvironmentCmd = &cobra.Command{
+		Use:   "environment",
+		Short: "Trigger Temporal workflow to manage catalog of environments that Workspace instances are created from",
+	}
+)
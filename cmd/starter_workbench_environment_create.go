@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toVersus/wbtemporal/pkg/executor/googleapi"
+	"github.com/toVersus/wbtemporal/pkg/logger"
+	"github.com/toVersus/wbtemporal/pkg/workflow"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+)
+
+var (
+	starterWorkbenchEnvironmentCreateCmd = &cobra.Command{
+		Use:   "create",
+		Short: "Trigger Temporal workflow to create notebook environment",
+		Run:   starterWorkbenchEnvironmentCreate,
+	}
+)
+
+func starterWorkbenchEnvironmentCreate(cmd *cobra.Command, args []string) {
+	logger := logger.NewDefaultLogger(logLevel)
+
+	if name == "" {
+		logger.Fatal("Name of the notebook environment is required, use --name")
+	}
+
+	logger.Debug(fmt.Sprintf("Trying to connect to temporal frontend: %s", frontendAddr))
+	c, err := client.Dial(client.Options{
+		HostPort: fmt.Sprintf("dns:///%s", frontendAddr),
+		Logger:   logger,
+	})
+	if err != nil {
+		logger.Fatal("Failed to create Temporal client", "Error", err)
+	}
+	defer c.Close()
+	logger.Info(fmt.Sprintf("Successfully connected to temporal frontend: %s", frontendAddr))
+
+	logger.Info("Register signal handler to shutdown starter process gracefully")
+	ctx, shutdown := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer shutdown()
+
+	options := &googleapi.EnvironmentOption{
+		Name:                name,
+		Zone:                zone,
+		ProjectId:           projectID,
+		DisplayName:         displayName,
+		Description:         description,
+		ImageProject:        imageProject,
+		ImageFamily:         imageFamily,
+		ImageName:           imageName,
+		ContainerRepository: containerRepository,
+		ContainerTag:        containerTag,
+	}
+	if err := options.Validate(); err != nil {
+		logger.Fatal("Invalid option to create notebook environment", "Error", err)
+	}
+	// ワークフロー ID が Workbench のインスタンスと衝突しないように、リソースの種類を含める
+	workflowID := fmt.Sprintf("%s-environment-create", name)
+	logger.Info("Trigger workflow to create notebook environment")
+	run, err := c.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: workflow.CreateEnvironmentTaskQueue,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval: time.Minute,
+			MaximumAttempts: 3,
+		},
+	}, workflow.CreateEnvironment, options)
+	if err != nil {
+		logger.Fatal("Could not trigger create notebook environment workflow", "Error", err)
+	}
+	if !wait {
+		logger.Info("Successfully triggered create notebook environment workflow!")
+		return
+	}
+
+	if !silent {
+		// Poll and print workflow status using separate goroutine
+		watcher := &workflowWatcher{c: c, id: workflowID}
+		logger.Info("Start workflow watcher")
+		watcher.run(ctx)
+	}
+
+	var status googleapi.EnvironmentStatus
+	if err := run.Get(ctx, &status); err != nil {
+		logger.Fatal("Could not complete create notebook environment workflow", "Error", err)
+	}
+	logger.Info("Environment workflow completed successfully", "name", status.Name, "imageFamily", status.ImageFamily, "imageName", status.ImageName, "containerRepository", status.ContainerRepository)
+	// Just to be sure, sleep 3 seconds before exiting
+	time.Sleep(3 * time.Second)
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toVersus/wbtemporal/pkg/executor/googleapi"
+	"github.com/toVersus/wbtemporal/pkg/logger"
+	"github.com/toVersus/wbtemporal/pkg/workflow"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+)
+
+var (
+	starterWorkbenchEnvironmentDeleteCmd = &cobra.Command{
+		Use:   "delete",
+		Short: "Trigger Temporal workflow to delete notebook environment, Workspace instances created from it are kept",
+		Run:   starterWorkbenchEnvironmentDelete,
+	}
+)
+
+func starterWorkbenchEnvironmentDelete(cmd *cobra.Command, args []string) {
+	logger := logger.NewDefaultLogger(logLevel)
+
+	if name == "" {
+		logger.Fatal("Name of the notebook environment is required, use --name")
+	}
+
+	logger.Debug(fmt.Sprintf("Trying to connect to temporal frontend: %s", frontendAddr))
+	c, err := client.Dial(client.Options{
+		HostPort: fmt.Sprintf("dns:///%s", frontendAddr),
+		Logger:   logger,
+	})
+	if err != nil {
+		logger.Fatal("Failed to create Temporal client", "Error", err)
+	}
+	defer c.Close()
+	logger.Info(fmt.Sprintf("Successfully connected to temporal frontend: %s", frontendAddr))
+
+	logger.Info("Register signal handler to shutdown starter process gracefully")
+	ctx, shutdown := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer shutdown()
+
+	options := &googleapi.EnvironmentOption{
+		Name:      name,
+		Zone:      zone,
+		ProjectId: projectID,
+	}
+	// ワークフロー ID が Workbench のインスタンスと衝突しないように、リソースの種類を含める
+	workflowID := fmt.Sprintf("%s-environment-delete", name)
+	logger.Info("Trigger workflow to delete notebook environment")
+	run, err := c.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: workflow.DeleteEnvironmentTaskQueue,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval: time.Minute,
+			MaximumAttempts: 3,
+		},
+	}, workflow.DeleteEnvironment, options)
+	if err != nil {
+		logger.Fatal("Could not trigger delete notebook environment workflow", "Error", err)
+	}
+	if !wait {
+		logger.Info("Successfully triggered delete notebook environment workflow!")
+		return
+	}
+
+	if !silent {
+		// Poll and print workflow status using separate goroutine
+		watcher := &workflowWatcher{c: c, id: workflowID}
+		logger.Info("Start workflow watcher")
+		watcher.run(ctx)
+	}
+
+	if err := run.Get(ctx, nil); err != nil {
+		logger.Fatal("Could not complete delete notebook environment workflow", "Error", err)
+	}
+	logger.Info("Successfully completed delete notebook environment workflow!")
+	// Just to be sure, sleep 3 seconds before exiting
+	time.Sleep(3 * time.Second)
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toVersus/wbtemporal/pkg/executor/googleapi"
+	"github.com/toVersus/wbtemporal/pkg/logger"
+	"github.com/toVersus/wbtemporal/pkg/workflow"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+)
+
+var (
+	starterWorkbenchEnvironmentListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "Trigger Temporal workflow to list notebook environments in the zone",
+		Run:   starterWorkbenchEnvironmentList,
+	}
+)
+
+func starterWorkbenchEnvironmentList(cmd *cobra.Command, args []string) {
+	logger := logger.NewDefaultLogger(logLevel)
+
+	logger.Debug(fmt.Sprintf("Trying to connect to temporal frontend: %s", frontendAddr))
+	c, err := client.Dial(client.Options{
+		HostPort: fmt.Sprintf("dns:///%s", frontendAddr),
+		Logger:   logger,
+	})
+	if err != nil {
+		logger.Fatal("Failed to create Temporal client", "Error", err)
+	}
+	defer c.Close()
+	logger.Info(fmt.Sprintf("Successfully connected to temporal frontend: %s", frontendAddr))
+
+	logger.Info("Register signal handler to shutdown starter process gracefully")
+	ctx, shutdown := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer shutdown()
+
+	options := &googleapi.EnvironmentOption{
+		Zone:      zone,
+		ProjectId: projectID,
+	}
+	// 一覧の取得は特定の環境を対象にしないので、プロジェクトとゾーンからワークフロー ID を決める
+	workflowID := fmt.Sprintf("%s-%s-environment-list", projectID, zone)
+	logger.Info("Trigger workflow to list notebook environments")
+	run, err := c.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: workflow.ListEnvironmentsTaskQueue,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval: time.Minute,
+			MaximumAttempts: 3,
+		},
+	}, workflow.ListEnvironments, options)
+	if err != nil {
+		logger.Fatal("Could not trigger list notebook environments workflow", "Error", err)
+	}
+	// 一覧は結果を表示しないと意味がないので、--wait の指定に関わらず完了を待つ
+	if !silent {
+		// Poll and print workflow status using separate goroutine
+		watcher := &workflowWatcher{c: c, id: workflowID}
+		logger.Info("Start workflow watcher")
+		watcher.run(ctx)
+	}
+
+	var environments []googleapi.EnvironmentStatus
+	if err := run.Get(ctx, &environments); err != nil {
+		logger.Fatal("Could not complete list notebook environments workflow", "Error", err)
+	}
+	for _, s := range environments {
+		logger.Info("Notebook environment", "name", s.Name, "displayName", s.DisplayName, "imageProject", s.ImageProject, "imageFamily", s.ImageFamily,
+			"imageName", s.ImageName, "containerRepository", s.ContainerRepository, "containerTag", s.ContainerTag)
+	}
+	logger.Info("Environment workflow completed successfully", "count", len(environments))
+}
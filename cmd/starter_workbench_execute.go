@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toVersus/wbtemporal/pkg/executor/googleapi"
+	"github.com/toVersus/wbtemporal/pkg/logger"
+	"github.com/toVersus/wbtemporal/pkg/workflow"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+)
+
+var (
+	starterWorkbenchExecuteCmd = &cobra.Command{
+		Use:   "execute",
+		Short: "Trigger Temporal workflow to execute notebook on Cloud Storage without Workspace instance",
+		Run:   starterWorkbenchExecute,
+	}
+)
+
+func starterWorkbenchExecute(cmd *cobra.Command, args []string) {
+	logger := logger.NewDefaultLogger(logLevel)
+
+	logger.Debug(fmt.Sprintf("Trying to connect to temporal frontend: %s", frontendAddr))
+	c, err := client.Dial(client.Options{
+		HostPort: fmt.Sprintf("dns:///%s", frontendAddr),
+		Logger:   logger,
+	})
+	if err != nil {
+		logger.Fatal("Failed to create Temporal client", "Error", err)
+	}
+	defer c.Close()
+	logger.Info(fmt.Sprintf("Successfully connected to temporal frontend: %s", frontendAddr))
+
+	logger.Info("Register signal handler to shutdown starter process gracefully")
+	ctx, shutdown := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer shutdown()
+
+	options := &googleapi.ExecutionOption{
+		Name:                 name,
+		Location:             location,
+		ProjectId:            projectID,
+		InputNotebookFile:    inputNotebookFile,
+		OutputNotebookFolder: outputNotebookFolder,
+		Parameters:           notebookParameters,
+		MachineType:          machineType,
+		AcceleratorType:      acceleratorType,
+		ContainerImage:       executionContainerImage,
+		ServiceAccount:       serviceAccount,
+		KernelSpec:           kernelSpec,
+		Labels:               labels,
+	}
+	if acceleratorType != "" {
+		options.AcceleratorCount = acceleratorCount
+	}
+	if err := options.Validate(); err != nil {
+		logger.Fatal("Invalid option to execute notebook", "Error", err)
+	}
+	workflowID := fmt.Sprintf("%s-execute", name)
+	logger.Info("Trigger workflow to execute notebook")
+	run, err := c.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: workflow.ExecuteNotebookTaskQueue,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval: time.Minute,
+			MaximumAttempts: 3,
+		},
+	}, workflow.ExecuteNotebook, options)
+	if err != nil {
+		logger.Fatal("Could not trigger execute notebook workflow", "Error", err)
+	}
+	if !wait {
+		logger.Info("Successfully triggered execute notebook workflow!")
+		return
+	}
+
+	if !silent {
+		// Poll and print workflow status using separate goroutine
+		watcher := &workflowWatcher{c: c, id: workflowID}
+		logger.Info("Start workflow watcher")
+		watcher.run(ctx)
+	}
+
+	var status googleapi.ExecutionStatus
+	if err := run.Get(ctx, &status); err != nil {
+		logger.Fatal("Could not complete execute notebook workflow", "Error", err)
+	}
+	if !status.State.IsSucceeded() {
+		logger.Fatal("Notebook execution finished unsuccessfully", "name", status.Name, "state", status.State, "job", status.JobURI)
+	}
+	logger.Info("Workspace workflow completed successfully", "name", status.Name, "state", status.State, "output", status.OutputNotebookFile, "job", status.JobURI)
+	// Just to be sure, sleep 3 seconds before exiting
+	time.Sleep(3 * time.Second)
+}
@@ -0,0 +1,10 @@
+package cmd
+
+import "github.com/spf13/cobra"
+
+var (
+	starterWorkbenchScheduleCmd = &cobra.Command{
+		Use:   "schedule",
+		Short: "Trigger Temporal workflow to manage notebook schedules executing notebook periodically",
+	}
+)
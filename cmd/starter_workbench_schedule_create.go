@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toVersus/wbtemporal/pkg/executor/googleapi"
+	"github.com/toVersus/wbtemporal/pkg/logger"
+	"github.com/toVersus/wbtemporal/pkg/workflow"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+)
+
+var (
+	starterWorkbenchScheduleCreateCmd = &cobra.Command{
+		Use:   "create",
+		Short: "Trigger Temporal workflow to create notebook schedule",
+		Run:   starterWorkbenchScheduleCreate,
+	}
+)
+
+func starterWorkbenchScheduleCreate(cmd *cobra.Command, args []string) {
+	logger := logger.NewDefaultLogger(logLevel)
+
+	if name == "" {
+		logger.Fatal("Name of the notebook schedule is required, use --name")
+	}
+
+	logger.Debug(fmt.Sprintf("Trying to connect to temporal frontend: %s", frontendAddr))
+	c, err := client.Dial(client.Options{
+		HostPort: fmt.Sprintf("dns:///%s", frontendAddr),
+		Logger:   logger,
+	})
+	if err != nil {
+		logger.Fatal("Failed to create Temporal client", "Error", err)
+	}
+	defer c.Close()
+	logger.Info(fmt.Sprintf("Successfully connected to temporal frontend: %s", frontendAddr))
+
+	logger.Info("Register signal handler to shutdown starter process gracefully")
+	ctx, shutdown := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer shutdown()
+
+	options := &googleapi.ScheduleOption{
+		Name:         name,
+		Location:     location,
+		ProjectId:    projectID,
+		CronSchedule: cronSchedule,
+		TimeZone:     timeZone,
+		Description:  description,
+		Template: &googleapi.ExecutionOption{
+			InputNotebookFile:    inputNotebookFile,
+			OutputNotebookFolder: outputNotebookFolder,
+			Parameters:           notebookParameters,
+			MachineType:          machineType,
+			AcceleratorType:      acceleratorType,
+			ContainerImage:       executionContainerImage,
+			ServiceAccount:       serviceAccount,
+			KernelSpec:           kernelSpec,
+			Labels:               labels,
+		},
+	}
+	if acceleratorType != "" {
+		options.Template.AcceleratorCount = acceleratorCount
+	}
+	if err := options.Validate(); err != nil {
+		logger.Fatal("Invalid option to create notebook schedule", "Error", err)
+	}
+	// ワークフロー ID が Workbench のインスタンスと衝突しないように、リソースの種類を含める
+	workflowID := fmt.Sprintf("%s-schedule-create", name)
+	logger.Info("Trigger workflow to create notebook schedule")
+	run, err := c.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: workflow.CreateScheduleTaskQueue,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval: time.Minute,
+			MaximumAttempts: 3,
+		},
+	}, workflow.CreateSchedule, options)
+	if err != nil {
+		logger.Fatal("Could not trigger create notebook schedule workflow", "Error", err)
+	}
+	if !wait {
+		logger.Info("Successfully triggered create notebook schedule workflow!")
+		return
+	}
+
+	if !silent {
+		// Poll and print workflow status using separate goroutine
+		watcher := &workflowWatcher{c: c, id: workflowID}
+		logger.Info("Start workflow watcher")
+		watcher.run(ctx)
+	}
+
+	var status googleapi.ScheduleStatus
+	if err := run.Get(ctx, &status); err != nil {
+		logger.Fatal("Could not complete create notebook schedule workflow", "Error", err)
+	}
+	logger.Info("Schedule workflow completed successfully", "name", status.Name, "state", status.State, "cron", status.CronSchedule, "timeZone", status.TimeZone)
+	// Just to be sure, sleep 3 seconds before exiting
+	time.Sleep(3 * time.Second)
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toVersus/wbtemporal/pkg/executor/googleapi"
+	"github.com/toVersus/wbtemporal/pkg/logger"
+	"github.com/toVersus/wbtemporal/pkg/workflow"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+)
+
+var (
+	starterWorkbenchScheduleDeleteCmd = &cobra.Command{
+		Use:   "delete",
+		Short: "Trigger Temporal workflow to delete notebook schedule, executions created by the schedule are kept",
+		Run:   starterWorkbenchScheduleDelete,
+	}
+)
+
+func starterWorkbenchScheduleDelete(cmd *cobra.Command, args []string) {
+	logger := logger.NewDefaultLogger(logLevel)
+
+	if name == "" {
+		logger.Fatal("Name of the notebook schedule is required, use --name")
+	}
+
+	logger.Debug(fmt.Sprintf("Trying to connect to temporal frontend: %s", frontendAddr))
+	c, err := client.Dial(client.Options{
+		HostPort: fmt.Sprintf("dns:///%s", frontendAddr),
+		Logger:   logger,
+	})
+	if err != nil {
+		logger.Fatal("Failed to create Temporal client", "Error", err)
+	}
+	defer c.Close()
+	logger.Info(fmt.Sprintf("Successfully connected to temporal frontend: %s", frontendAddr))
+
+	logger.Info("Register signal handler to shutdown starter process gracefully")
+	ctx, shutdown := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer shutdown()
+
+	options := &googleapi.ScheduleOption{
+		Name:      name,
+		Location:  location,
+		ProjectId: projectID,
+	}
+	// ワークフロー ID が Workbench のインスタンスと衝突しないように、リソースの種類を含める
+	workflowID := fmt.Sprintf("%s-schedule-delete", name)
+	logger.Info("Trigger workflow to delete notebook schedule")
+	run, err := c.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: workflow.DeleteScheduleTaskQueue,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval: time.Minute,
+			MaximumAttempts: 3,
+		},
+	}, workflow.DeleteSchedule, options)
+	if err != nil {
+		logger.Fatal("Could not trigger delete notebook schedule workflow", "Error", err)
+	}
+	if !wait {
+		logger.Info("Successfully triggered delete notebook schedule workflow!")
+		return
+	}
+
+	if !silent {
+		// Poll and print workflow status using separate goroutine
+		watcher := &workflowWatcher{c: c, id: workflowID}
+		logger.Info("Start workflow watcher")
+		watcher.run(ctx)
+	}
+
+	if err := run.Get(ctx, nil); err != nil {
+		logger.Fatal("Could not complete delete notebook schedule workflow", "Error", err)
+	}
+	logger.Info("Successfully completed delete notebook schedule workflow!")
+	// Just to be sure, sleep 3 seconds before exiting
+	time.Sleep(3 * time.Second)
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toVersus/wbtemporal/pkg/executor/googleapi"
+	"github.com/toVersus/wbtemporal/pkg/logger"
+	"github.com/toVersus/wbtemporal/pkg/workflow"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+)
+
+var (
+	starterWorkbenchScheduleListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "Trigger Temporal workflow to list notebook schedules in the location",
+		Run:   starterWorkbenchScheduleList,
+	}
+)
+
+func starterWorkbenchScheduleList(cmd *cobra.Command, args []string) {
+	logger := logger.NewDefaultLogger(logLevel)
+
+	logger.Debug(fmt.Sprintf("Trying to connect to temporal frontend: %s", frontendAddr))
+	c, err := client.Dial(client.Options{
+		HostPort: fmt.Sprintf("dns:///%s", frontendAddr),
+		Logger:   logger,
+	})
+	if err != nil {
+		logger.Fatal("Failed to create Temporal client", "Error", err)
+	}
+	defer c.Close()
+	logger.Info(fmt.Sprintf("Successfully connected to temporal frontend: %s", frontendAddr))
+
+	logger.Info("Register signal handler to shutdown starter process gracefully")
+	ctx, shutdown := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer shutdown()
+
+	options := &googleapi.ScheduleOption{
+		Location:  location,
+		ProjectId: projectID,
+	}
+	// 一覧の取得は特定のスケジュールを対象にしないので、プロジェクトとロケーションからワークフロー ID を決める
+	workflowID := fmt.Sprintf("%s-%s-schedule-list", projectID, location)
+	logger.Info("Trigger workflow to list notebook schedules")
+	run, err := c.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: workflow.ListSchedulesTaskQueue,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval: time.Minute,
+			MaximumAttempts: 3,
+		},
+	}, workflow.ListSchedules, options)
+	if err != nil {
+		logger.Fatal("Could not trigger list notebook schedules workflow", "Error", err)
+	}
+	// 一覧は結果を表示しないと意味がないので、--wait の指定に関わらず完了を待つ
+	if !silent {
+		// Poll and print workflow status using separate goroutine
+		watcher := &workflowWatcher{c: c, id: workflowID}
+		logger.Info("Start workflow watcher")
+		watcher.run(ctx)
+	}
+
+	var schedules []googleapi.ScheduleStatus
+	if err := run.Get(ctx, &schedules); err != nil {
+		logger.Fatal("Could not complete list notebook schedules workflow", "Error", err)
+	}
+	for _, s := range schedules {
+		logger.Info("Notebook schedule", "name", s.Name, "state", s.State, "cron", s.CronSchedule, "timeZone", s.TimeZone, "recentExecutions", len(s.RecentExecutions))
+	}
+	logger.Info("Schedule workflow completed successfully", "count", len(schedules))
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/toVersus/wbtemporal/pkg/executor/googleapi"
+	"github.com/toVersus/wbtemporal/pkg/logger"
+	"github.com/toVersus/wbtemporal/pkg/workflow"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+)
+
+var (
+	starterWorkbenchScheduleTriggerCmd = &cobra.Command{
+		Use:   "trigger",
+		Short: "Trigger Temporal workflow to execute notebook from the schedule immediately",
+		Run:   starterWorkbenchScheduleTrigger,
+	}
+)
+
+func starterWorkbenchScheduleTrigger(cmd *cobra.Command, args []string) {
+	logger := logger.NewDefaultLogger(logLevel)
+
+	if name == "" {
+		logger.Fatal("Name of the notebook schedule is required, use --name")
+	}
+
+	logger.Debug(fmt.Sprintf("Trying to connect to temporal frontend: %s", frontendAddr))
+	c, err := client.Dial(client.Options{
+		HostPort: fmt.Sprintf("dns:///%s", frontendAddr),
+		Logger:   logger,
+	})
+	if err != nil {
+		logger.Fatal("Failed to create Temporal client", "Error", err)
+	}
+	defer c.Close()
+	logger.Info(fmt.Sprintf("Successfully connected to temporal frontend: %s", frontendAddr))
+
+	logger.Info("Register signal handler to shutdown starter process gracefully")
+	ctx, shutdown := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer shutdown()
+
+	options := &googleapi.ScheduleOption{
+		Name:      name,
+		Location:  location,
+		ProjectId: projectID,
+	}
+	// ワークフロー ID が Workbench のインスタンスと衝突しないように、リソースの種類を含める
+	workflowID := fmt.Sprintf("%s-schedule-trigger", name)
+	logger.Info("Trigger workflow to trigger notebook schedule")
+	run, err := c.ExecuteWorkflow(ctx, client.StartWorkflowOptions{
+		ID:        workflowID,
+		TaskQueue: workflow.TriggerScheduleTaskQueue,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval: time.Minute,
+			MaximumAttempts: 3,
+		},
+	}, workflow.TriggerSchedule, options)
+	if err != nil {
+		logger.Fatal("Could not trigger trigger notebook schedule workflow", "Error", err)
+	}
+	if !wait {
+		logger.Info("Successfully triggered trigger notebook schedule workflow!")
+		return
+	}
+
+	if !silent {
+		// Poll and print workflow status using separate goroutine
+		watcher := &workflowWatcher{c: c, id: workflowID}
+		logger.Info("Start workflow watcher")
+		watcher.run(ctx)
+	}
+
+	var status googleapi.ScheduleStatus
+	if err := run.Get(ctx, &status); err != nil {
+		logger.Fatal("Could not complete trigger notebook schedule workflow", "Error", err)
+	}
+	// RecentExecutions は新しい順に並んでいるので、先頭が今回作成された実行
+	if len(status.RecentExecutions) > 0 {
+		e := status.RecentExecutions[0]
+		logger.Info("Schedule workflow completed successfully", "name", status.Name, "execution", e.Name, "state", e.State, "job", e.JobURI)
+	} else {
+		logger.Info("Schedule workflow completed successfully", "name", status.Name)
+	}
+	// Just to be sure, sleep 3 seconds before exiting
+	time.Sleep(3 * time.Second)
+}
@@ -76,7 +76,7 @@ func starterWorkbenchStart(cmd *cobra.Command, args []string) {
 	if err := run.Get(ctx, &status); err != nil {
 		logger.Fatal("Could not complete start workspace workflow", "Error", err)
 	}
-	logger.Info("Successfully complte start workspace workflow!")
+	logger.Info("Successfully complte start workspace workflow!", "name", status.Name, "url", status.URL, "status", status.Status)
 	// Just to be sure, sleep 3 seconds before exiting
 	time.Sleep(3 * time.Second)
 }
@@ -1,14 +1,19 @@
 package cmd
 
 import (
+	"context"
 	"log"
+	"sync"
 	"time"
 
 	prom "github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/cobra"
+	"github.com/toVersus/wbtemporal/pkg/workflow"
 	"github.com/uber-go/tally/v4"
 	"github.com/uber-go/tally/v4/prometheus"
+	"go.temporal.io/sdk/client"
 	sdktally "go.temporal.io/sdk/contrib/tally"
+	"go.temporal.io/sdk/worker"
 )
 
 var (
@@ -42,3 +47,30 @@ func newPrometheusScope(c prometheus.Configuration) tally.Scope {
 	log.Println("prometheus metrics scope created")
 	return scope
 }
+
+// runWorkers starts a worker polling the task queue of each registration, which serves the workflow and shared activities,
+// and blocks until all workers are stopped.
+func runWorkers(ctx context.Context, c client.Client, registrations []workflow.Registration, activities interface{}) {
+	workers := make([]worker.Worker, 0, len(registrations))
+	for _, r := range registrations {
+		w := worker.New(c, r.TaskQueue, worker.Options{
+			WorkerStopTimeout:         20 * time.Second,
+			BackgroundActivityContext: ctx,
+		})
+		w.RegisterWorkflow(r.Workflow)
+		w.RegisterActivity(activities)
+		workers = append(workers, w)
+	}
+
+	wg := sync.WaitGroup{}
+	wg.Add(len(workers))
+	for i, w := range workers {
+		go func(name string, w worker.Worker) {
+			if err := w.Run(worker.InterruptCh()); err != nil {
+				log.Fatalf("Failed to start %s worker: %s", name, err)
+			}
+			wg.Done()
+		}(registrations[i].Name, w)
+	}
+	wg.Wait()
+}
@@ -23,9 +23,23 @@ func NewJupyterHubExecutor(ctx context.Context, opts ExecutorOpts) (jupyterhubap
 			return nil, fmt.Errorf("jupyterhub api token is required")
 		}
 		return jupyterhubapi.NewExecutor(ctx, jupyterHubBaseURL, jupyterHubAPIToken)
+	} else if opts.Name == jupyterhubapi.ExecutorNameFakeClient {
+		fakeErrors, err := parseJupyterHubFakeErrors(opts.FakeErrors)
+		if err != nil {
+			return nil, err
+		}
+		fakeOpts := []jupyterhubapi.FakeClientOption{
+			jupyterhubapi.WithFakeSpawnDelay(opts.FakeSpawnDelay),
+			jupyterhubapi.WithFakeStopDelay(opts.FakeStopDelay),
+		}
+		if len(jupyterHubBaseURL) != 0 {
+			fakeOpts = append(fakeOpts, jupyterhubapi.WithFakeBaseURL(jupyterHubBaseURL))
+		}
+		f := jupyterhubapi.NewFakeClient(fakeOpts...)
+		for method, err := range fakeErrors {
+			f.InjectError(method, err, 0)
+		}
+		return f, nil
 	}
-	// } else if opts.Name == executor.ExecutorNameFakeClient {
-	// 	return fakeclient.NewFakeClientExecutor(), nil
-	// }
 	return nil, fmt.Errorf("executor %s not supported: %w", opts.Name, ErrNotFoundExecutor)
 }
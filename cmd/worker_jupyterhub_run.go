@@ -3,9 +3,6 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"log"
-	"sync"
-	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/toVersus/wbtemporal/pkg/activity"
@@ -14,7 +11,6 @@ import (
 	"github.com/uber-go/tally/v4/prometheus"
 	"go.temporal.io/sdk/client"
 	sdktally "go.temporal.io/sdk/contrib/tally"
-	"go.temporal.io/sdk/worker"
 )
 
 var (
@@ -30,7 +26,12 @@ func workerJupyterHubRun(cmd *cobra.Command, args []string) {
 	ctx := context.Background()
 	logger := logger.NewDefaultLogger(logLevel)
 
-	opts := ExecutorOpts{Name: executorName}
+	opts := ExecutorOpts{
+		Name:           executorName,
+		FakeSpawnDelay: fakeSpawnDelay,
+		FakeStopDelay:  fakeStopDelay,
+		FakeErrors:     fakeErrors,
+	}
 	logger.Info(fmt.Sprintf("executor option: %+v", opts))
 	executor, err := NewJupyterHubExecutor(ctx, opts)
 	if err != nil {
@@ -56,35 +57,6 @@ func workerJupyterHubRun(cmd *cobra.Command, args []string) {
 		Executor: executor,
 	}
 
-	createJupyterHubWorker := worker.New(c, workflow.CreateJupyterHubTaskQueue, worker.Options{
-		WorkerStopTimeout:         20 * time.Second,
-		BackgroundActivityContext: ctx,
-	})
-	createJupyterHubWorker.RegisterWorkflow(workflow.CreateUserServer)
-	createJupyterHubWorker.RegisterActivity(wa)
-
-	deleteJupyterHubWorker := worker.New(c, workflow.DeleteJupyterHubTaskQueue, worker.Options{
-		WorkerStopTimeout:         20 * time.Second,
-		BackgroundActivityContext: ctx,
-	})
-	deleteJupyterHubWorker.RegisterWorkflow(workflow.DeleteUserServer)
-	deleteJupyterHubWorker.RegisterActivity(wa)
-
-	wg := sync.WaitGroup{}
-	wg.Add(2)
-	go func() {
-		if err := createJupyterHubWorker.Run(worker.InterruptCh()); err != nil {
-			log.Fatalf("Failed to start create JupyterHub user server worker: %s", err)
-		}
-		wg.Done()
-	}()
-	go func() {
-		if err := deleteJupyterHubWorker.Run(worker.InterruptCh()); err != nil {
-			log.Fatalf("Failed to start delete JupyterHub user server worker: %s", err)
-		}
-		wg.Done()
-	}()
-
-	wg.Wait()
+	runWorkers(ctx, c, workflow.JupyterHubRegistrations, wa)
 	logger.Info("Successfully stop JupyterHub worker process!")
 }
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/spf13/cobra"
+	"github.com/toVersus/wbtemporal/pkg/executor/googleapi"
+)
+
+var (
+	workerRuntimeCmd = &cobra.Command{
+		Use: "runtime",
+	}
+)
+
+func NewRuntimeExecutor(ctx context.Context, opts ExecutorOpts) (googleapi.RuntimeExecutor, error) {
+	if opts.Name == googleapi.ExecutorNameGoogleAPI {
+		return googleapi.NewManagedNotebook(ctx)
+	} else if opts.Name == googleapi.ExecutorNameFakeClient {
+		fakeErrors, err := parseGoogleAPIFakeErrors(opts.FakeErrors, reflect.TypeOf((*googleapi.RuntimeExecutor)(nil)).Elem())
+		if err != nil {
+			return nil, err
+		}
+		f := googleapi.NewFakeClient(googleapi.WithFakeOperationDelay(opts.FakeOperationDelay))
+		for method, err := range fakeErrors {
+			f.InjectError(method, err, 0)
+		}
+		return f, nil
+	}
+	return nil, fmt.Errorf("executor %s not supported: %w", opts.Name, ErrNotFoundExecutor)
+}
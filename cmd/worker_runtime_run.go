@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/toVersus/wbtemporal/pkg/activity"
+	"github.com/toVersus/wbtemporal/pkg/logger"
+	"github.com/toVersus/wbtemporal/pkg/workflow"
+	"github.com/uber-go/tally/v4/prometheus"
+	"go.temporal.io/sdk/client"
+	sdktally "go.temporal.io/sdk/contrib/tally"
+)
+
+var (
+	workerRuntimeRunCmd = &cobra.Command{
+		Use:   "run",
+		Short: "Run Temporal worker to manage managed notebook runtimes",
+		Run:   workerRuntimeRun,
+	}
+)
+
+func workerRuntimeRun(cmd *cobra.Command, args []string) {
+	// Pass to shared google client used by activity worker
+	ctx := context.Background()
+	logger := logger.NewDefaultLogger(logLevel)
+
+	opts := ExecutorOpts{
+		Name:               executorName,
+		FakeOperationDelay: fakeOperationDelay,
+		FakeErrors:         fakeErrors,
+	}
+	logger.Info(fmt.Sprintf("executor option: %+v", opts))
+	executor, err := NewRuntimeExecutor(ctx, opts)
+	if err != nil {
+		logger.Fatal("Failed to select executor: %s", err)
+	}
+
+	logger.Debug(fmt.Sprintf("trying to connect to temporal frontend: %s", frontendAddr))
+	c, err := client.Dial(client.Options{
+		HostPort: fmt.Sprintf("dns:///%s", frontendAddr),
+		Logger:   logger,
+		MetricsHandler: sdktally.NewMetricsHandler(newPrometheusScope(prometheus.Configuration{
+			ListenAddress: "0.0.0.0:9090",
+			TimerType:     "histogram",
+		})),
+	})
+	if err != nil {
+		logger.Fatal("Failed to create Temporal client", "Error", err)
+	}
+	defer c.Close()
+	logger.Info(fmt.Sprintf("Successfully connected to temporal frontend: %s", frontendAddr))
+
+	ra := &activity.RuntimeActivity{
+		Executor: executor,
+	}
+
+	runWorkers(ctx, c, workflow.RuntimeRegistrations, ra)
+	logger.Info("Successfully stop worker process!")
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"reflect"
 
 	"github.com/spf13/cobra"
 	"github.com/toVersus/wbtemporal/pkg/executor/googleapi"
@@ -17,9 +18,18 @@ var (
 func NewGoogleAPIExecutor(ctx context.Context, opts ExecutorOpts) (googleapi.Executor, error) {
 	if opts.Name == googleapi.ExecutorNameGoogleAPI {
 		return googleapi.NewWorkbench(ctx)
+	} else if opts.Name == googleapi.ExecutorNameGoogleAPIV2 {
+		return googleapi.NewWorkbenchV2(ctx)
+	} else if opts.Name == googleapi.ExecutorNameFakeClient {
+		fakeErrors, err := parseGoogleAPIFakeErrors(opts.FakeErrors, reflect.TypeOf((*googleapi.Executor)(nil)).Elem())
+		if err != nil {
+			return nil, err
+		}
+		f := googleapi.NewFakeClient(googleapi.WithFakeOperationDelay(opts.FakeOperationDelay))
+		for method, err := range fakeErrors {
+			f.InjectError(method, err, 0)
+		}
+		return f, nil
 	}
-	// } else if opts.Name == executor.ExecutorNameFakeClient {
-	// 	return fakeclient.NewFakeClientExecutor(), nil
-	// }
 	return nil, fmt.Errorf("executor %s not supported: %w", opts.Name, ErrNotFoundExecutor)
 }
@@ -3,18 +3,15 @@ package cmd
 import (
 	"context"
 	"fmt"
-	"log"
-	"sync"
-	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/toVersus/wbtemporal/pkg/activity"
+	"github.com/toVersus/wbtemporal/pkg/executor/googleapi"
 	"github.com/toVersus/wbtemporal/pkg/logger"
 	"github.com/toVersus/wbtemporal/pkg/workflow"
 	"github.com/uber-go/tally/v4/prometheus"
 	"go.temporal.io/sdk/client"
 	sdktally "go.temporal.io/sdk/contrib/tally"
-	"go.temporal.io/sdk/worker"
 )
 
 var (
@@ -30,7 +27,11 @@ func workerWorkbenchRun(cmd *cobra.Command, args []string) {
 	ctx := context.Background()
 	logger := logger.NewDefaultLogger(logLevel)
 
-	opts := ExecutorOpts{Name: executorName}
+	opts := ExecutorOpts{
+		Name:               executorName,
+		FakeOperationDelay: fakeOperationDelay,
+		FakeErrors:         fakeErrors,
+	}
 	logger.Info(fmt.Sprintf("executor option: %+v", opts))
 	executor, err := NewGoogleAPIExecutor(ctx, opts)
 	if err != nil {
@@ -52,67 +53,25 @@ func workerWorkbenchRun(cmd *cobra.Command, args []string) {
 	defer c.Close()
 	logger.Info(fmt.Sprintf("Successfully connected to temporal frontend: %s", frontendAddr))
 
-	wa := &activity.WorkbenchActivity{
-		Executor: executor,
+	profile := &googleapi.SecurityProfile{
+		NoPublicIP:                  changedBool(cmd, "default-no-public-ip", defaultNoPublicIP),
+		ServiceAccount:              defaultServiceAccount,
+		Tags:                        defaultTags,
+		ShieldedSecureBoot:          changedBool(cmd, "default-shielded-secure-boot", defaultShieldedSecureBoot),
+		ShieldedVtpm:                changedBool(cmd, "default-shielded-vtpm", defaultShieldedVtpm),
+		ShieldedIntegrityMonitoring: changedBool(cmd, "default-shielded-integrity-monitoring", defaultShieldedIntegrityMonitoring),
+		DiskEncryption:              defaultDiskEncryption,
+		KmsKey:                      defaultKmsKey,
+	}
+	if err := profile.Validate(); err != nil {
+		logger.Fatal("Invalid default security profile", "Error", err)
 	}
 
-	cw := worker.New(c, workflow.CreateWorkbenchTaskQueue, worker.Options{
-		WorkerStopTimeout:         20 * time.Second,
-		BackgroundActivityContext: ctx,
-	})
-	cw.RegisterWorkflow(workflow.CreateWorkbench)
-	cw.RegisterActivity(wa)
-
-	dw := worker.New(c, workflow.DeleteWorkbenchTaskQueue, worker.Options{
-		WorkerStopTimeout:         20 * time.Second,
-		BackgroundActivityContext: ctx,
-	})
-	dw.RegisterWorkflow(workflow.DeleteWorkbench)
-	dw.RegisterActivity(wa)
-
-	tw := worker.New(c, workflow.StartWorkbenchTaskQueue, worker.Options{
-		WorkerStopTimeout:         20 * time.Second,
-		BackgroundActivityContext: ctx,
-	})
-	tw.RegisterWorkflow(workflow.StartWorkbench)
-	tw.RegisterActivity(wa)
-
-	sw := worker.New(c, workflow.StopWorkbenchTaskQueue, worker.Options{
-		WorkerStopTimeout:         20 * time.Second,
-		BackgroundActivityContext: ctx,
-	})
-	sw.RegisterWorkflow(workflow.StopWorkbench)
-	sw.RegisterActivity(wa)
-
-	wg := sync.WaitGroup{}
-	wg.Add(4)
-	go func() {
-		if err := cw.Run(worker.InterruptCh()); err != nil {
-			log.Fatalf("Failed to start create workspace worker: %s", err)
-		}
-		wg.Done()
-	}()
-	go func() {
-		if err := dw.Run(worker.InterruptCh()); err != nil {
-			log.Fatalf("Failed to start delete workspace worker: %s", err)
-		}
-		wg.Done()
-	}()
-
-	go func() {
-		if err := tw.Run(worker.InterruptCh()); err != nil {
-			log.Fatalf("Failed to start start workspace worker: %s", err)
-		}
-		wg.Done()
-	}()
-
-	go func() {
-		if err := sw.Run(worker.InterruptCh()); err != nil {
-			log.Fatalf("Failed to start stop workspace worker: %s", err)
-		}
-		wg.Done()
-	}()
+	wa := &activity.WorkbenchActivity{
+		Executor:        executor,
+		SecurityProfile: profile,
+	}
 
-	wg.Wait()
+	runWorkers(ctx, c, workflow.WorkbenchRegistrations, wa)
 	logger.Info("Successfully stop worker process!")
 }
@@ -2,6 +2,7 @@ package activity
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"go.temporal.io/sdk/temporal"
@@ -12,6 +13,12 @@ import (
 
 const (
 	ErrOperationFailed = "ErrorOperationFailed"
+	ErrUnauthorized    = "ErrorUnauthorized"
+	ErrForbidden       = "ErrorForbidden"
+	ErrConflict        = "ErrorConflict"
+	ErrClientError     = "ErrorClientError"
+	ErrRateLimited     = "ErrorRateLimited"
+	ErrServerError     = "ErrorServerError"
 )
 
 type JupyterHubActivity struct {
@@ -22,11 +29,15 @@ func (a *JupyterHubActivity) GetOrCreateUser(ctx context.Context, option *jupyte
 	user, err := a.Executor.GetUser(ctx, option)
 	if err == jupyterhubapi.ErrUserNotFound {
 		user, err = a.Executor.CreateUser(ctx, option)
+		// 他のワークフローが同時にユーザーを作成した場合は作成済みのユーザーを取得する
+		if errors.Is(err, jupyterhubapi.ErrConflict) {
+			user, err = a.Executor.GetUser(ctx, option)
+		}
 		if err != nil {
-			return nil, err
+			return nil, jupyterHubError(err)
 		}
 	} else if err != nil {
-		return nil, fmt.Errorf("failed to get user: %v", err)
+		return nil, jupyterHubError(err)
 	}
 	return user, nil
 }
@@ -36,15 +47,15 @@ func (a *JupyterHubActivity) ExistUserServer(ctx context.Context, option *jupyte
 	if err == jupyterhubapi.ErrServerNotFound {
 		return false, nil
 	} else if err != nil {
-		return false, err
+		return false, jupyterHubError(err)
 	}
-	return server.Status == jupyterhubapi.UserServerStatusReady, nil
+	return server.Status.IsRunning(), nil
 }
 
 func (a *JupyterHubActivity) CreateUserServer(ctx context.Context, option *jupyterhubapi.Option) error {
 	err := a.Executor.CreateUserServer(ctx, option)
 	if err != nil {
-		return err
+		return jupyterHubError(err)
 	}
 	return nil
 }
@@ -52,7 +63,7 @@ func (a *JupyterHubActivity) CreateUserServer(ctx context.Context, option *jupyt
 func (a *JupyterHubActivity) DeleteUserServer(ctx context.Context, option *jupyterhubapi.Option) error {
 	err := a.Executor.DeleteUserServer(ctx, option)
 	if err != nil {
-		return err
+		return jupyterHubError(err)
 	}
 	return nil
 }
@@ -60,7 +71,7 @@ func (a *JupyterHubActivity) DeleteUserServer(ctx context.Context, option *jupyt
 func (a *JupyterHubActivity) GetUserServer(ctx context.Context, option *jupyterhubapi.Option) (*jupyterhubapi.Status, error) {
 	server, err := a.Executor.GetUserServer(ctx, option)
 	if err != nil {
-		return nil, err
+		return nil, jupyterHubError(err)
 	}
 	return server, nil
 }
@@ -68,6 +79,10 @@ func (a *JupyterHubActivity) GetUserServer(ctx context.Context, option *jupyterh
 func (a *JupyterHubActivity) WaitUserServerReady(ctx context.Context, option *jupyterhubapi.Option) error {
 	ready, err := a.Executor.IsUserServerReady(ctx, option)
 	if err != nil {
+		var httpErr *jupyterhubapi.HTTPError
+		if errors.As(err, &httpErr) {
+			return jupyterHubError(err)
+		}
 		return temporal.NewNonRetryableApplicationError("non-retryable error found in waiting to become ready", ErrOperationFailed, err)
 	}
 	if !ready {
@@ -76,9 +91,29 @@ func (a *JupyterHubActivity) WaitUserServerReady(ctx context.Context, option *ju
 	return nil
 }
 
+// WaitUserServerSteady waits until the user server finishes spawning or stopping.
+// It returns nil status if the user server does not exist.
+func (a *JupyterHubActivity) WaitUserServerSteady(ctx context.Context, option *jupyterhubapi.Option) (*jupyterhubapi.Status, error) {
+	server, err := a.Executor.GetUserServer(ctx, option)
+	if err == jupyterhubapi.ErrServerNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, jupyterHubError(err)
+	}
+	// 起動中や停止中のサーバへのリクエストは拒否されるので、完了するまでリトライさせる
+	if server.Status.IsTransitional() {
+		return nil, fmt.Errorf("user server is %s", server.Status)
+	}
+	return server, nil
+}
+
 func (a *JupyterHubActivity) WaitUserServerDeleted(ctx context.Context, option *jupyterhubapi.Option) error {
 	ready, err := a.Executor.IsUserServerDeleted(ctx, option)
 	if err != nil {
+		var httpErr *jupyterhubapi.HTTPError
+		if errors.As(err, &httpErr) {
+			return jupyterHubError(err)
+		}
 		return temporal.NewNonRetryableApplicationError("non-retryable error found in waiting to be deleted", ErrOperationFailed, err)
 	}
 	if !ready {
@@ -86,3 +121,33 @@ func (a *JupyterHubActivity) WaitUserServerDeleted(ctx context.Context, option *
 	}
 	return nil
 }
+
+// jupyterHubError converts HTTP errors returned by jupyterhubapi executor into Temporal application errors.
+// 4xx errors except 429 never succeed however many times they are retried, so they are returned as non-retryable errors.
+// 429 and 5xx errors are returned as retryable errors with the duration requested by Retry-After header as the details.
+func jupyterHubError(err error) error {
+	var httpErr *jupyterhubapi.HTTPError
+	if !errors.As(err, &httpErr) {
+		return err
+	}
+
+	if httpErr.Temporary() {
+		errType := ErrServerError
+		if errors.Is(err, jupyterhubapi.ErrRateLimited) {
+			errType = ErrRateLimited
+		}
+		// アクティビティ内で Retry-After の間待つとタイムアウトまでの時間を消費してしまうので、待機はリトライ戦略に任せる
+		return temporal.NewApplicationErrorWithCause(httpErr.Error(), errType, err, httpErr.RetryAfter)
+	}
+
+	errType := ErrClientError
+	switch {
+	case errors.Is(err, jupyterhubapi.ErrUnauthorized):
+		errType = ErrUnauthorized
+	case errors.Is(err, jupyterhubapi.ErrForbidden):
+		errType = ErrForbidden
+	case errors.Is(err, jupyterhubapi.ErrConflict):
+		errType = ErrConflict
+	}
+	return temporal.NewNonRetryableApplicationError(httpErr.Error(), errType, err)
+}
@@ -0,0 +1,126 @@
+package activity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/toVersus/wbtemporal/pkg/executor/googleapi"
+	"go.temporal.io/sdk/temporal"
+)
+
+// RuntimeActivity is the activities of Google-managed notebook runtimes.
+// The methods have the "Runtime" suffix so that they can be registered with WorkbenchActivity in the same worker.
+type RuntimeActivity struct {
+	Executor googleapi.RuntimeExecutor
+}
+
+func (a *RuntimeActivity) ExistRuntime(ctx context.Context, option *googleapi.RuntimeOption) (bool, error) {
+	_, err := a.Executor.DescribeRuntime(ctx, option)
+	if err != nil {
+		if errors.Is(err, googleapi.ErrNotFound) {
+			return false, nil
+		}
+		return false, googleAPIError(err, "runtime")
+	}
+	return true, nil
+}
+
+func (a *RuntimeActivity) DescribeRuntime(ctx context.Context, option *googleapi.RuntimeOption) (*googleapi.RuntimeStatus, error) {
+	result, err := a.Executor.DescribeRuntime(ctx, option)
+	if err != nil {
+		return nil, googleAPIError(err, "runtime")
+	}
+	return result, nil
+}
+
+// GetRuntimeSteadyState waits for the runtime to be either ACTIVE or STOPPED, e.g. while the runtime is provisioning or stopping.
+func (a *RuntimeActivity) GetRuntimeSteadyState(ctx context.Context, option *googleapi.RuntimeOption) (*googleapi.RuntimeStatus, error) {
+	result, err := a.Executor.DescribeRuntime(ctx, option)
+	if err != nil {
+		return nil, googleAPIError(err, "runtime")
+	}
+	switch {
+	case result.Status.IsRunning(), result.Status.IsStopped():
+		return result, nil
+	case result.Status.IsTransitional():
+		return nil, fmt.Errorf("runtime is in transition: %s", result.Status)
+	default:
+		return nil, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("runtime cannot be made running in %s state", result.Status), ErrUnexpectedState, nil)
+	}
+}
+
+func (a *RuntimeActivity) GetRuntimeURL(ctx context.Context, option *googleapi.RuntimeOption) (*googleapi.RuntimeStatus, error) {
+	result, err := a.Executor.DescribeRuntime(ctx, option)
+	if err != nil {
+		return nil, googleAPIError(err, "runtime")
+	}
+	if result.Status.IsTerminal() {
+		return nil, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("runtime never becomes active in %s state", result.Status), ErrUnexpectedState, nil)
+	}
+	// Operation が完了しても JupyterLab のプロキシが準備できるまでは URL が返されないので待つ
+	if !result.Status.IsRunning() || len(result.URL) == 0 {
+		return nil, fmt.Errorf("runtime is not active yet: %s", result.Status)
+	}
+	return result, nil
+}
+
+func (a *RuntimeActivity) CreateRuntime(ctx context.Context, option *googleapi.RuntimeOption) (string, error) {
+	if err := option.Validate(); err != nil {
+		return "", temporal.NewNonRetryableApplicationError("invalid option found in request to create runtime", ErrInvalidArgument, err)
+	}
+	opName, err := a.Executor.CreateRuntime(ctx, option)
+	if err != nil {
+		return "", googleAPIError(err, "runtime")
+	}
+	return opName, nil
+}
+
+func (a *RuntimeActivity) DeleteRuntime(ctx context.Context, option *googleapi.RuntimeOption) (string, error) {
+	opName, err := a.Executor.DeleteRuntime(ctx, option)
+	if err != nil {
+		return "", googleAPIError(err, "runtime")
+	}
+	return opName, nil
+}
+
+func (a *RuntimeActivity) StartRuntime(ctx context.Context, option *googleapi.RuntimeOption) (string, error) {
+	opName, err := a.Executor.StartRuntime(ctx, option)
+	if err != nil {
+		return "", googleAPIError(err, "runtime")
+	}
+	return opName, nil
+}
+
+func (a *RuntimeActivity) StopRuntime(ctx context.Context, option *googleapi.RuntimeOption) (string, error) {
+	opName, err := a.Executor.StopRuntime(ctx, option)
+	if err != nil {
+		return "", googleAPIError(err, "runtime")
+	}
+	return opName, nil
+}
+
+// SwitchRuntime changes the machine type and accelerator of the stopped runtime.
+func (a *RuntimeActivity) SwitchRuntime(ctx context.Context, option *googleapi.RuntimeOption) (string, error) {
+	if err := option.ValidateSwitch(); err != nil {
+		return "", temporal.NewNonRetryableApplicationError("invalid option found in request to switch runtime", ErrInvalidArgument, err)
+	}
+	opName, err := a.Executor.SwitchRuntime(ctx, option)
+	if err != nil {
+		return "", googleAPIError(err, "runtime")
+	}
+	return opName, nil
+}
+
+func (a *RuntimeActivity) RuntimeOperationCompleted(ctx context.Context, opName string) error {
+	done, err := a.Executor.HasOperationDone(ctx, opName)
+	if err != nil {
+		return temporal.NewNonRetryableApplicationError("non-retryable error found in watch operation", ErrLongRunningOperationFailed, err)
+	}
+	if !done {
+		return fmt.Errorf("operation is not done yet")
+	}
+	return nil
+}
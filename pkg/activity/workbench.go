@@ -2,6 +2,7 @@ package activity
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -11,41 +12,92 @@ import (
 
 const (
 	ErrLongRunningOperationFailed = "ErrorLongRunningOperationFailed"
+	ErrNotFound                   = "ErrorNotFound"
+	ErrPermissionDenied           = "ErrorPermissionDenied"
+	ErrInvalidArgument            = "ErrorInvalidArgument"
+	ErrResourceExhausted          = "ErrorResourceExhausted"
+	ErrFailedPrecondition         = "ErrorFailedPrecondition"
+	ErrBootstrapFailed            = "ErrorBootstrapFailed"
+	ErrUnexpectedState            = "ErrorUnexpectedState"
+	ErrUnimplemented              = "ErrorUnimplemented"
 )
 
 type WorkbenchActivity struct {
 	Executor googleapi.Executor
+	// SecurityProfile is applied to instances created without security settings, nil means no defaults
+	SecurityProfile *googleapi.SecurityProfile
 }
 
 func (a *WorkbenchActivity) Exist(ctx context.Context, option *googleapi.Option) (bool, error) {
 	_, err := a.Executor.DescribeNotebookInstance(ctx, option)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, googleapi.ErrNotFound) {
 			return false, nil
 		}
-		return false, err
+		return false, googleAPIError(err, "workbench instance")
 	}
 	return true, nil
 }
 
+func (a *WorkbenchActivity) Describe(ctx context.Context, option *googleapi.Option) (*googleapi.Status, error) {
+	result, err := a.Executor.DescribeNotebookInstance(ctx, option)
+	if err != nil {
+		return nil, googleAPIError(err, "workbench instance")
+	}
+	return result, nil
+}
+
+// GetSteadyState waits for the instance to be either ACTIVE or STOPPED, e.g. while the instance is stopping or being upgraded.
+// Instances in the other steady states, e.g. DELETED or SUSPENDED, cannot be made running, so non-retryable error is returned for them.
+func (a *WorkbenchActivity) GetSteadyState(ctx context.Context, option *googleapi.Option) (*googleapi.Status, error) {
+	result, err := a.Executor.DescribeNotebookInstance(ctx, option)
+	if err != nil {
+		return nil, googleAPIError(err, "workbench instance")
+	}
+	switch {
+	case result.Status.IsRunning(), result.Status.IsStopped():
+		return result, nil
+	case result.Status.IsTransitional():
+		return nil, fmt.Errorf("workbench instance is in transition: %s", result.Status)
+	default:
+		return nil, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("workbench instance cannot be made running in %s state", result.Status), ErrUnexpectedState, nil)
+	}
+}
+
 func (a *WorkbenchActivity) GetWorkspaceURL(ctx context.Context, option *googleapi.Option) (*googleapi.Status, error) {
 	result, err := a.Executor.DescribeNotebookInstance(ctx, option)
 	if err != nil {
-		return nil, err
+		return nil, googleAPIError(err, "workbench instance")
+	}
+	if result.Status.IsTerminal() {
+		return nil, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("workbench instance never becomes active in %s state", result.Status), ErrUnexpectedState, nil)
 	}
 	// Vertex AI Workbench Instance を作成する Operation はあくまで Workbench Instance を作成するまでしか待たないので、
-	// Workbench Instance が Active になるまで待つために、接続先の URL が取得できるまで待つ
-	if len(result.URL) == 0 {
-		return nil, fmt.Errorf("workbench instance is not active yet")
+	// Workbench Instance が Active になり、接続先の URL が取得できるまで待つ
+	if !result.Status.IsRunning() || len(result.URL) == 0 {
+		return nil, fmt.Errorf("workbench instance is not active yet: %s", result.Status)
 	}
 
 	return result, nil
 }
 
 func (a *WorkbenchActivity) Create(ctx context.Context, option *googleapi.Option) (string, error) {
+	option.ApplySecurityProfile(a.SecurityProfile)
+	if err := option.Validate(); err != nil {
+		return "", temporal.NewNonRetryableApplicationError("invalid option found in request to create workbench instance", ErrInvalidArgument, err)
+	}
+	// 環境が未解決のまま作成すると既定のイメージが使われてしまうので拒否する
+	if option.Environment != "" {
+		return "", temporal.NewNonRetryableApplicationError("environment must be resolved into image before creating workbench instance", ErrInvalidArgument, nil)
+	}
+	if err := a.checkAcceleratorAvailability(ctx, option); err != nil {
+		return "", err
+	}
 	opName, err := a.Executor.CreateNotebookInstance(ctx, option)
 	if err != nil {
-		return "", err
+		return "", googleAPIError(err, "workbench instance")
 	}
 	return opName, nil
 }
@@ -53,7 +105,7 @@ func (a *WorkbenchActivity) Create(ctx context.Context, option *googleapi.Option
 func (a *WorkbenchActivity) Delete(ctx context.Context, option *googleapi.Option) (string, error) {
 	opName, err := a.Executor.DeleteNotebookInstance(ctx, option)
 	if err != nil {
-		return "", err
+		return "", googleAPIError(err, "workbench instance")
 	}
 	return opName, nil
 }
@@ -61,7 +113,7 @@ func (a *WorkbenchActivity) Delete(ctx context.Context, option *googleapi.Option
 func (a *WorkbenchActivity) Start(ctx context.Context, option *googleapi.Option) (string, error) {
 	opName, err := a.Executor.StartNotebookInstance(ctx, option)
 	if err != nil {
-		return "", err
+		return "", googleAPIError(err, "workbench instance")
 	}
 	return opName, nil
 }
@@ -69,11 +121,280 @@ func (a *WorkbenchActivity) Start(ctx context.Context, option *googleapi.Option)
 func (a *WorkbenchActivity) Stop(ctx context.Context, option *googleapi.Option) (string, error) {
 	opName, err := a.Executor.StopNotebookInstance(ctx, option)
 	if err != nil {
-		return "", err
+		return "", googleAPIError(err, "workbench instance")
 	}
 	return opName, nil
 }
 
+func (a *WorkbenchActivity) SetLabels(ctx context.Context, option *googleapi.Option) (string, error) {
+	if err := option.ValidateLabels(); err != nil {
+		return "", temporal.NewNonRetryableApplicationError("invalid labels found in request to workbench instance", ErrInvalidArgument, err)
+	}
+	opName, err := a.Executor.SetNotebookInstanceLabels(ctx, option)
+	if err != nil {
+		return "", googleAPIError(err, "workbench instance")
+	}
+	return opName, nil
+}
+
+// SetMachineType changes the machine type of the stopped instance to option.MachineType.
+func (a *WorkbenchActivity) SetMachineType(ctx context.Context, option *googleapi.Option) (string, error) {
+	if option.MachineType == "" {
+		return "", temporal.NewNonRetryableApplicationError("machine type is required in request to resize workbench instance", ErrInvalidArgument, nil)
+	}
+	opName, err := a.Executor.SetNotebookInstanceMachineType(ctx, option)
+	if err != nil {
+		return "", googleAPIError(err, "workbench instance")
+	}
+	return opName, nil
+}
+
+// CheckAccelerator checks that the accelerator can be attached to the instance with option.MachineType and is offered in the zone,
+// so that the instance is not stopped for the change that never succeeds.
+func (a *WorkbenchActivity) CheckAccelerator(ctx context.Context, option *googleapi.Option) error {
+	if err := option.ValidateAccelerator(); err != nil {
+		return temporal.NewNonRetryableApplicationError("invalid accelerator found in request to workbench instance", ErrInvalidArgument, err)
+	}
+	return a.checkAcceleratorAvailability(ctx, option)
+}
+
+func (a *WorkbenchActivity) SetAccelerator(ctx context.Context, option *googleapi.Option) (string, error) {
+	if err := option.ValidateAccelerator(); err != nil {
+		return "", temporal.NewNonRetryableApplicationError("invalid accelerator found in request to workbench instance", ErrInvalidArgument, err)
+	}
+	opName, err := a.Executor.SetNotebookInstanceAccelerator(ctx, option)
+	if err != nil {
+		return "", googleAPIError(err, "workbench instance")
+	}
+	return opName, nil
+}
+
+func (a *WorkbenchActivity) IsUpgradeable(ctx context.Context, option *googleapi.Option) (*googleapi.Upgradeability, error) {
+	result, err := a.Executor.IsNotebookInstanceUpgradeable(ctx, option)
+	if err != nil {
+		return nil, googleAPIError(err, "workbench instance")
+	}
+	return result, nil
+}
+
+func (a *WorkbenchActivity) Upgrade(ctx context.Context, option *googleapi.Option) (string, error) {
+	opName, err := a.Executor.UpgradeNotebookInstance(ctx, option)
+	if err != nil {
+		return "", googleAPIError(err, "workbench instance")
+	}
+	return opName, nil
+}
+
+// Rollback restores the instance from option.Snapshot, which must be one of the snapshots in the upgrade history.
+func (a *WorkbenchActivity) Rollback(ctx context.Context, option *googleapi.Option) (string, error) {
+	if option.Snapshot == "" {
+		return "", temporal.NewNonRetryableApplicationError("snapshot is required in request to roll back workbench instance", ErrInvalidArgument, nil)
+	}
+	opName, err := a.Executor.RollbackNotebookInstance(ctx, option)
+	if err != nil {
+		return "", googleAPIError(err, "workbench instance")
+	}
+	return opName, nil
+}
+
+func (a *WorkbenchActivity) Reset(ctx context.Context, option *googleapi.Option) (string, error) {
+	opName, err := a.Executor.ResetNotebookInstance(ctx, option)
+	if err != nil {
+		return "", googleAPIError(err, "workbench instance")
+	}
+	return opName, nil
+}
+
+func (a *WorkbenchActivity) Diagnose(ctx context.Context, option *googleapi.Option) (string, error) {
+	if option.Diagnostic == nil {
+		return "", temporal.NewNonRetryableApplicationError("diagnostic configuration is required in request to diagnose workbench instance", ErrInvalidArgument, nil)
+	}
+	if err := option.Diagnostic.Validate(); err != nil {
+		return "", temporal.NewNonRetryableApplicationError("invalid diagnostic configuration found in request to workbench instance", ErrInvalidArgument, err)
+	}
+	opName, err := a.Executor.DiagnoseNotebookInstance(ctx, option)
+	if err != nil {
+		return "", googleAPIError(err, "workbench instance")
+	}
+	return opName, nil
+}
+
+func (a *WorkbenchActivity) ExistExecution(ctx context.Context, option *googleapi.ExecutionOption) (bool, error) {
+	_, err := a.Executor.DescribeExecution(ctx, option)
+	if err != nil {
+		if errors.Is(err, googleapi.ErrNotFound) {
+			return false, nil
+		}
+		return false, googleAPIError(err, "execution")
+	}
+	return true, nil
+}
+
+func (a *WorkbenchActivity) CreateExecution(ctx context.Context, option *googleapi.ExecutionOption) (string, error) {
+	if err := option.Validate(); err != nil {
+		return "", temporal.NewNonRetryableApplicationError("invalid option found in request to execute notebook", ErrInvalidArgument, err)
+	}
+	opName, err := a.Executor.CreateExecution(ctx, option)
+	if err != nil {
+		return "", googleAPIError(err, "execution")
+	}
+	return opName, nil
+}
+
+// ExecutionCompleted waits for the notebook execution to be in terminal state, like OperationCompleted does for operations.
+// The failed execution is returned without error, so that the caller can report the state and the job.
+func (a *WorkbenchActivity) ExecutionCompleted(ctx context.Context, option *googleapi.ExecutionOption) (*googleapi.ExecutionStatus, error) {
+	result, err := a.Executor.DescribeExecution(ctx, option)
+	if err != nil {
+		return nil, googleAPIError(err, "execution")
+	}
+	if !result.State.IsTerminal() {
+		return nil, fmt.Errorf("notebook execution is not completed yet: %s", result.State)
+	}
+	return result, nil
+}
+
+func (a *WorkbenchActivity) ExistSchedule(ctx context.Context, option *googleapi.ScheduleOption) (bool, error) {
+	_, err := a.Executor.DescribeSchedule(ctx, option)
+	if err != nil {
+		if errors.Is(err, googleapi.ErrNotFound) {
+			return false, nil
+		}
+		return false, googleAPIError(err, "schedule")
+	}
+	return true, nil
+}
+
+func (a *WorkbenchActivity) CreateSchedule(ctx context.Context, option *googleapi.ScheduleOption) (string, error) {
+	if err := option.Validate(); err != nil {
+		return "", temporal.NewNonRetryableApplicationError("invalid option found in request to create notebook schedule", ErrInvalidArgument, err)
+	}
+	opName, err := a.Executor.CreateSchedule(ctx, option)
+	if err != nil {
+		return "", googleAPIError(err, "schedule")
+	}
+	return opName, nil
+}
+
+func (a *WorkbenchActivity) DescribeSchedule(ctx context.Context, option *googleapi.ScheduleOption) (*googleapi.ScheduleStatus, error) {
+	result, err := a.Executor.DescribeSchedule(ctx, option)
+	if err != nil {
+		return nil, googleAPIError(err, "schedule")
+	}
+	return result, nil
+}
+
+func (a *WorkbenchActivity) ListSchedules(ctx context.Context, option *googleapi.ScheduleOption) ([]googleapi.ScheduleStatus, error) {
+	result, err := a.Executor.ListSchedules(ctx, option)
+	if err != nil {
+		return nil, googleAPIError(err, "schedule")
+	}
+	return result, nil
+}
+
+func (a *WorkbenchActivity) DeleteSchedule(ctx context.Context, option *googleapi.ScheduleOption) (string, error) {
+	opName, err := a.Executor.DeleteSchedule(ctx, option)
+	if err != nil {
+		return "", googleAPIError(err, "schedule")
+	}
+	return opName, nil
+}
+
+func (a *WorkbenchActivity) TriggerSchedule(ctx context.Context, option *googleapi.ScheduleOption) (string, error) {
+	opName, err := a.Executor.TriggerSchedule(ctx, option)
+	if err != nil {
+		return "", googleAPIError(err, "schedule")
+	}
+	return opName, nil
+}
+
+// ResolveEnvironment returns the option to create Workbench instance from the image of the environment in the zone of the instance.
+func (a *WorkbenchActivity) ResolveEnvironment(ctx context.Context, option *googleapi.Option) (*googleapi.Option, error) {
+	env, err := a.Executor.DescribeEnvironment(ctx, &googleapi.EnvironmentOption{
+		Name:      option.Environment,
+		Zone:      option.Zone,
+		ProjectId: option.ProjectId,
+	})
+	if err != nil {
+		if errors.Is(err, googleapi.ErrNotFound) {
+			return nil, temporal.NewNonRetryableApplicationError(
+				fmt.Sprintf("environment %q is not found in the catalog of %s", option.Environment, option.Zone), ErrInvalidArgument, err)
+		}
+		return nil, googleAPIError(err, "environment")
+	}
+	return option.WithEnvironment(env), nil
+}
+
+func (a *WorkbenchActivity) ExistEnvironment(ctx context.Context, option *googleapi.EnvironmentOption) (bool, error) {
+	_, err := a.Executor.DescribeEnvironment(ctx, option)
+	if err != nil {
+		if errors.Is(err, googleapi.ErrNotFound) {
+			return false, nil
+		}
+		return false, googleAPIError(err, "environment")
+	}
+	return true, nil
+}
+
+func (a *WorkbenchActivity) CreateEnvironment(ctx context.Context, option *googleapi.EnvironmentOption) (string, error) {
+	if err := option.Validate(); err != nil {
+		return "", temporal.NewNonRetryableApplicationError("invalid option found in request to create notebook environment", ErrInvalidArgument, err)
+	}
+	opName, err := a.Executor.CreateEnvironment(ctx, option)
+	if err != nil {
+		return "", googleAPIError(err, "environment")
+	}
+	return opName, nil
+}
+
+func (a *WorkbenchActivity) DescribeEnvironment(ctx context.Context, option *googleapi.EnvironmentOption) (*googleapi.EnvironmentStatus, error) {
+	result, err := a.Executor.DescribeEnvironment(ctx, option)
+	if err != nil {
+		return nil, googleAPIError(err, "environment")
+	}
+	return result, nil
+}
+
+func (a *WorkbenchActivity) ListEnvironments(ctx context.Context, option *googleapi.EnvironmentOption) ([]googleapi.EnvironmentStatus, error) {
+	result, err := a.Executor.ListEnvironments(ctx, option)
+	if err != nil {
+		return nil, googleAPIError(err, "environment")
+	}
+	return result, nil
+}
+
+func (a *WorkbenchActivity) DeleteEnvironment(ctx context.Context, option *googleapi.EnvironmentOption) (string, error) {
+	opName, err := a.Executor.DeleteEnvironment(ctx, option)
+	if err != nil {
+		return "", googleAPIError(err, "environment")
+	}
+	return opName, nil
+}
+
+func (a *WorkbenchActivity) SetIdleTimeout(ctx context.Context, option *googleapi.Option) error {
+	if err := option.ValidateIdleTimeout(); err != nil {
+		return temporal.NewNonRetryableApplicationError("invalid idle timeout found in request to workbench instance", ErrInvalidArgument, err)
+	}
+	if err := a.Executor.SetNotebookInstanceIdleTimeout(ctx, option); err != nil {
+		return googleAPIError(err, "workbench instance")
+	}
+	return nil
+}
+
+// IdleTimeoutApplied waits for the idle shutdown metadata to be reflected in the instance, like OperationCompleted does for operations.
+func (a *WorkbenchActivity) IdleTimeoutApplied(ctx context.Context, option *googleapi.Option) (*googleapi.Status, error) {
+	result, err := a.Executor.DescribeNotebookInstance(ctx, option)
+	if err != nil {
+		return nil, googleAPIError(err, "workbench instance")
+	}
+	for k, v := range option.IdleTimeoutMetadata() {
+		if result.Metadata[k] != v {
+			return nil, fmt.Errorf("idle timeout is not applied yet")
+		}
+	}
+	return result, nil
+}
+
 func (a *WorkbenchActivity) OperationCompleted(ctx context.Context, opName string) error {
 	done, err := a.Executor.HasOperationDone(ctx, opName)
 	if err != nil {
@@ -84,3 +405,77 @@ func (a *WorkbenchActivity) OperationCompleted(ctx context.Context, opName strin
 	}
 	return nil
 }
+
+// BootstrapCompleted checks the bootstrap status reported by the script running on the instance through guest attributes.
+func (a *WorkbenchActivity) BootstrapCompleted(ctx context.Context, option *googleapi.Option) error {
+	attrs, err := a.Executor.GetGuestAttributes(ctx, option, googleapi.GuestAttributeNamespace)
+	if err != nil {
+		// スクリプトが開始されるまではゲスト属性が存在しないので、見つからない場合もリトライする
+		if errors.Is(err, googleapi.ErrNotFound) {
+			return fmt.Errorf("bootstrap is not started yet")
+		}
+		return googleAPIError(err, "workbench instance")
+	}
+	switch attrs[googleapi.BootstrapStatusKey] {
+	case googleapi.BootstrapStatusDone:
+		return nil
+	case googleapi.BootstrapStatusFailed:
+		return temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("bootstrap of workbench instance failed: %s", attrs[googleapi.BootstrapMessageKey]), ErrBootstrapFailed, nil)
+	default:
+		return fmt.Errorf("bootstrap is not completed yet")
+	}
+}
+
+// checkAcceleratorAvailability checks that the accelerator can be attached in the zone before creating instance,
+// otherwise the operation fails after the instance creation has started.
+func (a *WorkbenchActivity) checkAcceleratorAvailability(ctx context.Context, option *googleapi.Option) error {
+	if !googleapi.IsZonalAccelerator(option.AcceleratorType) {
+		return nil
+	}
+	types, err := a.Executor.ListAcceleratorTypes(ctx, option)
+	if err != nil {
+		return googleAPIError(err, "accelerator type")
+	}
+	for _, t := range types {
+		if !strings.EqualFold(t.Name, option.AcceleratorType) {
+			continue
+		}
+		if t.MaxCount > 0 && option.AcceleratorCount > t.MaxCount {
+			return temporal.NewNonRetryableApplicationError(
+				fmt.Sprintf("up to %d %s accelerators can be attached in zone %s, but %d requested", t.MaxCount, t.Name, option.Zone, option.AcceleratorCount),
+				ErrInvalidArgument, nil)
+		}
+		return nil
+	}
+	return temporal.NewNonRetryableApplicationError(
+		fmt.Sprintf("accelerator %s is not available in zone %s", option.AcceleratorType, option.Zone), ErrInvalidArgument, nil)
+}
+
+// googleAPIError converts typed errors returned by googleapi executor into Temporal application errors.
+// resource is the kind of the resource being operated, e.g. "workbench instance" or "schedule", and is used in the error messages.
+// Errors caused by the request itself, e.g. wrong project ID or missing IAM role, never succeed however many times they are retried,
+// so they are returned as non-retryable errors to fail the workflow immediately.
+func googleAPIError(err error, resource string) error {
+	switch {
+	case errors.Is(err, googleapi.ErrNotFound):
+		return temporal.NewNonRetryableApplicationError(fmt.Sprintf("%s not found", resource), ErrNotFound, err)
+	case errors.Is(err, googleapi.ErrPermissionDenied):
+		return temporal.NewNonRetryableApplicationError(fmt.Sprintf("permission denied to operate %s", resource), ErrPermissionDenied, err)
+	case errors.Is(err, googleapi.ErrInvalidArgument):
+		return temporal.NewNonRetryableApplicationError(fmt.Sprintf("invalid argument found in request to %s", resource), ErrInvalidArgument, err)
+	case errors.Is(err, googleapi.ErrResourceExhausted):
+		// クォータやリソースの枯渇は時間をおけば解消する可能性があるのでリトライする
+		return temporal.NewApplicationErrorWithCause(fmt.Sprintf("resource exhausted to operate %s", resource), ErrResourceExhausted, err)
+	case errors.Is(err, googleapi.ErrFailedPrecondition):
+		// 別の操作の完了待ちなどリソースの状態が変われば成功する可能性があるのでリトライする
+		return temporal.NewApplicationErrorWithCause(fmt.Sprintf("%s is not ready for the operation", resource), ErrFailedPrecondition, err)
+	case errors.Is(err, googleapi.ErrInvalidOption):
+		// executor 側で検出した不正なオプションはリトライしても成功しない
+		return temporal.NewNonRetryableApplicationError(fmt.Sprintf("invalid option found in request to %s", resource), ErrInvalidArgument, err)
+	case errors.Is(err, googleapi.ErrUnimplemented):
+		return temporal.NewNonRetryableApplicationError("operation not supported by the executor", ErrUnimplemented, err)
+	default:
+		return err
+	}
+}
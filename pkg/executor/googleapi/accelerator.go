@@ -0,0 +1,119 @@
+package googleapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/notebooks/apiv1/notebookspb"
+)
+
+// acceleratorRule is the machine series and the number of accelerators that the accelerator can be attached with.
+// https://cloud.google.com/compute/docs/gpus
+type acceleratorRule struct {
+	series string
+	counts []int64
+}
+
+var acceleratorRules = map[notebookspb.Instance_AcceleratorType]acceleratorRule{
+	notebookspb.Instance_NVIDIA_TESLA_K80:      {series: "n1", counts: []int64{1, 2, 4, 8}},
+	notebookspb.Instance_NVIDIA_TESLA_P100:     {series: "n1", counts: []int64{1, 2, 4}},
+	notebookspb.Instance_NVIDIA_TESLA_P100_VWS: {series: "n1", counts: []int64{1, 2, 4}},
+	notebookspb.Instance_NVIDIA_TESLA_V100:     {series: "n1", counts: []int64{1, 2, 4, 8}},
+	notebookspb.Instance_NVIDIA_TESLA_P4:       {series: "n1", counts: []int64{1, 2, 4}},
+	notebookspb.Instance_NVIDIA_TESLA_P4_VWS:   {series: "n1", counts: []int64{1, 2, 4}},
+	notebookspb.Instance_NVIDIA_TESLA_T4:       {series: "n1", counts: []int64{1, 2, 4}},
+	notebookspb.Instance_NVIDIA_TESLA_T4_VWS:   {series: "n1", counts: []int64{1, 2, 4}},
+	// A100 GPU は A2 マシンタイプにあらかじめアタッチされており、数はマシンタイプによって決まる
+	notebookspb.Instance_NVIDIA_TESLA_A100: {series: "a2"},
+	notebookspb.Instance_TPU_V2:            {series: "n1", counts: []int64{8}},
+	notebookspb.Instance_TPU_V3:            {series: "n1", counts: []int64{8}},
+}
+
+// checkAccelerator validates the combination of machine type, accelerator type and count without calling any API.
+func checkAccelerator(machineType, acceleratorType string, count int64, installGpuDriver bool) error {
+	series, _, _ := strings.Cut(machineType, "-")
+
+	if acceleratorType == "" {
+		if count != 0 {
+			return fmt.Errorf("accelerator count requires accelerator type")
+		}
+		if installGpuDriver {
+			return fmt.Errorf("GPU driver can be installed only with GPU accelerator")
+		}
+		if series == "a2" {
+			return fmt.Errorf("machine type %s requires %s accelerator", machineType, notebookspb.Instance_NVIDIA_TESLA_A100)
+		}
+		return nil
+	}
+
+	t, err := parseAcceleratorType(acceleratorType)
+	if err != nil {
+		return err
+	}
+	if count <= 0 {
+		return fmt.Errorf("accelerator count must be positive: %d", count)
+	}
+	if installGpuDriver && !isGPU(t) {
+		return fmt.Errorf("GPU driver can be installed only with GPU accelerator, not %s", t)
+	}
+
+	rule := acceleratorRules[t]
+	if series != rule.series {
+		return fmt.Errorf("accelerator %s cannot be attached to machine type %s, use %s machine series", t, machineType, rule.series)
+	}
+	if len(rule.counts) == 0 {
+		// a2-highgpu-4g のようにマシンタイプの末尾が GPU の数を表す
+		gpus, err := strconv.ParseInt(strings.TrimSuffix(machineType[strings.LastIndex(machineType, "-")+1:], "g"), 10, 64)
+		if err != nil {
+			return fmt.Errorf("unknown machine type %s for accelerator %s", machineType, t)
+		}
+		if count != gpus {
+			return fmt.Errorf("machine type %s requires %d %s accelerators, but %d requested", machineType, gpus, t, count)
+		}
+		return nil
+	}
+	for _, c := range rule.counts {
+		if count == c {
+			return nil
+		}
+	}
+	return fmt.Errorf("accelerator count %d is not supported for %s, use one of %v", count, t, rule.counts)
+}
+
+func parseAcceleratorType(s string) (notebookspb.Instance_AcceleratorType, error) {
+	v, ok := notebookspb.Instance_AcceleratorType_value[strings.ToUpper(s)]
+	if !ok || v == int32(notebookspb.Instance_ACCELERATOR_TYPE_UNSPECIFIED) {
+		return notebookspb.Instance_ACCELERATOR_TYPE_UNSPECIFIED, fmt.Errorf("unknown accelerator type %q", s)
+	}
+	return notebookspb.Instance_AcceleratorType(v), nil
+}
+
+func isGPU(t notebookspb.Instance_AcceleratorType) bool {
+	return strings.HasPrefix(t.String(), "NVIDIA_")
+}
+
+// acceleratorTypeFromCompute converts Compute Engine accelerator type name, e.g. "nvidia-tesla-t4", into Notebooks API format.
+func acceleratorTypeFromCompute(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// acceleratorTypeToCompute converts Notebooks API accelerator type name, e.g. "NVIDIA_TESLA_T4", into Compute Engine format.
+func acceleratorTypeToCompute(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", "-"))
+}
+
+// IsZonalAccelerator reports whether availability of the accelerator type differs among zones, which is true for GPUs.
+// TPUs are not listed in Compute Engine accelerator types, so they cannot be checked with ListAcceleratorTypes.
+func IsZonalAccelerator(acceleratorType string) bool {
+	t, err := parseAcceleratorType(acceleratorType)
+	return err == nil && isGPU(t)
+}
+
+// acceleratorFromProto returns the accelerator type and count attached to the instance, or empty type if none is attached.
+func acceleratorFromProto(config *notebookspb.Instance_AcceleratorConfig) (string, int64) {
+	if config.GetType() == notebookspb.Instance_ACCELERATOR_TYPE_UNSPECIFIED {
+		return "", 0
+	}
+	return config.GetType().String(), config.GetCoreCount()
+}
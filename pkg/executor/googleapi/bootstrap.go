@@ -0,0 +1,245 @@
+package googleapi
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+const (
+	// GuestAttributeNamespace is the namespace of guest attributes that the bootstrap script reports its progress to
+	GuestAttributeNamespace = "wbtemporal"
+	// BootstrapStatusKey is the guest attribute key of the bootstrap status, one of BootstrapStatus* values
+	BootstrapStatusKey = "bootstrap-status"
+	// BootstrapMessageKey is the guest attribute key of the message describing why the bootstrap failed
+	BootstrapMessageKey = "bootstrap-message"
+
+	BootstrapStatusRunning = "running"
+	BootstrapStatusDone    = "done"
+	BootstrapStatusFailed  = "failed"
+
+	// startupScriptMetadataKey is the metadata key of the script run by the guest environment every time the VM boots up
+	startupScriptMetadataKey = "startup-script"
+	// guestAttributesMetadataKey is the metadata key to allow the VM to write guest attributes
+	guestAttributesMetadataKey = "enable-guest-attributes"
+	// maxMetadataValueSize is the maximum size of a metadata value
+	// https://cloud.google.com/compute/docs/metadata/setting-custom-metadata#limitations
+	maxMetadataValueSize = 256 * 1024
+
+	// bootstrapUser is the user running JupyterLab on Deep Learning VM images, who owns cloned repositories
+	bootstrapUser = "jupyter"
+)
+
+var (
+	envNameRegexp   = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	gitConfigRegexp = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9-]*(\.[^\s.]+)*\.[A-Za-z][A-Za-z0-9-]*$`)
+)
+
+// Bootstrap is the declarative setup of new workspace, rendered into the startup script in instance metadata.
+// The script runs once after the VM boots up for the first time, and reports its progress to guest attributes
+// in GuestAttributeNamespace so that the workflow can wait for the bootstrap to finish.
+// Bootstrap is supported only on VM images, because the script runs on the VM, not in the custom container.
+type Bootstrap struct {
+	// GitRepositories indicates the repositories cloned into the home directory of the JupyterLab user
+	GitRepositories []GitRepository
+	// GitConfig indicates the system-wide git configuration, e.g. "user.name" or "credential.helper"
+	GitConfig map[string]string
+	// PipPackages indicates the packages installed with pip, e.g. "pandas==2.0.3"
+	PipPackages []string
+	// CondaPackages indicates the packages installed with conda
+	CondaPackages []string
+	// Env indicates the environment variables exported to login shells and the rest of the bootstrap
+	Env map[string]string
+	// PostStartupScript indicates the content of the script run after the other bootstrap steps
+	PostStartupScript string
+	// PostStartupScriptURL indicates the URL ("https://...") or Cloud Storage path ("gs://...") of the script
+	// run after the other bootstrap steps, which cannot be used together with PostStartupScript
+	PostStartupScriptURL string
+}
+
+// GitRepository is the git repository cloned by the bootstrap
+type GitRepository struct {
+	// URL indicates the repository URL to clone
+	URL string
+	// Branch indicates the branch to check out, defaults to the default branch of the repository
+	Branch string
+	// Dir indicates the directory to clone into relative to the home directory, defaults to the repository name
+	Dir string
+}
+
+// ParseGitRepository parses "<url>[#<branch>]" format, e.g. "https://github.com/toVersus/wbtemporal.git#main".
+func ParseGitRepository(s string) GitRepository {
+	u, branch, _ := strings.Cut(s, "#")
+	return GitRepository{URL: u, Branch: branch}
+}
+
+func (r GitRepository) dir() string {
+	if r.Dir != "" {
+		return r.Dir
+	}
+	// git@github.com:org/repo.git や https://github.com/org/repo.git から "repo" を取り出す
+	u := strings.TrimSuffix(strings.TrimSuffix(r.URL, "/"), ".git")
+	return path.Base(u[strings.LastIndexAny(u, ":/")+1:])
+}
+
+func (b *Bootstrap) validate() error {
+	var errs []error
+	dirs := map[string]bool{}
+	for _, r := range b.GitRepositories {
+		if r.URL == "" {
+			errs = append(errs, errors.New("git repository URL is required"))
+			continue
+		}
+		dir := r.dir()
+		if dir == "" || dir == "." || path.IsAbs(dir) || strings.HasPrefix(path.Clean(dir), "..") {
+			errs = append(errs, fmt.Errorf("invalid directory %q to clone %s, must be relative to the home directory", dir, r.URL))
+			continue
+		}
+		if dirs[path.Clean(dir)] {
+			errs = append(errs, fmt.Errorf("multiple git repositories are cloned into the same directory %q", dir))
+		}
+		dirs[path.Clean(dir)] = true
+	}
+	for k := range b.GitConfig {
+		if !gitConfigRegexp.MatchString(k) {
+			errs = append(errs, fmt.Errorf("invalid git config key %q, use \"<section>.<name>\" format", k))
+		}
+	}
+	for k := range b.Env {
+		if !envNameRegexp.MatchString(k) {
+			errs = append(errs, fmt.Errorf("invalid environment variable name %q", k))
+		}
+	}
+	for _, packages := range [][]string{b.PipPackages, b.CondaPackages} {
+		for _, p := range packages {
+			if p == "" || strings.HasPrefix(p, "-") {
+				errs = append(errs, fmt.Errorf("invalid package %q, options cannot be passed as packages", p))
+			}
+		}
+	}
+	if b.PostStartupScript != "" && b.PostStartupScriptURL != "" {
+		errs = append(errs, errors.New("post-startup script and its URL cannot be specified at the same time"))
+	}
+	if b.PostStartupScriptURL != "" {
+		u, err := url.Parse(b.PostStartupScriptURL)
+		if err != nil || (u.Scheme != "gs" && u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("invalid post-startup script URL %q, use \"gs://\" or \"https://\" URL", b.PostStartupScriptURL))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	script, err := b.render()
+	if err != nil {
+		return err
+	}
+	if len(script) > maxMetadataValueSize {
+		return fmt.Errorf("bootstrap script is too large, must be up to %d bytes but %d bytes", maxMetadataValueSize, len(script))
+	}
+	return nil
+}
+
+// render renders the bootstrap into bash script.
+func (b *Bootstrap) render() (string, error) {
+	var buf bytes.Buffer
+	if err := bootstrapTemplate.Execute(&buf, b); err != nil {
+		return "", fmt.Errorf("failed to render bootstrap script: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// shellQuote quotes the string to be passed to bash as a single word.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+var bootstrapTemplate = template.Must(template.New("bootstrap").Funcs(template.FuncMap{
+	"quote":     shellQuote,
+	"hasPrefix": strings.HasPrefix,
+	"base64": func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	},
+	"dir": func(r GitRepository) string {
+		return r.dir()
+	},
+}).Parse(`#!/bin/bash
+# Rendered by wbtemporal from the bootstrap option, changes are overwritten.
+set -euo pipefail
+
+readonly GUEST_ATTRIBUTES_URL="http://metadata.google.internal/computeMetadata/v1/instance/guest-attributes/` + GuestAttributeNamespace + `"
+readonly STATE_DIR=/var/lib/wbtemporal
+readonly HOME_DIR=/home/` + bootstrapUser + `
+
+report() {
+  curl -sf -X PUT -H "Metadata-Flavor: Google" --data "$2" "${GUEST_ATTRIBUTES_URL}/$1" || true
+}
+
+# startup-script runs on every boot, but the bootstrap runs only once
+if [[ -f "${STATE_DIR}/bootstrap-done" ]]; then
+  report ` + BootstrapStatusKey + ` ` + BootstrapStatusDone + `
+  exit 0
+fi
+
+step="initialization"
+trap 'report ` + BootstrapMessageKey + ` "failed at ${step}"; report ` + BootstrapStatusKey + ` ` + BootstrapStatusFailed + `' ERR
+report ` + BootstrapStatusKey + ` ` + BootstrapStatusRunning + `
+mkdir -p "${STATE_DIR}"
+{{- if .Env}}
+
+step="environment variables"
+: > /etc/profile.d/wbtemporal.sh
+{{- range $k, $v := .Env}}
+echo {{quote (printf "export %s=%s" $k (quote $v))}} >> /etc/profile.d/wbtemporal.sh
+{{- end}}
+source /etc/profile.d/wbtemporal.sh
+{{- end}}
+{{- if .GitConfig}}
+
+step="git config"
+{{- range $k, $v := .GitConfig}}
+git config --system {{quote $k}} {{quote $v}}
+{{- end}}
+{{- end}}
+{{- range .GitRepositories}}
+
+step={{quote (printf "git clone %s" .URL)}}
+if [[ ! -d "${HOME_DIR}"/{{quote (dir .)}} ]]; then
+  sudo -u ` + bootstrapUser + ` -H git clone{{if .Branch}} --branch {{quote .Branch}}{{end}} -- {{quote .URL}} "${HOME_DIR}"/{{quote (dir .)}}
+fi
+{{- end}}
+{{- if .PipPackages}}
+
+step="pip install"
+/opt/conda/bin/pip install{{range .PipPackages}} {{quote .}}{{end}}
+{{- end}}
+{{- if .CondaPackages}}
+
+step="conda install"
+/opt/conda/bin/conda install -y{{range .CondaPackages}} {{quote .}}{{end}}
+{{- end}}
+{{- if .PostStartupScript}}
+
+step="post-startup script"
+echo {{quote (base64 .PostStartupScript)}} | base64 -d > "${STATE_DIR}/post-startup-script.sh"
+bash "${STATE_DIR}/post-startup-script.sh"
+{{- else if .PostStartupScriptURL}}
+
+step="post-startup script"
+{{- if hasPrefix .PostStartupScriptURL "gs://"}}
+gsutil cp {{quote .PostStartupScriptURL}} "${STATE_DIR}/post-startup-script.sh"
+{{- else}}
+curl -sSfL -o "${STATE_DIR}/post-startup-script.sh" {{quote .PostStartupScriptURL}}
+{{- end}}
+bash "${STATE_DIR}/post-startup-script.sh"
+{{- end}}
+
+touch "${STATE_DIR}/bootstrap-done"
+report ` + BootstrapStatusKey + ` ` + BootstrapStatusDone + `
+`))
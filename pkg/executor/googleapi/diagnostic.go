@@ -0,0 +1,66 @@
+package googleapi
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"cloud.google.com/go/notebooks/apiv1/notebookspb"
+)
+
+// bucketRegexp roughly checks the Cloud Storage bucket name, which is specified without "gs://" prefix.
+// https://cloud.google.com/storage/docs/buckets#naming
+var bucketRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{1,220}[a-z0-9]$`)
+
+// Diagnostic is the configuration to collect diagnostic logs of the instance, which are uploaded to Cloud Storage
+// as "gs://<GcsBucket>/<RelativePath>/<VM>_<DATE>_<TIME>.tar.gz". The service account of the instance must be able to write to the bucket.
+type Diagnostic struct {
+	// GcsBucket indicates the Cloud Storage bucket name the diagnostic logs are uploaded to, e.g. "sample-diagnostics"
+	GcsBucket string
+	// RelativePath indicates the path in the bucket, defaults to the root of the bucket
+	RelativePath string
+	// Repair indicates whether the repair service is run on the instance as well
+	Repair bool
+	// PacketCapture indicates whether packets of the instance are captured for 30 seconds
+	PacketCapture bool
+	// CopyHomeFiles indicates whether the contents of the home directory are included in the diagnostic logs
+	CopyHomeFiles bool
+}
+
+// Validate checks the diagnostic configuration, problems are wrapped with ErrInvalidOption.
+func (d *Diagnostic) Validate() error {
+	var errs []error
+	if strings.HasPrefix(d.GcsBucket, "gs://") {
+		errs = append(errs, fmt.Errorf("invalid bucket %q, specify bucket name without \"gs://\" prefix", d.GcsBucket))
+	} else if !bucketRegexp.MatchString(d.GcsBucket) {
+		errs = append(errs, fmt.Errorf("invalid bucket %q to upload diagnostic logs", d.GcsBucket))
+	}
+	if path.IsAbs(d.RelativePath) || strings.HasPrefix(path.Clean(d.RelativePath), "..") {
+		errs = append(errs, fmt.Errorf("invalid path %q, must be relative to the root of the bucket", d.RelativePath))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrInvalidOption, errors.Join(errs...))
+}
+
+// Location returns the Cloud Storage location the diagnostic logs are uploaded to, e.g. "gs://sample-diagnostics/logs/".
+func (d *Diagnostic) Location() string {
+	p := strings.Trim(path.Clean(d.RelativePath), "/")
+	if p == "." || p == "" {
+		return fmt.Sprintf("gs://%s/", d.GcsBucket)
+	}
+	return fmt.Sprintf("gs://%s/%s/", d.GcsBucket, p)
+}
+
+func (d *Diagnostic) proto() *notebookspb.DiagnosticConfig {
+	return &notebookspb.DiagnosticConfig{
+		GcsBucket:                d.GcsBucket,
+		RelativePath:             d.RelativePath,
+		RepairFlagEnabled:        d.Repair,
+		PacketCaptureFlagEnabled: d.PacketCapture,
+		CopyHomeFilesFlagEnabled: d.CopyHomeFiles,
+	}
+}
@@ -0,0 +1,135 @@
+package googleapi
+
+import (
+	"errors"
+	"fmt"
+
+	"cloud.google.com/go/notebooks/apiv1/notebookspb"
+)
+
+// EnvironmentOption is the option of notebook environment, which is the VM image or the container image approved for Workbench instances.
+type EnvironmentOption struct {
+	// Name indicates the environment ID, e.g. "pytorch-2", which is specified as Option.Environment to create instances
+	Name string
+	// Zone indicates the location of the environment, which must be the same as the zone of the instances created from it
+	Zone string
+	// ProjectId indicates the GCP project ID
+	ProjectId string
+	// DisplayName indicates the human readable name of the environment
+	DisplayName string
+	// Description indicates the description of the environment
+	Description string
+	// ImageProject indicates the Google Cloud project that the VM image belongs to, defaults to DefaultImageProject
+	ImageProject string
+	// ImageFamily indicates the VM image family, the latest image in the family is used
+	ImageFamily string
+	// ImageName indicates the VM image name, which cannot be used together with ImageFamily
+	ImageName string
+	// ContainerRepository indicates the custom container image repository, which cannot be used together with VM image
+	ContainerRepository string
+	// ContainerTag indicates the tag of the custom container image, defaults to "latest"
+	ContainerTag string
+}
+
+type EnvironmentStatus struct {
+	Name                string
+	DisplayName         string
+	Description         string
+	ImageProject        string
+	ImageFamily         string
+	ImageName           string
+	ContainerRepository string
+	ContainerTag        string
+}
+
+// Validate checks the option to create notebook environment.
+// All problems found are returned at once, wrapped with ErrInvalidOption.
+func (o *EnvironmentOption) Validate() error {
+	var errs []error
+	if o.Name == "" {
+		errs = append(errs, errors.New("name is required"))
+	}
+	if o.ProjectId == "" {
+		errs = append(errs, errors.New("project ID is required"))
+	}
+	if o.Zone == "" {
+		errs = append(errs, errors.New("zone is required"))
+	}
+
+	// インスタンスと違って既定のイメージは使わず、カタログに登録するイメージを明示させる
+	switch {
+	case o.ContainerRepository != "":
+		if o.ImageProject != "" || o.ImageFamily != "" || o.ImageName != "" {
+			errs = append(errs, errors.New("container image and VM image cannot be specified at the same time"))
+		}
+	case o.ImageFamily != "" && o.ImageName != "":
+		errs = append(errs, errors.New("image family and image name cannot be specified at the same time"))
+	case o.ImageFamily == "" && o.ImageName == "":
+		errs = append(errs, errors.New("either VM image family, VM image name or container repository is required"))
+	}
+	if o.ContainerRepository == "" && o.ContainerTag != "" {
+		errs = append(errs, errors.New("container tag requires container repository"))
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrInvalidOption, errors.Join(errs...))
+}
+
+// proto returns the environment to create.
+func (o *EnvironmentOption) proto() *notebookspb.Environment {
+	env := &notebookspb.Environment{
+		DisplayName: o.DisplayName,
+		Description: o.Description,
+	}
+	// イメージの組み立ては Workbench インスタンスの作成と同じ規則に従う
+	image := &Option{
+		ImageProject:        o.ImageProject,
+		ImageFamily:         o.ImageFamily,
+		ImageName:           o.ImageName,
+		ContainerRepository: o.ContainerRepository,
+		ContainerTag:        o.ContainerTag,
+	}
+	if c := image.containerImage(); c != nil {
+		env.ImageType = &notebookspb.Environment_ContainerImage{ContainerImage: c}
+	} else {
+		env.ImageType = &notebookspb.Environment_VmImage{VmImage: image.vmImage()}
+	}
+	return env
+}
+
+func environmentStatusFromProto(e *notebookspb.Environment) EnvironmentStatus {
+	status := EnvironmentStatus{
+		Name:        e.GetName(),
+		DisplayName: e.GetDisplayName(),
+		Description: e.GetDescription(),
+	}
+	if c := e.GetContainerImage(); c != nil {
+		status.ContainerRepository = c.GetRepository()
+		status.ContainerTag = c.GetTag()
+	}
+	if vm := e.GetVmImage(); vm != nil {
+		status.ImageProject = vm.GetProject()
+		status.ImageFamily = vm.GetImageFamily()
+		status.ImageName = vm.GetImageName()
+	}
+	return status
+}
+
+// WithEnvironment returns the copy of the option creating the instance from the image of the environment.
+// Environment of the returned option is cleared, since it has been resolved into the image fields.
+func (o *Option) WithEnvironment(env *EnvironmentStatus) *Option {
+	resolved := *o
+	resolved.Environment = ""
+	resolved.ImageProject = env.ImageProject
+	resolved.ImageFamily = env.ImageFamily
+	resolved.ImageName = env.ImageName
+	resolved.ContainerRepository = env.ContainerRepository
+	resolved.ContainerTag = env.ContainerTag
+	return &resolved
+}
+
+func environmentFullname(projectID, zone, name string) string {
+	return fmt.Sprintf("projects/%s/locations/%s/environments/%s", projectID, zone, name)
+}
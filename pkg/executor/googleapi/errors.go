@@ -0,0 +1,70 @@
+package googleapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	gapi "google.golang.org/api/googleapi"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Errors returned by executors, classified from gRPC status codes of Google Cloud APIs.
+// Use errors.Is to check them, the original error is still available through errors.Unwrap chain.
+var (
+	ErrNotFound           = errors.New("not found")
+	ErrPermissionDenied   = errors.New("permission denied")
+	ErrInvalidArgument    = errors.New("invalid argument")
+	ErrResourceExhausted  = errors.New("resource exhausted")
+	ErrFailedPrecondition = errors.New("failed precondition")
+	ErrUnimplemented      = errors.New("unimplemented")
+)
+
+var httpCodeErrors = map[int]error{
+	http.StatusNotFound:           ErrNotFound,
+	http.StatusForbidden:          ErrPermissionDenied,
+	http.StatusBadRequest:         ErrInvalidArgument,
+	http.StatusTooManyRequests:    ErrResourceExhausted,
+	http.StatusPreconditionFailed: ErrFailedPrecondition,
+	http.StatusNotImplemented:     ErrUnimplemented,
+}
+
+var codeErrors = map[codes.Code]error{
+	codes.NotFound:           ErrNotFound,
+	codes.PermissionDenied:   ErrPermissionDenied,
+	codes.InvalidArgument:    ErrInvalidArgument,
+	codes.ResourceExhausted:  ErrResourceExhausted,
+	codes.FailedPrecondition: ErrFailedPrecondition,
+	codes.Unimplemented:      ErrUnimplemented,
+}
+
+// classifyError wraps err with the typed error corresponding to its gRPC status code,
+// or HTTP status code for APIs called through REST clients. Errors with other codes are returned as they are.
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, typed := range httpCodeErrors {
+		if errors.Is(err, typed) {
+			return err
+		}
+	}
+	// REST クライアントのエラーは gRPC のステータスを持たないので HTTP のステータスコードで判定する
+	var httpErr *gapi.Error
+	if errors.As(err, &httpErr) {
+		if typed, ok := httpCodeErrors[httpErr.Code]; ok {
+			return fmt.Errorf("%w: %w", typed, err)
+		}
+		return err
+	}
+	s, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	typed, ok := codeErrors[s.Code()]
+	if !ok {
+		return err
+	}
+	return fmt.Errorf("%w: %w", typed, err)
+}
@@ -0,0 +1,237 @@
+package googleapi
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"cloud.google.com/go/notebooks/apiv1/notebookspb"
+)
+
+const (
+	// DefaultExecutionMachineType is the machine type running notebook executions used when omitted
+	DefaultExecutionMachineType = "n1-standard-4"
+	// DefaultExecutionContainerImage is the container image running notebook executions used when omitted
+	DefaultExecutionContainerImage = "gcr.io/deeplearning-platform-release/base-cpu:latest"
+)
+
+// ExecutionOption is the option of headless notebook execution, which runs the notebook on Cloud Storage
+// as a Vertex AI custom job and writes the executed notebook back to Cloud Storage.
+type ExecutionOption struct {
+	// Name indicates the execution ID, which must be unique in the location
+	Name string
+	// Location indicates the region running the execution, e.g. "asia-northeast1"
+	Location string
+	// ProjectId indicates the GCP project ID
+	ProjectId string
+	// InputNotebookFile indicates the notebook to execute, e.g. "gs://sample/notebooks/train.ipynb"
+	InputNotebookFile string
+	// OutputNotebookFolder indicates the folder the executed notebook is written to, e.g. "gs://sample/outputs"
+	OutputNotebookFolder string
+	// Parameters indicates the values passed to the cell tagged with "parameters" in the notebook through papermill
+	Parameters map[string]string
+	// MachineType indicates the machine type running the execution, DefaultExecutionMachineType is used if omitted
+	MachineType string
+	// AcceleratorType indicates the accelerator attached to the machine, e.g. "NVIDIA_TESLA_T4"
+	AcceleratorType string
+	// AcceleratorCount indicates the number of accelerators attached to the machine
+	AcceleratorCount int64
+	// ContainerImage indicates the container image running the notebook, DefaultExecutionContainerImage is used if omitted
+	ContainerImage string
+	// ServiceAccount indicates the service account email the execution runs as, Compute Engine default service account is used if omitted
+	ServiceAccount string
+	// KernelSpec indicates the kernel to run the notebook, required if it doesn't match the kernel in the notebook
+	KernelSpec string
+	// Labels indicates the labels attached to the execution
+	Labels map[string]string
+}
+
+type ExecutionStatus struct {
+	Name  string
+	State ExecutionState
+	// OutputNotebookFile indicates the executed notebook on Cloud Storage, e.g. "gs://sample/outputs/sample/train.ipynb"
+	OutputNotebookFile string
+	// JobURI indicates the Vertex AI custom job running the execution
+	JobURI string
+}
+
+// ExecutionState is the state of notebook execution, the values are the same as notebookspb.Execution_State names.
+type ExecutionState string
+
+const (
+	ExecutionStateUnspecified  ExecutionState = "STATE_UNSPECIFIED"
+	ExecutionStateQueued       ExecutionState = "QUEUED"
+	ExecutionStatePreparing    ExecutionState = "PREPARING"
+	ExecutionStateRunning      ExecutionState = "RUNNING"
+	ExecutionStateSucceeded    ExecutionState = "SUCCEEDED"
+	ExecutionStateFailed       ExecutionState = "FAILED"
+	ExecutionStateCancelling   ExecutionState = "CANCELLING"
+	ExecutionStateCancelled    ExecutionState = "CANCELLED"
+	ExecutionStateExpired      ExecutionState = "EXPIRED"
+	ExecutionStateInitializing ExecutionState = "INITIALIZING"
+)
+
+var executionStates = map[notebookspb.Execution_State]ExecutionState{
+	notebookspb.Execution_STATE_UNSPECIFIED: ExecutionStateUnspecified,
+	notebookspb.Execution_QUEUED:            ExecutionStateQueued,
+	notebookspb.Execution_PREPARING:         ExecutionStatePreparing,
+	notebookspb.Execution_RUNNING:           ExecutionStateRunning,
+	notebookspb.Execution_SUCCEEDED:         ExecutionStateSucceeded,
+	notebookspb.Execution_FAILED:            ExecutionStateFailed,
+	notebookspb.Execution_CANCELLING:        ExecutionStateCancelling,
+	notebookspb.Execution_CANCELLED:         ExecutionStateCancelled,
+	notebookspb.Execution_EXPIRED:           ExecutionStateExpired,
+	notebookspb.Execution_INITIALIZING:      ExecutionStateInitializing,
+}
+
+// executionStateFromProto converts the state returned from Notebooks API, unknown states added to the API are treated as unspecified.
+func executionStateFromProto(state notebookspb.Execution_State) ExecutionState {
+	if s, ok := executionStates[state]; ok {
+		return s
+	}
+	return ExecutionStateUnspecified
+}
+
+func executionStatusFromProto(e *notebookspb.Execution) ExecutionStatus {
+	return ExecutionStatus{
+		Name:               e.GetName(),
+		State:              executionStateFromProto(e.GetState()),
+		OutputNotebookFile: e.GetOutputNotebookFile(),
+		JobURI:             e.GetJobUri(),
+	}
+}
+
+// IsTerminal reports whether the execution has finished, either successfully or not.
+func (s ExecutionState) IsTerminal() bool {
+	switch s {
+	case ExecutionStateSucceeded, ExecutionStateFailed, ExecutionStateCancelled, ExecutionStateExpired:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsSucceeded reports whether the execution has finished successfully.
+func (s ExecutionState) IsSucceeded() bool {
+	return s == ExecutionStateSucceeded
+}
+
+// Validate checks the option to execute notebook.
+// All problems found are returned at once, wrapped with ErrInvalidOption.
+func (o *ExecutionOption) Validate() error {
+	errs := o.check()
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrInvalidOption, errors.Join(errs...))
+}
+
+// check returns all problems found in the option, shared with the execution template of schedules.
+func (o *ExecutionOption) check() []error {
+	var errs []error
+	if o.Name == "" {
+		errs = append(errs, errors.New("name is required"))
+	}
+	if o.ProjectId == "" {
+		errs = append(errs, errors.New("project ID is required"))
+	}
+	if o.Location == "" {
+		errs = append(errs, errors.New("location is required"))
+	}
+	if !strings.HasPrefix(o.InputNotebookFile, "gs://") || path.Ext(o.InputNotebookFile) != ".ipynb" {
+		errs = append(errs, fmt.Errorf("invalid input notebook %q, use \"gs://<bucket>/<path>.ipynb\" format", o.InputNotebookFile))
+	}
+	if !strings.HasPrefix(o.OutputNotebookFolder, "gs://") {
+		errs = append(errs, fmt.Errorf("invalid output folder %q, use \"gs://<bucket>/<path>\" format", o.OutputNotebookFolder))
+	}
+	for k := range o.Parameters {
+		if k == "" || strings.ContainsAny(k, ",=") {
+			errs = append(errs, fmt.Errorf("invalid parameter name %q", k))
+		}
+	}
+	if err := checkExecutionAccelerator(o.machineType(), o.AcceleratorType, o.AcceleratorCount); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkLabels(o.Labels); err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+func (o *ExecutionOption) machineType() string {
+	if o.MachineType == "" {
+		return DefaultExecutionMachineType
+	}
+	return o.MachineType
+}
+
+// parameters renders the parameters as "<key>=<value>" pairs joined with commas, sorted by key to be deterministic.
+func (o *ExecutionOption) parameters() string {
+	keys := make([]string, 0, len(o.Parameters))
+	for k := range o.Parameters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", k, o.Parameters[k]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// proto returns the execution to create.
+func (o *ExecutionOption) proto() *notebookspb.Execution {
+	return &notebookspb.Execution{
+		ExecutionTemplate: o.template(),
+		DisplayName:       o.Name,
+	}
+}
+
+// template returns the execution template, which is also used by schedules to create executions.
+func (o *ExecutionOption) template() *notebookspb.ExecutionTemplate {
+	image := o.ContainerImage
+	if image == "" {
+		image = DefaultExecutionContainerImage
+	}
+	template := &notebookspb.ExecutionTemplate{
+		// 現在は CUSTOM 以外のスケール階層はサポートされていない
+		ScaleTier:            notebookspb.ExecutionTemplate_CUSTOM,
+		MasterType:           o.machineType(),
+		Labels:               o.Labels,
+		InputNotebookFile:    o.InputNotebookFile,
+		ContainerImageUri:    image,
+		OutputNotebookFolder: strings.TrimSuffix(o.OutputNotebookFolder, "/"),
+		Parameters:           o.parameters(),
+		ServiceAccount:       o.ServiceAccount,
+		JobType:              notebookspb.ExecutionTemplate_VERTEX_AI,
+		KernelSpec:           o.KernelSpec,
+	}
+	if o.AcceleratorType != "" {
+		v := notebookspb.ExecutionTemplate_SchedulerAcceleratorType_value[strings.ToUpper(o.AcceleratorType)]
+		template.AcceleratorConfig = &notebookspb.ExecutionTemplate_SchedulerAcceleratorConfig{
+			Type:      notebookspb.ExecutionTemplate_SchedulerAcceleratorType(v),
+			CoreCount: o.AcceleratorCount,
+		}
+	}
+	return template
+}
+
+// checkExecutionAccelerator is the same as checkAccelerator, but also checks that the accelerator is supported by executions.
+func checkExecutionAccelerator(machineType, acceleratorType string, count int64) error {
+	if err := checkAccelerator(machineType, acceleratorType, count, false); err != nil {
+		return err
+	}
+	if acceleratorType == "" {
+		return nil
+	}
+	if _, ok := notebookspb.ExecutionTemplate_SchedulerAcceleratorType_value[strings.ToUpper(acceleratorType)]; !ok {
+		return fmt.Errorf("accelerator type %q is not supported by notebook executions", acceleratorType)
+	}
+	return nil
+}
+
+func executionFullname(projectID, location, name string) string {
+	return fmt.Sprintf("projects/%s/locations/%s/executions/%s", projectID, location, name)
+}
@@ -3,11 +3,13 @@ package googleapi
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 const (
-	ExecutorNameGoogleAPI  = "googleapi"
-	ExecutorNameFakeClient = "fakeclient"
+	ExecutorNameGoogleAPI   = "googleapi"
+	ExecutorNameGoogleAPIV2 = "googleapi-v2"
+	ExecutorNameFakeClient  = "fakeclient"
 )
 
 var (
@@ -34,12 +36,92 @@ type Option struct {
 	Network string
 	// Subnet indicates the subnet that workspace instances are deployed to
 	Subnet string
+	// Environment indicates the notebook environment in the zone to create the workspace from, which cannot be used together with
+	// VM image or container image. The workflow resolves it into the image fields with Option.WithEnvironment before creation.
+	Environment string
+	// ImageProject indicates the Google Cloud project that the VM image belongs to.
+	// DefaultImageProject is used if both of VM image and container image are omitted.
+	ImageProject string
+	// ImageFamily indicates the VM image family, the latest image in the family is used
+	ImageFamily string
+	// ImageName indicates the VM image name, which cannot be used together with ImageFamily
+	ImageName string
+	// ContainerRepository indicates the custom container image repository, e.g. "gcr.io/deeplearning-platform-release/base-cpu".
+	// VM image is not used if container image is specified.
+	ContainerRepository string
+	// ContainerTag indicates the tag of the custom container image, defaults to "latest"
+	ContainerTag string
+	// BootDiskType indicates the boot disk type, e.g. "PD_BALANCED"
+	BootDiskType string
+	// BootDiskSizeGB indicates the boot disk size in GB
+	BootDiskSizeGB int64
+	// DataDiskType indicates the data disk type, e.g. "PD_BALANCED"
+	DataDiskType string
+	// DataDiskSizeGB indicates the data disk size in GB
+	DataDiskSizeGB int64
+	// DiskEncryption indicates how boot and data disks are encrypted, "GMEK" or "CMEK"
+	DiskEncryption string
+	// KmsKey indicates the Cloud KMS key to encrypt disks, which is required when DiskEncryption is "CMEK".
+	// Use "projects/{project_id}/locations/{location}/keyRings/{key_ring_id}/cryptoKeys/{key_id}" format.
+	KmsKey string
+	// AcceleratorType indicates the accelerator attached to the workspace, e.g. "NVIDIA_TESLA_T4"
+	AcceleratorType string
+	// AcceleratorCount indicates the number of accelerators attached to the workspace
+	AcceleratorCount int64
+	// InstallGpuDriver indicates whether NVIDIA GPU driver is installed automatically
+	InstallGpuDriver bool
+	// NoPublicIP indicates whether the workspace is created without public IP address.
+	// Nil means the default of the worker's security profile, or public IP address is assigned if the profile omits it.
+	NoPublicIP *bool
+	// ServiceAccount indicates the service account email that the workspace VM runs as,
+	// Compute Engine default service account is used if omitted
+	ServiceAccount string
+	// Tags indicates the network tags attached to the workspace VM
+	Tags []string
+	// ShieldedSecureBoot indicates whether Shielded VM secure boot is enabled
+	ShieldedSecureBoot *bool
+	// ShieldedVtpm indicates whether Shielded VM vTPM is enabled
+	ShieldedVtpm *bool
+	// ShieldedIntegrityMonitoring indicates whether Shielded VM integrity monitoring is enabled, which requires vTPM
+	ShieldedIntegrityMonitoring *bool
+	// Labels indicates the labels attached to the workspace, e.g. cost center or team.
+	// SetNotebookInstanceLabels replaces all labels of existing workspace with them.
+	Labels map[string]string
+	// Metadata indicates the custom metadata of the workspace VM
+	Metadata map[string]string
+	// IdleTimeout indicates the idle time before the workspace is shut down automatically, zero means idle shutdown is not configured
+	IdleTimeout time.Duration
+	// Bootstrap indicates the setup run on the workspace VM after it is created, nil means no setup
+	Bootstrap *Bootstrap
+	// Snapshot indicates the snapshot that RollbackNotebookInstance restores the workspace from,
+	// which is taken before each upgrade and listed in Status.UpgradeHistory
+	Snapshot string
+	// Diagnostic indicates where and how DiagnoseNotebookInstance collects the diagnostic logs of the workspace
+	Diagnostic *Diagnostic
+}
+
+// AcceleratorType is the accelerator available in a zone
+type AcceleratorType struct {
+	// Name indicates the accelerator type in Notebooks API format, e.g. "NVIDIA_TESLA_T4"
+	Name string
+	// MaxCount indicates the maximum number of the accelerators attached to an instance
+	MaxCount int64
 }
 
 type Status struct {
 	Name   string
 	URL    string
-	Status string
+	Status InstanceState
+	// MachineType indicates the machine type of the instance, e.g. "n1-standard-4"
+	MachineType string
+	// AcceleratorType indicates the accelerator attached to the instance, e.g. "NVIDIA_TESLA_T4", empty if none is attached
+	AcceleratorType string
+	// AcceleratorCount indicates the number of accelerators attached to the instance
+	AcceleratorCount int64
+	Labels           map[string]string
+	Metadata         map[string]string
+	// UpgradeHistory indicates the upgrades and rollbacks of the instance in chronological order
+	UpgradeHistory []UpgradeHistoryEntry
 }
 
 // NotebookService is an interface for interacting with Google Cloud Notebooks API
@@ -49,13 +131,83 @@ type NotebookService interface {
 	StartNotebookInstance(ctx context.Context, option *Option) (string, error)
 	StopNotebookInstance(ctx context.Context, option *Option) (string, error)
 	DeleteNotebookInstance(ctx context.Context, option *Option) (string, error)
+	SetNotebookInstanceLabels(ctx context.Context, option *Option) (string, error)
+	// SetNotebookInstanceMachineType changes the machine type to option.MachineType, the instance must be stopped.
+	SetNotebookInstanceMachineType(ctx context.Context, option *Option) (string, error)
+	// SetNotebookInstanceAccelerator changes the accelerator to option.AcceleratorType and AcceleratorCount, the instance must be stopped.
+	SetNotebookInstanceAccelerator(ctx context.Context, option *Option) (string, error)
+	// SetNotebookInstanceIdleTimeout updates the idle shutdown metadata of the instance.
+	// Unlike other methods, the change is applied synchronously without long-running operation.
+	SetNotebookInstanceIdleTimeout(ctx context.Context, option *Option) error
+	IsNotebookInstanceUpgradeable(ctx context.Context, option *Option) (*Upgradeability, error)
+	// UpgradeNotebookInstance upgrades the image of the instance, after taking the snapshot of the boot disk for rollback.
+	UpgradeNotebookInstance(ctx context.Context, option *Option) (string, error)
+	// RollbackNotebookInstance restores the instance from option.Snapshot taken before the upgrade.
+	RollbackNotebookInstance(ctx context.Context, option *Option) (string, error)
+	// ResetNotebookInstance resets the VM of the instance like pressing the reset button, without shutting down the guest OS.
+	ResetNotebookInstance(ctx context.Context, option *Option) (string, error)
+	// DiagnoseNotebookInstance collects the diagnostic logs of the instance into Cloud Storage specified by option.Diagnostic.
+	DiagnoseNotebookInstance(ctx context.Context, option *Option) (string, error)
+	// CreateExecution runs the notebook on Cloud Storage as a batch job without instance, identified by option.Name.
+	// The operation finishes when the execution is created, use DescribeExecution to wait for the job.
+	CreateExecution(ctx context.Context, option *ExecutionOption) (string, error)
+	DescribeExecution(ctx context.Context, option *ExecutionOption) (*ExecutionStatus, error)
+}
+
+// ScheduleService is an interface for managing notebook schedules of Google Cloud Notebooks API
+type ScheduleService interface {
+	CreateSchedule(ctx context.Context, option *ScheduleOption) (string, error)
+	DescribeSchedule(ctx context.Context, option *ScheduleOption) (*ScheduleStatus, error)
+	// ListSchedules returns all schedules in option.ProjectId and option.Location, other fields are ignored.
+	ListSchedules(ctx context.Context, option *ScheduleOption) ([]ScheduleStatus, error)
+	DeleteSchedule(ctx context.Context, option *ScheduleOption) (string, error)
+	// TriggerSchedule creates the execution from the schedule immediately, regardless of the cron schedule.
+	TriggerSchedule(ctx context.Context, option *ScheduleOption) (string, error)
+}
+
+// EnvironmentService is an interface for managing notebook environments of Google Cloud Notebooks API
+type EnvironmentService interface {
+	CreateEnvironment(ctx context.Context, option *EnvironmentOption) (string, error)
+	DescribeEnvironment(ctx context.Context, option *EnvironmentOption) (*EnvironmentStatus, error)
+	// ListEnvironments returns all environments in option.ProjectId and option.Zone, other fields are ignored.
+	ListEnvironments(ctx context.Context, option *EnvironmentOption) ([]EnvironmentStatus, error)
+	DeleteEnvironment(ctx context.Context, option *EnvironmentOption) (string, error)
 }
 
 type LongRunningOperationService interface {
 	HasOperationDone(ctx context.Context, opName string) (bool, error)
 }
 
+// ComputeService is an interface for interacting with Google Cloud Compute Engine API
+type ComputeService interface {
+	ListAcceleratorTypes(ctx context.Context, option *Option) ([]AcceleratorType, error)
+	// GetGuestAttributes returns the guest attributes in the namespace written from inside the workspace VM.
+	// ErrNotFound is returned if nothing has been written to the namespace yet.
+	GetGuestAttributes(ctx context.Context, option *Option, namespace string) (map[string]string, error)
+}
+
 type Executor interface {
 	NotebookService
+	ScheduleService
+	EnvironmentService
+	LongRunningOperationService
+	ComputeService
+}
+
+// RuntimeService is an interface for interacting with Google Cloud Managed Notebooks API
+type RuntimeService interface {
+	CreateRuntime(ctx context.Context, option *RuntimeOption) (string, error)
+	DescribeRuntime(ctx context.Context, option *RuntimeOption) (*RuntimeStatus, error)
+	StartRuntime(ctx context.Context, option *RuntimeOption) (string, error)
+	StopRuntime(ctx context.Context, option *RuntimeOption) (string, error)
+	DeleteRuntime(ctx context.Context, option *RuntimeOption) (string, error)
+	// SwitchRuntime changes the machine type and accelerator to option.MachineType, AcceleratorType and AcceleratorCount,
+	// the runtime must be stopped.
+	SwitchRuntime(ctx context.Context, option *RuntimeOption) (string, error)
+}
+
+// RuntimeExecutor is the executor of Google-managed notebook runtimes
+type RuntimeExecutor interface {
+	RuntimeService
 	LongRunningOperationService
 }
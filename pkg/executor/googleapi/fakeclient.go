@@ -0,0 +1,744 @@
+package googleapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/notebooks/apiv1/notebookspb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultFakeOperationDelay is the time it takes for a fake long-running operation to finish
+	DefaultFakeOperationDelay = 10 * time.Second
+	// DefaultFakeImageVersion is the image version of instances created by FakeClient
+	DefaultFakeImageVersion = "m109"
+	// DefaultFakeUpgradeVersion is the image version that instances of FakeClient can be upgraded to
+	DefaultFakeUpgradeVersion = "m110"
+)
+
+var (
+	_ Executor        = &FakeClient{}
+	_ RuntimeExecutor = &FakeClient{}
+
+	// DefaultFakeAcceleratorTypes is the accelerator types available in every zone of FakeClient unless WithFakeAcceleratorTypes is given
+	DefaultFakeAcceleratorTypes = []AcceleratorType{
+		{Name: "NVIDIA_TESLA_K80", MaxCount: 8},
+		{Name: "NVIDIA_TESLA_P100", MaxCount: 4},
+		{Name: "NVIDIA_TESLA_P100_VWS", MaxCount: 4},
+		{Name: "NVIDIA_TESLA_V100", MaxCount: 8},
+		{Name: "NVIDIA_TESLA_P4", MaxCount: 4},
+		{Name: "NVIDIA_TESLA_P4_VWS", MaxCount: 4},
+		{Name: "NVIDIA_TESLA_T4", MaxCount: 4},
+		{Name: "NVIDIA_TESLA_T4_VWS", MaxCount: 4},
+		{Name: "NVIDIA_TESLA_A100", MaxCount: 16},
+	}
+)
+
+// FakeClient is a stateful in-memory implementation of Executor and RuntimeExecutor.
+// It simulates the lifecycle of user managed notebook instances and managed notebook runtimes without calling Google Cloud APIs,
+// so that workflows can be run end to end in local demos and CI.
+type FakeClient struct {
+	mu sync.Mutex
+
+	delay            time.Duration
+	now              func() time.Time
+	acceleratorTypes map[string][]AcceleratorType
+	upgradeVersion   string
+
+	seq        int
+	instances  map[string]*fakeInstance
+	runtimes   map[string]*fakeRuntime
+	executions map[string]*fakeExecution
+	schedules  map[string]*fakeSchedule
+	// environments is registered when the operation creating it finishes, since it has no state
+	environments map[string]*EnvironmentStatus
+	operations   map[string]*fakeOperation
+
+	errors   map[string]*fakeError
+	opErrors map[string]*fakeError
+}
+
+type fakeInstance struct {
+	state    notebookspb.Instance_State
+	proxyURI string
+	option   Option
+	// guestAttributes is the guest attributes written from inside the VM, keyed by namespace
+	guestAttributes map[string]map[string]string
+	version         string
+	upgradeHistory  []UpgradeHistoryEntry
+}
+
+type fakeOperation struct {
+	doneAt time.Time
+	done   bool
+	err    error
+	// apply is called once when the operation finishes successfully
+	apply func()
+	// revert is called once when the operation aborts, to restore the state changed at the start of the operation
+	revert func()
+}
+
+type fakeError struct {
+	err error
+	// times is the remaining number of calls that fail, negative value means the error never expires
+	times int
+}
+
+// FakeClientOption configures FakeClient.
+type FakeClientOption func(*FakeClient)
+
+// WithFakeOperationDelay sets the time it takes for long-running operations to finish.
+func WithFakeOperationDelay(delay time.Duration) FakeClientOption {
+	return func(f *FakeClient) {
+		f.delay = delay
+	}
+}
+
+// WithFakeClock replaces the clock used to decide whether long-running operations have finished.
+func WithFakeClock(now func() time.Time) FakeClientOption {
+	return func(f *FakeClient) {
+		f.now = now
+	}
+}
+
+// WithFakeAcceleratorTypes sets the accelerator types available in the zone.
+// Zones not configured by this option offer DefaultFakeAcceleratorTypes.
+func WithFakeAcceleratorTypes(zone string, types ...AcceleratorType) FakeClientOption {
+	return func(f *FakeClient) {
+		f.acceleratorTypes[zone] = types
+	}
+}
+
+// WithFakeUpgradeVersion sets the image version that instances can be upgraded to.
+// Instances are not upgradeable if the version is empty or the same as DefaultFakeImageVersion.
+func WithFakeUpgradeVersion(version string) FakeClientOption {
+	return func(f *FakeClient) {
+		f.upgradeVersion = version
+	}
+}
+
+func NewFakeClient(opts ...FakeClientOption) *FakeClient {
+	f := &FakeClient{
+		delay:            DefaultFakeOperationDelay,
+		now:              time.Now,
+		acceleratorTypes: map[string][]AcceleratorType{},
+		upgradeVersion:   DefaultFakeUpgradeVersion,
+		instances:        map[string]*fakeInstance{},
+		runtimes:         map[string]*fakeRuntime{},
+		executions:       map[string]*fakeExecution{},
+		schedules:        map[string]*fakeSchedule{},
+		environments:     map[string]*EnvironmentStatus{},
+		operations:       map[string]*fakeOperation{},
+		errors:           map[string]*fakeError{},
+		opErrors:         map[string]*fakeError{},
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// InjectError makes the next `times` calls of the method return err.
+// The method is the name of the Executor or RuntimeExecutor method, e.g. "CreateNotebookInstance".
+// If times is zero or negative, the method keeps failing until ClearErrors is called.
+// gRPC status errors, e.g. status.Error(codes.PermissionDenied, "denied"), are classified into typed errors like ErrPermissionDenied.
+func (f *FakeClient) InjectError(method string, err error, times int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.errors[method] = newFakeError(err, times)
+}
+
+// InjectOperationError makes the next `times` long-running operations started by the method abort with err.
+// If times is zero or negative, operations keep aborting until ClearErrors is called.
+// Use "RunExecution" as the method to make notebook executions fail after they are created.
+func (f *FakeClient) InjectOperationError(method string, err error, times int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.opErrors[method] = newFakeError(err, times)
+}
+
+// ClearErrors removes all injected errors.
+func (f *FakeClient) ClearErrors() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.errors = map[string]*fakeError{}
+	f.opErrors = map[string]*fakeError{}
+}
+
+func (f *FakeClient) CreateNotebookInstance(ctx context.Context, option *Option) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("CreateNotebookInstance"); err != nil {
+		return "", err
+	}
+
+	fullname := notebookInstanceFullname(option.ProjectId, option.Zone, option.Name)
+	if _, ok := f.instances[fullname]; ok {
+		return "", classifyError(status.Errorf(codes.AlreadyExists, "notebook instance %q already exists", fullname))
+	}
+
+	instance := &fakeInstance{
+		state:   notebookspb.Instance_PROVISIONING,
+		option:  *option,
+		version: DefaultFakeImageVersion,
+	}
+	metadata, err := option.metadata()
+	if err != nil {
+		return "", err
+	}
+	instance.option.Labels = copyMap(option.Labels)
+	instance.option.Metadata = copyMap(metadata)
+	f.instances[fullname] = instance
+	return f.startOperation("CreateNotebookInstance", option, func() {
+		instance.state = notebookspb.Instance_ACTIVE
+		instance.proxyURI = fakeProxyURI(fullname, option.Location)
+		// ブートストラップのスクリプトは実行せず、完了したことだけを報告する
+		if instance.option.Bootstrap != nil {
+			instance.guestAttributes = map[string]map[string]string{
+				GuestAttributeNamespace: {BootstrapStatusKey: BootstrapStatusDone},
+			}
+		}
+	}), nil
+}
+
+func (f *FakeClient) DescribeNotebookInstance(ctx context.Context, option *Option) (*Status, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("DescribeNotebookInstance"); err != nil {
+		return nil, err
+	}
+
+	fullname := notebookInstanceFullname(option.ProjectId, option.Zone, option.Name)
+	instance, ok := f.instances[fullname]
+	if !ok {
+		return nil, classifyError(status.Errorf(codes.NotFound, "notebook instance %q not found", fullname))
+	}
+	return &Status{
+		Name:             fullname,
+		URL:              instance.proxyURI,
+		Status:           instanceStateFromProto(instance.state),
+		MachineType:      instance.option.MachineType,
+		AcceleratorType:  strings.ToUpper(instance.option.AcceleratorType),
+		AcceleratorCount: instance.option.AcceleratorCount,
+		Labels:           copyMap(instance.option.Labels),
+		Metadata:         copyMap(instance.option.Metadata),
+		UpgradeHistory:   append([]UpgradeHistoryEntry(nil), instance.upgradeHistory...),
+	}, nil
+}
+
+func (f *FakeClient) StartNotebookInstance(ctx context.Context, option *Option) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("StartNotebookInstance"); err != nil {
+		return "", err
+	}
+
+	instance, err := f.instance(option)
+	if err != nil {
+		return "", err
+	}
+	switch instance.state {
+	case notebookspb.Instance_ACTIVE:
+		return f.startOperation("StartNotebookInstance", option, func() {}), nil
+	case notebookspb.Instance_STOPPED:
+		instance.state = notebookspb.Instance_STARTING
+		fullname := notebookInstanceFullname(option.ProjectId, option.Zone, option.Name)
+		return f.startRevertibleOperation("StartNotebookInstance", option, func() {
+			instance.state = notebookspb.Instance_ACTIVE
+			instance.proxyURI = fakeProxyURI(fullname, instance.option.Location)
+		}, func() {
+			instance.state = notebookspb.Instance_STOPPED
+		}), nil
+	default:
+		return "", classifyError(status.Errorf(codes.FailedPrecondition, "notebook instance cannot be started in %s state", instance.state))
+	}
+}
+
+func (f *FakeClient) StopNotebookInstance(ctx context.Context, option *Option) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("StopNotebookInstance"); err != nil {
+		return "", err
+	}
+
+	instance, err := f.instance(option)
+	if err != nil {
+		return "", err
+	}
+	switch instance.state {
+	case notebookspb.Instance_STOPPED:
+		return f.startOperation("StopNotebookInstance", option, func() {}), nil
+	case notebookspb.Instance_ACTIVE:
+		instance.state = notebookspb.Instance_STOPPING
+		return f.startRevertibleOperation("StopNotebookInstance", option, func() {
+			instance.state = notebookspb.Instance_STOPPED
+			instance.proxyURI = ""
+		}, func() {
+			instance.state = notebookspb.Instance_ACTIVE
+		}), nil
+	default:
+		return "", classifyError(status.Errorf(codes.FailedPrecondition, "notebook instance cannot be stopped in %s state", instance.state))
+	}
+}
+
+func (f *FakeClient) DeleteNotebookInstance(ctx context.Context, option *Option) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("DeleteNotebookInstance"); err != nil {
+		return "", err
+	}
+
+	instance, err := f.instance(option)
+	if err != nil {
+		return "", err
+	}
+	switch instance.state {
+	case notebookspb.Instance_ACTIVE, notebookspb.Instance_STOPPED, notebookspb.Instance_SUSPENDED:
+	default:
+		return "", classifyError(status.Errorf(codes.FailedPrecondition, "notebook instance cannot be deleted in %s state", instance.state))
+	}
+
+	fullname := notebookInstanceFullname(option.ProjectId, option.Zone, option.Name)
+	instance.state = notebookspb.Instance_DELETED
+	instance.proxyURI = ""
+	return f.startOperation("DeleteNotebookInstance", option, func() {
+		delete(f.instances, fullname)
+	}), nil
+}
+
+func (f *FakeClient) SetNotebookInstanceLabels(ctx context.Context, option *Option) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("SetNotebookInstanceLabels"); err != nil {
+		return "", err
+	}
+
+	instance, err := f.instance(option)
+	if err != nil {
+		return "", err
+	}
+	labels := copyMap(option.Labels)
+	return f.startOperation("SetNotebookInstanceLabels", option, func() {
+		instance.option.Labels = labels
+	}), nil
+}
+
+func (f *FakeClient) SetNotebookInstanceMachineType(ctx context.Context, option *Option) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("SetNotebookInstanceMachineType"); err != nil {
+		return "", err
+	}
+
+	instance, err := f.instance(option)
+	if err != nil {
+		return "", err
+	}
+	if instance.state != notebookspb.Instance_STOPPED {
+		return "", classifyError(status.Errorf(codes.FailedPrecondition, "machine type of notebook instance cannot be changed in %s state", instance.state))
+	}
+	machineType := option.MachineType
+	return f.startOperation("SetNotebookInstanceMachineType", option, func() {
+		instance.option.MachineType = machineType
+	}), nil
+}
+
+func (f *FakeClient) SetNotebookInstanceAccelerator(ctx context.Context, option *Option) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("SetNotebookInstanceAccelerator"); err != nil {
+		return "", err
+	}
+
+	if _, err := parseAcceleratorType(option.AcceleratorType); err != nil {
+		return "", classifyError(status.Error(codes.InvalidArgument, err.Error()))
+	}
+	instance, err := f.instance(option)
+	if err != nil {
+		return "", err
+	}
+	if instance.state != notebookspb.Instance_STOPPED {
+		return "", classifyError(status.Errorf(codes.FailedPrecondition, "accelerator of notebook instance cannot be changed in %s state", instance.state))
+	}
+	acceleratorType, acceleratorCount := option.AcceleratorType, option.AcceleratorCount
+	return f.startOperation("SetNotebookInstanceAccelerator", option, func() {
+		instance.option.AcceleratorType = acceleratorType
+		instance.option.AcceleratorCount = acceleratorCount
+	}), nil
+}
+
+func (f *FakeClient) SetNotebookInstanceIdleTimeout(ctx context.Context, option *Option) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("SetNotebookInstanceIdleTimeout"); err != nil {
+		return err
+	}
+
+	instance, err := f.instance(option)
+	if err != nil {
+		return err
+	}
+	if instance.option.Metadata == nil {
+		instance.option.Metadata = map[string]string{}
+	}
+	for k, v := range option.IdleTimeoutMetadata() {
+		instance.option.Metadata[k] = v
+	}
+	return nil
+}
+
+func (f *FakeClient) IsNotebookInstanceUpgradeable(ctx context.Context, option *Option) (*Upgradeability, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("IsNotebookInstanceUpgradeable"); err != nil {
+		return nil, err
+	}
+
+	instance, err := f.instance(option)
+	if err != nil {
+		return nil, err
+	}
+	if f.upgradeVersion == "" || instance.version == f.upgradeVersion {
+		return &Upgradeability{Info: fmt.Sprintf("notebook instance is already on the latest version %s", instance.version)}, nil
+	}
+	return &Upgradeability{
+		Upgradeable: true,
+		Version:     f.upgradeVersion,
+		Info:        fmt.Sprintf("notebook instance can be upgraded from %s to %s", instance.version, f.upgradeVersion),
+		Image:       fmt.Sprintf("projects/%s/global/images/%s-%s", DefaultImageProject, DefaultImageFamily, f.upgradeVersion),
+	}, nil
+}
+
+func (f *FakeClient) UpgradeNotebookInstance(ctx context.Context, option *Option) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("UpgradeNotebookInstance"); err != nil {
+		return "", err
+	}
+
+	instance, err := f.instance(option)
+	if err != nil {
+		return "", err
+	}
+	if instance.state != notebookspb.Instance_ACTIVE {
+		return "", classifyError(status.Errorf(codes.FailedPrecondition, "notebook instance cannot be upgraded in %s state", instance.state))
+	}
+	if f.upgradeVersion == "" || instance.version == f.upgradeVersion {
+		return "", classifyError(status.Errorf(codes.FailedPrecondition, "notebook instance is already on the latest version %s", instance.version))
+	}
+
+	// アップグレード前にブートディスクのスナップショットを取得したものとして履歴に記録する
+	i := len(instance.upgradeHistory)
+	instance.upgradeHistory = append(instance.upgradeHistory, UpgradeHistoryEntry{
+		Snapshot:      fmt.Sprintf("%s-snapshot-%d", option.Name, i+1),
+		Version:       instance.version,
+		TargetVersion: f.upgradeVersion,
+		Action:        UpgradeActionUpgrade,
+		State:         UpgradeStateStarted,
+		CreateTime:    f.now(),
+	})
+	instance.state = notebookspb.Instance_UPGRADING
+	return f.startRevertibleOperation("UpgradeNotebookInstance", option, func() {
+		instance.state = notebookspb.Instance_ACTIVE
+		instance.version = instance.upgradeHistory[i].TargetVersion
+		instance.upgradeHistory[i].State = UpgradeStateSucceeded
+	}, func() {
+		instance.state = notebookspb.Instance_ACTIVE
+		instance.upgradeHistory[i].State = UpgradeStateFailed
+	}), nil
+}
+
+func (f *FakeClient) RollbackNotebookInstance(ctx context.Context, option *Option) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("RollbackNotebookInstance"); err != nil {
+		return "", err
+	}
+
+	instance, err := f.instance(option)
+	if err != nil {
+		return "", err
+	}
+	if instance.state != notebookspb.Instance_ACTIVE && instance.state != notebookspb.Instance_STOPPED {
+		return "", classifyError(status.Errorf(codes.FailedPrecondition, "notebook instance cannot be rolled back in %s state", instance.state))
+	}
+	var target *UpgradeHistoryEntry
+	for j := range instance.upgradeHistory {
+		if e := &instance.upgradeHistory[j]; e.Action == UpgradeActionUpgrade && e.Snapshot == option.Snapshot {
+			target = e
+		}
+	}
+	if option.Snapshot == "" || target == nil {
+		return "", classifyError(status.Errorf(codes.InvalidArgument, "snapshot %q not found in upgrade history", option.Snapshot))
+	}
+
+	i := len(instance.upgradeHistory)
+	instance.upgradeHistory = append(instance.upgradeHistory, UpgradeHistoryEntry{
+		Snapshot:      option.Snapshot,
+		Version:       instance.version,
+		TargetVersion: target.Version,
+		Action:        UpgradeActionRollback,
+		State:         UpgradeStateStarted,
+		CreateTime:    f.now(),
+	})
+	previous := instance.state
+	instance.state = notebookspb.Instance_UPGRADING
+	fullname := notebookInstanceFullname(option.ProjectId, option.Zone, option.Name)
+	return f.startRevertibleOperation("RollbackNotebookInstance", option, func() {
+		instance.state = notebookspb.Instance_ACTIVE
+		instance.proxyURI = fakeProxyURI(fullname, instance.option.Location)
+		instance.version = instance.upgradeHistory[i].TargetVersion
+		instance.upgradeHistory[i].State = UpgradeStateSucceeded
+	}, func() {
+		instance.state = previous
+		instance.upgradeHistory[i].State = UpgradeStateFailed
+	}), nil
+}
+
+func (f *FakeClient) ResetNotebookInstance(ctx context.Context, option *Option) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("ResetNotebookInstance"); err != nil {
+		return "", err
+	}
+
+	instance, err := f.instance(option)
+	if err != nil {
+		return "", err
+	}
+	if instance.state != notebookspb.Instance_ACTIVE {
+		return "", classifyError(status.Errorf(codes.FailedPrecondition, "notebook instance cannot be reset in %s state", instance.state))
+	}
+	// リセットは OS を再起動するので、完了するまでは起動中として扱う
+	instance.state = notebookspb.Instance_STARTING
+	return f.startRevertibleOperation("ResetNotebookInstance", option, func() {
+		instance.state = notebookspb.Instance_ACTIVE
+	}, func() {
+		instance.state = notebookspb.Instance_ACTIVE
+	}), nil
+}
+
+func (f *FakeClient) DiagnoseNotebookInstance(ctx context.Context, option *Option) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("DiagnoseNotebookInstance"); err != nil {
+		return "", err
+	}
+
+	if option.Diagnostic == nil || option.Diagnostic.GcsBucket == "" {
+		return "", classifyError(status.Error(codes.InvalidArgument, "Cloud Storage bucket is required to diagnose notebook instance"))
+	}
+	instance, err := f.instance(option)
+	if err != nil {
+		return "", err
+	}
+	if instance.state != notebookspb.Instance_ACTIVE {
+		return "", classifyError(status.Errorf(codes.FailedPrecondition, "notebook instance cannot be diagnosed in %s state", instance.state))
+	}
+	return f.startOperation("DiagnoseNotebookInstance", option, func() {}), nil
+}
+
+func (f *FakeClient) HasOperationDone(ctx context.Context, opName string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("HasOperationDone"); err != nil {
+		return false, classifyError(fmt.Errorf("failed to get notebook operation %q: %w", opName, err))
+	}
+
+	op, ok := f.operations[opName]
+	if !ok {
+		return false, classifyError(fmt.Errorf("failed to get notebook operation %q: %w", opName,
+			status.Errorf(codes.NotFound, "operation %q not found", opName)))
+	}
+	if !op.done {
+		return false, nil
+	}
+	if op.err != nil {
+		return false, classifyError(fmt.Errorf("notebook operation %q aborted: %w", opName, op.err))
+	}
+	return true, nil
+}
+
+func (f *FakeClient) ListAcceleratorTypes(ctx context.Context, option *Option) ([]AcceleratorType, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.injectedError("ListAcceleratorTypes"); err != nil {
+		return nil, err
+	}
+
+	types, ok := f.acceleratorTypes[option.Zone]
+	if !ok {
+		types = DefaultFakeAcceleratorTypes
+	}
+	return append([]AcceleratorType(nil), types...), nil
+}
+
+func (f *FakeClient) GetGuestAttributes(ctx context.Context, option *Option, namespace string) (map[string]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("GetGuestAttributes"); err != nil {
+		return nil, err
+	}
+
+	instance, err := f.instance(option)
+	if err != nil {
+		return nil, err
+	}
+	attrs, ok := instance.guestAttributes[namespace]
+	if !ok {
+		return nil, classifyError(status.Errorf(codes.NotFound, "guest attributes in namespace %q not found", namespace))
+	}
+	return copyMap(attrs), nil
+}
+
+// reconcile finishes all operations and notebook executions whose deadline has passed. The caller must hold f.mu.
+func (f *FakeClient) reconcile() {
+	now := f.now()
+	for _, op := range f.operations {
+		if op.done || now.Before(op.doneAt) {
+			continue
+		}
+		op.done = true
+		if op.err == nil {
+			op.apply()
+		} else if op.revert != nil {
+			op.revert()
+		}
+	}
+	for _, e := range f.executions {
+		if e.state != notebookspb.Execution_RUNNING || now.Before(e.finishAt) {
+			continue
+		}
+		e.finish()
+	}
+}
+
+// startOperation registers new long-running operation finishing after the configured delay. The caller must hold f.mu.
+func (f *FakeClient) startOperation(method string, option *Option, apply func()) string {
+	return f.startRevertibleOperation(method, option, apply, nil)
+}
+
+// startRevertibleOperation is the same as startOperation, but calls revert if the operation aborts, e.g. to put the
+// instance in STARTING state back to STOPPED state like Notebooks API does. The caller must hold f.mu.
+func (f *FakeClient) startRevertibleOperation(method string, option *Option, apply, revert func()) string {
+	return f.newOperation(method, fmt.Sprintf("projects/%s/locations/%s", option.ProjectId, option.Zone), apply, revert)
+}
+
+// newOperation registers new long-running operation under the parent, e.g. "projects/{project}/locations/{location}".
+// The caller must hold f.mu.
+func (f *FakeClient) newOperation(method, parent string, apply, revert func()) string {
+	f.seq++
+	opName := fmt.Sprintf("%s/operations/operation-%d", parent, f.seq)
+	f.operations[opName] = &fakeOperation{
+		doneAt: f.now().Add(f.delay),
+		err:    f.injectedOperationError(method),
+		apply:  apply,
+		revert: revert,
+	}
+	return opName
+}
+
+// instance returns the instance specified by the option. The caller must hold f.mu.
+func (f *FakeClient) instance(option *Option) (*fakeInstance, error) {
+	fullname := notebookInstanceFullname(option.ProjectId, option.Zone, option.Name)
+	instance, ok := f.instances[fullname]
+	if !ok || instance.state == notebookspb.Instance_DELETED {
+		return nil, classifyError(status.Errorf(codes.NotFound, "notebook instance %q not found", fullname))
+	}
+	return instance, nil
+}
+
+// injectedOperationError returns the error injected into the operations of the method if any. The caller must hold f.mu.
+func (f *FakeClient) injectedOperationError(method string) error {
+	e, ok := f.opErrors[method]
+	if !ok {
+		return nil
+	}
+	if e.consume() {
+		delete(f.opErrors, method)
+	}
+	return e.err
+}
+
+// injectedError returns the error injected into the method if any. The caller must hold f.mu.
+func (f *FakeClient) injectedError(method string) error {
+	e, ok := f.errors[method]
+	if !ok {
+		return nil
+	}
+	if e.consume() {
+		delete(f.errors, method)
+	}
+	return classifyError(e.err)
+}
+
+func copyMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+func newFakeError(err error, times int) *fakeError {
+	if times <= 0 {
+		times = -1
+	}
+	return &fakeError{err: err, times: times}
+}
+
+// consume decrements the remaining number of failures and reports whether the error has expired.
+func (e *fakeError) consume() bool {
+	if e.times < 0 {
+		return false
+	}
+	e.times--
+	return e.times == 0
+}
+
+func fakeProxyURI(fullname, location string) string {
+	sum := sha256.Sum256([]byte(fullname))
+	return fmt.Sprintf("%x-dot-%s.notebooks.googleusercontent.com", sum[:8], location)
+}
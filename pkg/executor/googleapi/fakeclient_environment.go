@@ -0,0 +1,94 @@
+package googleapi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (f *FakeClient) CreateEnvironment(ctx context.Context, option *EnvironmentOption) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("CreateEnvironment"); err != nil {
+		return "", err
+	}
+
+	fullname := environmentFullname(option.ProjectId, option.Zone, option.Name)
+	if _, ok := f.environments[fullname]; ok {
+		return "", classifyError(status.Errorf(codes.AlreadyExists, "environment %q already exists", fullname))
+	}
+
+	// 作成が完了するまでは Get で見えないように、完了時に登録する
+	env := environmentStatusFromProto(option.proto())
+	env.Name = fullname
+	return f.newOperation("CreateEnvironment", fmt.Sprintf("projects/%s/locations/%s", option.ProjectId, option.Zone), func() {
+		f.environments[fullname] = &env
+	}, nil), nil
+}
+
+func (f *FakeClient) DescribeEnvironment(ctx context.Context, option *EnvironmentOption) (*EnvironmentStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("DescribeEnvironment"); err != nil {
+		return nil, err
+	}
+
+	fullname := environmentFullname(option.ProjectId, option.Zone, option.Name)
+	env, ok := f.environments[fullname]
+	if !ok {
+		return nil, classifyError(status.Errorf(codes.NotFound, "environment %q not found", fullname))
+	}
+	status := *env
+	return &status, nil
+}
+
+func (f *FakeClient) ListEnvironments(ctx context.Context, option *EnvironmentOption) ([]EnvironmentStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("ListEnvironments"); err != nil {
+		return nil, err
+	}
+
+	parent := fmt.Sprintf("projects/%s/locations/%s/environments/", option.ProjectId, option.Zone)
+	var environments []EnvironmentStatus
+	for fullname, env := range f.environments {
+		if !strings.HasPrefix(fullname, parent) {
+			continue
+		}
+		environments = append(environments, *env)
+	}
+	// map の順序は不定なので、API と同じく名前の順に並べる
+	sort.Slice(environments, func(i, j int) bool {
+		return environments[i].Name < environments[j].Name
+	})
+	return environments, nil
+}
+
+func (f *FakeClient) DeleteEnvironment(ctx context.Context, option *EnvironmentOption) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("DeleteEnvironment"); err != nil {
+		return "", err
+	}
+
+	fullname := environmentFullname(option.ProjectId, option.Zone, option.Name)
+	if _, ok := f.environments[fullname]; !ok {
+		return "", classifyError(status.Errorf(codes.NotFound, "environment %q not found", fullname))
+	}
+	// 環境から作成済みのインスタンスには影響しない
+	return f.newOperation("DeleteEnvironment", fmt.Sprintf("projects/%s/locations/%s", option.ProjectId, option.Zone), func() {
+		delete(f.environments, fullname)
+	}, nil), nil
+}
@@ -0,0 +1,101 @@
+package googleapi
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/notebooks/apiv1/notebookspb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeExecution struct {
+	state  notebookspb.Execution_State
+	option ExecutionOption
+	jobURI string
+	// finishAt is when the running execution finishes, with err if the execution is made to fail
+	finishAt time.Time
+	err      error
+	output   string
+	// run moves the queued execution to RUNNING state, called when the operation creating the execution finishes
+	run func()
+}
+
+// finish moves the running execution to the terminal state. The caller must hold f.mu.
+func (e *fakeExecution) finish() {
+	if e.err != nil {
+		e.state = notebookspb.Execution_FAILED
+		return
+	}
+	e.state = notebookspb.Execution_SUCCEEDED
+	// papermill で実行したノートブックは出力先のフォルダに実行 ID のディレクトリを作成して書き込まれる
+	e.output = fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(e.option.OutputNotebookFolder, "/"), e.option.Name, path.Base(e.option.InputNotebookFile))
+}
+
+// queueExecution registers new execution in QUEUED state. The caller must hold f.mu.
+func (f *FakeClient) queueExecution(option *ExecutionOption) *fakeExecution {
+	e := &fakeExecution{
+		state:  notebookspb.Execution_QUEUED,
+		option: *option,
+		jobURI: fmt.Sprintf("https://console.cloud.google.com/vertex-ai/locations/%s/training/%d?project=%s", option.Location, f.seq+1, option.ProjectId),
+		err:    f.injectedOperationError("RunExecution"),
+	}
+	e.option.Parameters = copyMap(option.Parameters)
+	e.option.Labels = copyMap(option.Labels)
+	e.run = func() {
+		e.state = notebookspb.Execution_RUNNING
+		e.finishAt = f.now().Add(f.delay)
+	}
+	f.executions[executionFullname(option.ProjectId, option.Location, option.Name)] = e
+	return e
+}
+
+func (f *FakeClient) CreateExecution(ctx context.Context, option *ExecutionOption) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("CreateExecution"); err != nil {
+		return "", err
+	}
+
+	fullname := executionFullname(option.ProjectId, option.Location, option.Name)
+	if _, ok := f.executions[fullname]; ok {
+		return "", classifyError(status.Errorf(codes.AlreadyExists, "execution %q already exists", fullname))
+	}
+
+	e := f.queueExecution(option)
+	return f.newOperation("CreateExecution", fmt.Sprintf("projects/%s/locations/%s", option.ProjectId, option.Location), e.run, func() {
+		delete(f.executions, fullname)
+	}), nil
+}
+
+func (f *FakeClient) DescribeExecution(ctx context.Context, option *ExecutionOption) (*ExecutionStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("DescribeExecution"); err != nil {
+		return nil, err
+	}
+
+	fullname := executionFullname(option.ProjectId, option.Location, option.Name)
+	e, ok := f.executions[fullname]
+	if !ok {
+		return nil, classifyError(status.Errorf(codes.NotFound, "execution %q not found", fullname))
+	}
+	status := e.status(fullname)
+	return &status, nil
+}
+
+func (e *fakeExecution) status(fullname string) ExecutionStatus {
+	return ExecutionStatus{
+		Name:               fullname,
+		State:              executionStateFromProto(e.state),
+		OutputNotebookFile: e.output,
+		JobURI:             e.jobURI,
+	}
+}
@@ -0,0 +1,202 @@
+package googleapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/notebooks/apiv1/notebookspb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeRuntime struct {
+	state    notebookspb.Runtime_State
+	proxyURI string
+	option   RuntimeOption
+}
+
+func (f *FakeClient) CreateRuntime(ctx context.Context, option *RuntimeOption) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("CreateRuntime"); err != nil {
+		return "", err
+	}
+
+	fullname := runtimeFullname(option.ProjectId, option.Location, option.Name)
+	if _, ok := f.runtimes[fullname]; ok {
+		return "", classifyError(status.Errorf(codes.AlreadyExists, "runtime %q already exists", fullname))
+	}
+
+	rt := &fakeRuntime{
+		state:  notebookspb.Runtime_PROVISIONING,
+		option: *option,
+	}
+	rt.option.MachineType = option.machineType()
+	rt.option.Labels = copyMap(option.Labels)
+	f.runtimes[fullname] = rt
+	return f.startRuntimeOperation("CreateRuntime", option, func() {
+		rt.state = notebookspb.Runtime_ACTIVE
+		rt.proxyURI = fakeProxyURI(fullname, option.Location)
+	}, func() {
+		delete(f.runtimes, fullname)
+	}), nil
+}
+
+func (f *FakeClient) DescribeRuntime(ctx context.Context, option *RuntimeOption) (*RuntimeStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("DescribeRuntime"); err != nil {
+		return nil, err
+	}
+
+	rt, err := f.runtime(option)
+	if err != nil {
+		return nil, err
+	}
+	return &RuntimeStatus{
+		Name:             runtimeFullname(option.ProjectId, option.Location, option.Name),
+		URL:              rt.proxyURI,
+		Status:           runtimeStateFromProto(rt.state),
+		MachineType:      rt.option.MachineType,
+		AcceleratorType:  strings.ToUpper(rt.option.AcceleratorType),
+		AcceleratorCount: rt.option.AcceleratorCount,
+		Labels:           copyMap(rt.option.Labels),
+	}, nil
+}
+
+func (f *FakeClient) StartRuntime(ctx context.Context, option *RuntimeOption) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("StartRuntime"); err != nil {
+		return "", err
+	}
+
+	rt, err := f.runtime(option)
+	if err != nil {
+		return "", err
+	}
+	switch rt.state {
+	case notebookspb.Runtime_ACTIVE:
+		return f.startRuntimeOperation("StartRuntime", option, func() {}, nil), nil
+	case notebookspb.Runtime_STOPPED:
+		rt.state = notebookspb.Runtime_STARTING
+		fullname := runtimeFullname(option.ProjectId, option.Location, option.Name)
+		return f.startRuntimeOperation("StartRuntime", option, func() {
+			rt.state = notebookspb.Runtime_ACTIVE
+			rt.proxyURI = fakeProxyURI(fullname, option.Location)
+		}, func() {
+			rt.state = notebookspb.Runtime_STOPPED
+		}), nil
+	default:
+		return "", classifyError(status.Errorf(codes.FailedPrecondition, "runtime cannot be started in %s state", rt.state))
+	}
+}
+
+func (f *FakeClient) StopRuntime(ctx context.Context, option *RuntimeOption) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("StopRuntime"); err != nil {
+		return "", err
+	}
+
+	rt, err := f.runtime(option)
+	if err != nil {
+		return "", err
+	}
+	switch rt.state {
+	case notebookspb.Runtime_STOPPED:
+		return f.startRuntimeOperation("StopRuntime", option, func() {}, nil), nil
+	case notebookspb.Runtime_ACTIVE:
+		rt.state = notebookspb.Runtime_STOPPING
+		return f.startRuntimeOperation("StopRuntime", option, func() {
+			rt.state = notebookspb.Runtime_STOPPED
+			rt.proxyURI = ""
+		}, func() {
+			rt.state = notebookspb.Runtime_ACTIVE
+		}), nil
+	default:
+		return "", classifyError(status.Errorf(codes.FailedPrecondition, "runtime cannot be stopped in %s state", rt.state))
+	}
+}
+
+func (f *FakeClient) DeleteRuntime(ctx context.Context, option *RuntimeOption) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("DeleteRuntime"); err != nil {
+		return "", err
+	}
+
+	rt, err := f.runtime(option)
+	if err != nil {
+		return "", err
+	}
+	if rt.state != notebookspb.Runtime_ACTIVE && rt.state != notebookspb.Runtime_STOPPED {
+		return "", classifyError(status.Errorf(codes.FailedPrecondition, "runtime cannot be deleted in %s state", rt.state))
+	}
+
+	fullname := runtimeFullname(option.ProjectId, option.Location, option.Name)
+	previous := rt.state
+	rt.state = notebookspb.Runtime_DELETING
+	rt.proxyURI = ""
+	return f.startRuntimeOperation("DeleteRuntime", option, func() {
+		delete(f.runtimes, fullname)
+	}, func() {
+		rt.state = previous
+		if previous == notebookspb.Runtime_ACTIVE {
+			rt.proxyURI = fakeProxyURI(fullname, option.Location)
+		}
+	}), nil
+}
+
+func (f *FakeClient) SwitchRuntime(ctx context.Context, option *RuntimeOption) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("SwitchRuntime"); err != nil {
+		return "", err
+	}
+
+	if err := checkRuntimeAccelerator(option.MachineType, option.AcceleratorType, option.AcceleratorCount, false); err != nil {
+		return "", classifyError(status.Error(codes.InvalidArgument, err.Error()))
+	}
+	rt, err := f.runtime(option)
+	if err != nil {
+		return "", err
+	}
+	if rt.state != notebookspb.Runtime_STOPPED {
+		return "", classifyError(status.Errorf(codes.FailedPrecondition, "runtime cannot be switched in %s state", rt.state))
+	}
+	machineType, acceleratorType, acceleratorCount := option.MachineType, option.AcceleratorType, option.AcceleratorCount
+	return f.startRuntimeOperation("SwitchRuntime", option, func() {
+		rt.option.MachineType = machineType
+		rt.option.AcceleratorType = acceleratorType
+		rt.option.AcceleratorCount = acceleratorCount
+	}, nil), nil
+}
+
+// startRuntimeOperation registers new long-running operation of the runtime. The caller must hold f.mu.
+func (f *FakeClient) startRuntimeOperation(method string, option *RuntimeOption, apply, revert func()) string {
+	return f.newOperation(method, fmt.Sprintf("projects/%s/locations/%s", option.ProjectId, option.Location), apply, revert)
+}
+
+// runtime returns the runtime specified by the option. The caller must hold f.mu.
+func (f *FakeClient) runtime(option *RuntimeOption) (*fakeRuntime, error) {
+	fullname := runtimeFullname(option.ProjectId, option.Location, option.Name)
+	rt, ok := f.runtimes[fullname]
+	if !ok {
+		return nil, classifyError(status.Errorf(codes.NotFound, "runtime %q not found", fullname))
+	}
+	return rt, nil
+}
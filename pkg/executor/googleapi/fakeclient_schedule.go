@@ -0,0 +1,163 @@
+package googleapi
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"cloud.google.com/go/notebooks/apiv1/notebookspb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeSchedule struct {
+	state  notebookspb.Schedule_State
+	option ScheduleOption
+	// executions is the full names of the executions created by the schedule, the oldest first
+	executions []string
+}
+
+// fakeRecentExecutions is the number of executions reported in ScheduleStatus.RecentExecutions like Notebooks API
+const fakeRecentExecutions = 5
+
+func (f *FakeClient) CreateSchedule(ctx context.Context, option *ScheduleOption) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("CreateSchedule"); err != nil {
+		return "", err
+	}
+
+	fullname := scheduleFullname(option.ProjectId, option.Location, option.Name)
+	if _, ok := f.schedules[fullname]; ok {
+		return "", classifyError(status.Errorf(codes.AlreadyExists, "schedule %q already exists", fullname))
+	}
+
+	s := &fakeSchedule{
+		state:  notebookspb.Schedule_INITIALIZING,
+		option: *option,
+	}
+	s.option.Template = option.template()
+	s.option.Template.Parameters = copyMap(option.Template.Parameters)
+	s.option.Template.Labels = copyMap(option.Template.Labels)
+	s.option.TimeZone = option.timeZone()
+	f.schedules[fullname] = s
+	return f.newOperation("CreateSchedule", fmt.Sprintf("projects/%s/locations/%s", option.ProjectId, option.Location), func() {
+		s.state = notebookspb.Schedule_ENABLED
+	}, func() {
+		delete(f.schedules, fullname)
+	}), nil
+}
+
+func (f *FakeClient) DescribeSchedule(ctx context.Context, option *ScheduleOption) (*ScheduleStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("DescribeSchedule"); err != nil {
+		return nil, err
+	}
+
+	fullname := scheduleFullname(option.ProjectId, option.Location, option.Name)
+	s, ok := f.schedules[fullname]
+	if !ok {
+		return nil, classifyError(status.Errorf(codes.NotFound, "schedule %q not found", fullname))
+	}
+	status := f.scheduleStatus(fullname, s)
+	return &status, nil
+}
+
+func (f *FakeClient) ListSchedules(ctx context.Context, option *ScheduleOption) ([]ScheduleStatus, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("ListSchedules"); err != nil {
+		return nil, err
+	}
+
+	var schedules []ScheduleStatus
+	for fullname, s := range f.schedules {
+		if s.option.ProjectId != option.ProjectId || s.option.Location != option.Location {
+			continue
+		}
+		schedules = append(schedules, f.scheduleStatus(fullname, s))
+	}
+	// map の順序は不定なので、API と同じく名前の順に並べる
+	sort.Slice(schedules, func(i, j int) bool {
+		return schedules[i].Name < schedules[j].Name
+	})
+	return schedules, nil
+}
+
+func (f *FakeClient) DeleteSchedule(ctx context.Context, option *ScheduleOption) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("DeleteSchedule"); err != nil {
+		return "", err
+	}
+
+	fullname := scheduleFullname(option.ProjectId, option.Location, option.Name)
+	s, ok := f.schedules[fullname]
+	if !ok || s.state == notebookspb.Schedule_DELETING {
+		return "", classifyError(status.Errorf(codes.NotFound, "schedule %q not found", fullname))
+	}
+	prev := s.state
+	s.state = notebookspb.Schedule_DELETING
+	// スケジュールから作成された実行は削除されずに残る
+	return f.newOperation("DeleteSchedule", fmt.Sprintf("projects/%s/locations/%s", option.ProjectId, option.Location), func() {
+		delete(f.schedules, fullname)
+	}, func() {
+		s.state = prev
+	}), nil
+}
+
+func (f *FakeClient) TriggerSchedule(ctx context.Context, option *ScheduleOption) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("TriggerSchedule"); err != nil {
+		return "", err
+	}
+
+	fullname := scheduleFullname(option.ProjectId, option.Location, option.Name)
+	s, ok := f.schedules[fullname]
+	if !ok || s.state == notebookspb.Schedule_DELETING {
+		return "", classifyError(status.Errorf(codes.NotFound, "schedule %q not found", fullname))
+	}
+	if s.state != notebookspb.Schedule_ENABLED {
+		return "", classifyError(status.Errorf(codes.FailedPrecondition, "schedule %q is not enabled", fullname))
+	}
+
+	template := *s.option.Template
+	// 実行 ID はスケジュール名と連番から生成する
+	template.Name = fmt.Sprintf("%s-%d", s.option.Name, f.seq+1)
+	e := f.queueExecution(&template)
+	executionName := executionFullname(template.ProjectId, template.Location, template.Name)
+	s.executions = append(s.executions, executionName)
+	return f.newOperation("TriggerSchedule", fmt.Sprintf("projects/%s/locations/%s", option.ProjectId, option.Location), e.run, func() {
+		delete(f.executions, executionName)
+		s.executions = s.executions[:len(s.executions)-1]
+	}), nil
+}
+
+// scheduleStatus returns the status of the schedule with the recent executions, the newest first. The caller must hold f.mu.
+func (f *FakeClient) scheduleStatus(fullname string, s *fakeSchedule) ScheduleStatus {
+	status := ScheduleStatus{
+		Name:         fullname,
+		State:        scheduleStateFromProto(s.state),
+		CronSchedule: s.option.CronSchedule,
+		TimeZone:     s.option.TimeZone,
+		Description:  s.option.Description,
+	}
+	for i := len(s.executions) - 1; i >= 0 && len(status.RecentExecutions) < fakeRecentExecutions; i-- {
+		if e, ok := f.executions[s.executions[i]]; ok {
+			status.RecentExecutions = append(status.RecentExecutions, e.status(s.executions[i]))
+		}
+	}
+	return status
+}
@@ -0,0 +1,115 @@
+package googleapi
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	notebooks "cloud.google.com/go/notebooks/apiv1"
+	"cloud.google.com/go/notebooks/apiv1/notebookspb"
+)
+
+var (
+	_ RuntimeExecutor = &managedNotebook{}
+)
+
+// managedNotebook is the executor of Google-managed notebook runtimes backed by Managed Notebooks API.
+type managedNotebook struct {
+	client *notebooks.ManagedNotebookClient
+}
+
+// NewManagedNotebook returns RuntimeExecutor backed by Managed Notebooks API.
+// Only the options for Notebooks API client are used.
+func NewManagedNotebook(ctx context.Context, opts ...WorkbenchOption) (RuntimeExecutor, error) {
+	o := &workbenchOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	client, err := notebooks.NewManagedNotebookClient(ctx, o.notebookClientOptions...)
+	if err != nil {
+		return &managedNotebook{}, fmt.Errorf("failed to initialize managed notebook service: %s", err)
+	}
+	return &managedNotebook{client: client}, nil
+}
+
+func (m *managedNotebook) CreateRuntime(ctx context.Context, option *RuntimeOption) (string, error) {
+	op, err := m.client.CreateRuntime(ctx, &notebookspb.CreateRuntimeRequest{
+		Parent:    fmt.Sprintf("projects/%s/locations/%s", option.ProjectId, option.Location),
+		RuntimeId: option.Name,
+		Runtime:   option.proto(),
+	})
+	if err != nil {
+		return "", classifyError(fmt.Errorf("failed to create managed notebook runtime: %w", err))
+	}
+	return op.Name(), nil
+}
+
+func (m *managedNotebook) DescribeRuntime(ctx context.Context, option *RuntimeOption) (*RuntimeStatus, error) {
+	rt, err := m.client.GetRuntime(ctx, &notebookspb.GetRuntimeRequest{
+		Name: runtimeFullname(option.ProjectId, option.Location, option.Name),
+	})
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	vm := rt.GetVirtualMachine().GetVirtualMachineConfig()
+	st := &RuntimeStatus{
+		Name:   rt.Name,
+		URL:    rt.GetAccessConfig().GetProxyUri(),
+		Status: runtimeStateFromProto(rt.State),
+		// マシンタイプは "https://www.googleapis.com/compute/v1/projects/{project}/zones/{zone}/machineTypes/{name}" の形式で返されることがある
+		MachineType: path.Base(vm.GetMachineType()),
+		Labels:      vm.GetLabels(),
+	}
+	if ac := vm.GetAcceleratorConfig(); ac != nil && ac.GetType() != notebookspb.RuntimeAcceleratorConfig_ACCELERATOR_TYPE_UNSPECIFIED {
+		st.AcceleratorType = ac.GetType().String()
+		st.AcceleratorCount = ac.GetCoreCount()
+	}
+	return st, nil
+}
+
+func (m *managedNotebook) StartRuntime(ctx context.Context, option *RuntimeOption) (string, error) {
+	op, err := m.client.StartRuntime(ctx, &notebookspb.StartRuntimeRequest{
+		Name: runtimeFullname(option.ProjectId, option.Location, option.Name),
+	})
+	if err != nil {
+		return "", classifyError(err)
+	}
+	return op.Name(), nil
+}
+
+func (m *managedNotebook) StopRuntime(ctx context.Context, option *RuntimeOption) (string, error) {
+	op, err := m.client.StopRuntime(ctx, &notebookspb.StopRuntimeRequest{
+		Name: runtimeFullname(option.ProjectId, option.Location, option.Name),
+	})
+	if err != nil {
+		return "", classifyError(err)
+	}
+	return op.Name(), nil
+}
+
+func (m *managedNotebook) DeleteRuntime(ctx context.Context, option *RuntimeOption) (string, error) {
+	op, err := m.client.DeleteRuntime(ctx, &notebookspb.DeleteRuntimeRequest{
+		Name: runtimeFullname(option.ProjectId, option.Location, option.Name),
+	})
+	if err != nil {
+		return "", classifyError(err)
+	}
+	return op.Name(), nil
+}
+
+func (m *managedNotebook) SwitchRuntime(ctx context.Context, option *RuntimeOption) (string, error) {
+	op, err := m.client.SwitchRuntime(ctx, &notebookspb.SwitchRuntimeRequest{
+		Name:              runtimeFullname(option.ProjectId, option.Location, option.Name),
+		MachineType:       option.MachineType,
+		AcceleratorConfig: option.acceleratorConfig(),
+	})
+	if err != nil {
+		return "", classifyError(err)
+	}
+	return op.Name(), nil
+}
+
+func (m *managedNotebook) HasOperationDone(ctx context.Context, opName string) (bool, error) {
+	return hasOperationDone(ctx, m.client.GetOperation, opName)
+}
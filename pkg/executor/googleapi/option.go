@@ -0,0 +1,313 @@
+package googleapi
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/notebooks/apiv1/notebookspb"
+)
+
+const (
+	// MaxLabels is the maximum number of labels attached to an instance
+	MaxLabels = 64
+
+	// DefaultImageProject is the project of Deep Learning VM images
+	DefaultImageProject = "deeplearning-platform-release"
+	// DefaultImageFamily is the image family used when neither VM image nor container image is specified
+	DefaultImageFamily = "common-cpu-notebooks"
+	// DefaultDiskType is the type of boot and data disks used when omitted
+	DefaultDiskType = "PD_BALANCED"
+	// DefaultBootDiskSizeGB is the boot disk size used when omitted, which is the minimum size for Deep Learning VM images
+	DefaultBootDiskSizeGB = 50
+	// DefaultDataDiskSizeGB is the data disk size used when omitted
+	DefaultDataDiskSizeGB = 20
+	// MaxDiskSizeGB is the maximum size of boot and data disks
+	MaxDiskSizeGB = 64000
+
+	// IdleTimeoutMetadataKey is the metadata key of the idle time in seconds before the instance is shut down
+	IdleTimeoutMetadataKey = "idle-timeout-seconds"
+	// MinIdleTimeout and MaxIdleTimeout are the range of idle time supported by idle shutdown
+	MinIdleTimeout = 10 * time.Minute
+	MaxIdleTimeout = 24 * time.Hour
+)
+
+var (
+	ErrInvalidOption = errors.New("invalid option")
+
+	// labelKeyRegexp and labelValueRegexp are the requirements of Google Cloud labels.
+	// https://cloud.google.com/compute/docs/labeling-resources#requirements
+	labelKeyRegexp   = regexp.MustCompile(`^[\p{Ll}\p{Lo}][\p{Ll}\p{Lo}\p{N}_-]{0,62}$`)
+	labelValueRegexp = regexp.MustCompile(`^[\p{Ll}\p{Lo}\p{N}_-]{0,63}$`)
+)
+
+// Validate checks the option to create Workbench instance.
+// All problems found are returned at once, wrapped with ErrInvalidOption.
+func (o *Option) Validate() error {
+	var errs []error
+	if o.Name == "" {
+		errs = append(errs, errors.New("name is required"))
+	}
+	if o.ProjectId == "" {
+		errs = append(errs, errors.New("project ID is required"))
+	}
+	if o.Zone == "" {
+		errs = append(errs, errors.New("zone is required"))
+	}
+
+	if o.Environment != "" && (o.ImageProject != "" || o.ImageFamily != "" || o.ImageName != "" || o.ContainerRepository != "" || o.ContainerTag != "") {
+		errs = append(errs, errors.New("environment cannot be specified with VM image or container image"))
+	}
+	if o.ImageFamily != "" && o.ImageName != "" {
+		errs = append(errs, errors.New("image family and image name cannot be specified at the same time"))
+	}
+	if o.ContainerRepository != "" && (o.ImageProject != "" || o.ImageFamily != "" || o.ImageName != "") {
+		errs = append(errs, errors.New("container image and VM image cannot be specified at the same time"))
+	}
+	if o.ContainerRepository == "" && o.ContainerTag != "" {
+		errs = append(errs, errors.New("container tag requires container repository"))
+	}
+
+	for _, disk := range []struct {
+		name     string
+		diskType string
+		sizeGB   int64
+		minGB    int64
+	}{
+		{name: "boot disk", diskType: o.BootDiskType, sizeGB: o.BootDiskSizeGB, minGB: DefaultBootDiskSizeGB},
+		{name: "data disk", diskType: o.DataDiskType, sizeGB: o.DataDiskSizeGB, minGB: 10},
+	} {
+		if disk.diskType != "" {
+			if _, err := parseDiskType(disk.diskType); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", disk.name, err))
+			}
+		}
+		if disk.sizeGB != 0 && (disk.sizeGB < disk.minGB || disk.sizeGB > MaxDiskSizeGB) {
+			errs = append(errs, fmt.Errorf("%s size must be between %d and %d GB: %d", disk.name, disk.minGB, MaxDiskSizeGB, disk.sizeGB))
+		}
+	}
+
+	if err := checkDiskEncryption(o.DiskEncryption, o.KmsKey); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := checkServiceAccount(o.ServiceAccount); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkTags(o.Tags); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkShieldedInstance(o.ShieldedVtpm, o.ShieldedIntegrityMonitoring); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := checkLabels(o.Labels); err != nil {
+		errs = append(errs, err)
+	}
+
+	if o.IdleTimeout != 0 {
+		if err := checkIdleTimeout(o.IdleTimeout); err != nil {
+			errs = append(errs, err)
+		}
+		if _, ok := o.Metadata[IdleTimeoutMetadataKey]; ok {
+			errs = append(errs, fmt.Errorf("metadata %q cannot be specified with idle timeout", IdleTimeoutMetadataKey))
+		}
+	}
+
+	if o.Bootstrap != nil {
+		if err := o.Bootstrap.validate(); err != nil {
+			errs = append(errs, err)
+		}
+		if o.ContainerRepository != "" {
+			errs = append(errs, errors.New("bootstrap cannot be used with container image, install packages in the image instead"))
+		}
+		for _, key := range []string{startupScriptMetadataKey, guestAttributesMetadataKey} {
+			if _, ok := o.Metadata[key]; ok {
+				errs = append(errs, fmt.Errorf("metadata %q cannot be specified with bootstrap", key))
+			}
+		}
+	}
+
+	if err := checkAccelerator(o.MachineType, o.AcceleratorType, o.AcceleratorCount, o.InstallGpuDriver); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrInvalidOption, errors.Join(errs...))
+}
+
+// ValidateLabels checks that the labels follow the requirements of Google Cloud labels.
+func (o *Option) ValidateLabels() error {
+	if err := checkLabels(o.Labels); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidOption, err)
+	}
+	return nil
+}
+
+// ValidateAccelerator checks that the accelerator can be attached to the machine type, which must be the current machine type of the instance.
+func (o *Option) ValidateAccelerator() error {
+	if o.AcceleratorType == "" {
+		return fmt.Errorf("%w: accelerator type is required", ErrInvalidOption)
+	}
+	if err := checkAccelerator(o.MachineType, o.AcceleratorType, o.AcceleratorCount, false); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidOption, err)
+	}
+	return nil
+}
+
+// ValidateIdleTimeout checks that the idle timeout is in the range supported by idle shutdown.
+func (o *Option) ValidateIdleTimeout() error {
+	if err := checkIdleTimeout(o.IdleTimeout); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidOption, err)
+	}
+	return nil
+}
+
+// IdleTimeoutMetadata returns the metadata items to configure idle shutdown, or nil if idle timeout is not specified.
+func (o *Option) IdleTimeoutMetadata() map[string]string {
+	if o.IdleTimeout == 0 {
+		return nil
+	}
+	return map[string]string{
+		IdleTimeoutMetadataKey: strconv.FormatInt(int64(o.IdleTimeout/time.Second), 10),
+	}
+}
+
+// metadata returns the instance metadata, including the items rendered from idle timeout and bootstrap if specified.
+func (o *Option) metadata() (map[string]string, error) {
+	if o.IdleTimeout == 0 && o.Bootstrap == nil {
+		return o.Metadata, nil
+	}
+	metadata := copyMap(o.Metadata)
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	for k, v := range o.IdleTimeoutMetadata() {
+		metadata[k] = v
+	}
+	if o.Bootstrap != nil {
+		script, err := o.Bootstrap.render()
+		if err != nil {
+			return nil, err
+		}
+		metadata[startupScriptMetadataKey] = script
+		metadata[guestAttributesMetadataKey] = "TRUE"
+	}
+	return metadata, nil
+}
+
+// vmImage returns the VM image to create instance from, or nil if container image is specified.
+func (o *Option) vmImage() *notebookspb.VmImage {
+	if o.ContainerRepository != "" {
+		return nil
+	}
+	image := &notebookspb.VmImage{
+		Project: o.ImageProject,
+	}
+	if image.Project == "" {
+		image.Project = DefaultImageProject
+	}
+	switch {
+	case o.ImageName != "":
+		image.Image = &notebookspb.VmImage_ImageName{ImageName: o.ImageName}
+	case o.ImageFamily != "":
+		image.Image = &notebookspb.VmImage_ImageFamily{ImageFamily: o.ImageFamily}
+	default:
+		image.Image = &notebookspb.VmImage_ImageFamily{ImageFamily: DefaultImageFamily}
+	}
+	return image
+}
+
+// containerImage returns the custom container image to create instance from, or nil if it is not specified.
+func (o *Option) containerImage() *notebookspb.ContainerImage {
+	if o.ContainerRepository == "" {
+		return nil
+	}
+	return &notebookspb.ContainerImage{
+		Repository: o.ContainerRepository,
+		Tag:        o.ContainerTag,
+	}
+}
+
+func (o *Option) bootDisk() (notebookspb.Instance_DiskType, int64) {
+	return diskOrDefault(o.BootDiskType, o.BootDiskSizeGB, DefaultBootDiskSizeGB)
+}
+
+func (o *Option) dataDisk() (notebookspb.Instance_DiskType, int64) {
+	return diskOrDefault(o.DataDiskType, o.DataDiskSizeGB, DefaultDataDiskSizeGB)
+}
+
+func (o *Option) diskEncryption() notebookspb.Instance_DiskEncryption {
+	if o.DiskEncryption == "" {
+		return notebookspb.Instance_DISK_ENCRYPTION_UNSPECIFIED
+	}
+	encryption, _ := parseDiskEncryption(o.DiskEncryption)
+	return encryption
+}
+
+func diskOrDefault(diskType string, sizeGB, defaultSizeGB int64) (notebookspb.Instance_DiskType, int64) {
+	if diskType == "" {
+		diskType = DefaultDiskType
+	}
+	t, _ := parseDiskType(diskType)
+	if sizeGB == 0 {
+		sizeGB = defaultSizeGB
+	}
+	return t, sizeGB
+}
+
+func parseDiskType(s string) (notebookspb.Instance_DiskType, error) {
+	v, ok := notebookspb.Instance_DiskType_value[strings.ToUpper(s)]
+	if !ok || v == int32(notebookspb.Instance_DISK_TYPE_UNSPECIFIED) {
+		return notebookspb.Instance_DISK_TYPE_UNSPECIFIED, fmt.Errorf("unknown disk type %q, use one of PD_STANDARD, PD_SSD, PD_BALANCED or PD_EXTREME", s)
+	}
+	return notebookspb.Instance_DiskType(v), nil
+}
+
+func parseDiskEncryption(s string) (notebookspb.Instance_DiskEncryption, error) {
+	v, ok := notebookspb.Instance_DiskEncryption_value[strings.ToUpper(s)]
+	if !ok || v == int32(notebookspb.Instance_DISK_ENCRYPTION_UNSPECIFIED) {
+		return notebookspb.Instance_DISK_ENCRYPTION_UNSPECIFIED, fmt.Errorf("unknown disk encryption %q, use GMEK or CMEK", s)
+	}
+	return notebookspb.Instance_DiskEncryption(v), nil
+}
+
+// acceleratorConfig returns the accelerator to attach to instance, or nil if it is not specified.
+func (o *Option) acceleratorConfig() *notebookspb.Instance_AcceleratorConfig {
+	if o.AcceleratorType == "" {
+		return nil
+	}
+	t, _ := parseAcceleratorType(o.AcceleratorType)
+	return &notebookspb.Instance_AcceleratorConfig{
+		Type:      t,
+		CoreCount: o.AcceleratorCount,
+	}
+}
+
+func checkIdleTimeout(timeout time.Duration) error {
+	if timeout < MinIdleTimeout || timeout > MaxIdleTimeout {
+		return fmt.Errorf("idle timeout must be between %s and %s: %s", MinIdleTimeout, MaxIdleTimeout, timeout)
+	}
+	return nil
+}
+
+func checkLabels(labels map[string]string) error {
+	if len(labels) > MaxLabels {
+		return fmt.Errorf("up to %d labels can be attached, but %d specified", MaxLabels, len(labels))
+	}
+	var errs []error
+	for k, v := range labels {
+		if !labelKeyRegexp.MatchString(k) {
+			errs = append(errs, fmt.Errorf("invalid label key %q, must start with lowercase letter and contain only lowercase letters, numbers, underscores and dashes up to 63 characters", k))
+		}
+		if !labelValueRegexp.MatchString(v) {
+			errs = append(errs, fmt.Errorf("invalid label value %q for key %q, must contain only lowercase letters, numbers, underscores and dashes up to 63 characters", v, k))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,303 @@
+package googleapi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/notebooks/apiv1/notebookspb"
+)
+
+const (
+	// DefaultRuntimeMachineType is the machine type of managed notebook runtimes used when omitted
+	DefaultRuntimeMachineType = "n1-standard-4"
+	// DefaultRuntimeDataDiskSizeGB is the data disk size of managed notebook runtimes used when omitted, which is the minimum size for runtimes
+	DefaultRuntimeDataDiskSizeGB = 100
+)
+
+// RuntimeOption is the option of Google-managed notebook runtime.
+// Unlike user managed notebook instances, runtimes are regional and their VMs are placed in a zone chosen by Google.
+type RuntimeOption struct {
+	// Name indicates the runtime name
+	Name string
+	// Email indicates the runtime owner email, who is the only user allowed to access the runtime
+	Email string
+	// Location indicates the region of the runtime, e.g. "asia-northeast1"
+	Location string
+	// ProjectId indicates the GCP project ID
+	ProjectId string
+	// MachineType indicates the machine type of the runtime VM, DefaultRuntimeMachineType is used if omitted
+	MachineType string
+	// Network indicates the VPC network that the runtime VM is deployed to, Google-managed network is used if omitted
+	Network string
+	// Subnet indicates the subnet that the runtime VM is deployed to
+	Subnet string
+	// DataDiskType indicates the data disk type, e.g. "PD_BALANCED"
+	DataDiskType string
+	// DataDiskSizeGB indicates the data disk size in GB
+	DataDiskSizeGB int64
+	// KmsKey indicates the Cloud KMS key to encrypt disks, Google-managed key is used if omitted.
+	// Use "projects/{project_id}/locations/{location}/keyRings/{key_ring_id}/cryptoKeys/{key_id}" format.
+	KmsKey string
+	// AcceleratorType indicates the accelerator attached to the runtime, e.g. "NVIDIA_TESLA_T4"
+	AcceleratorType string
+	// AcceleratorCount indicates the number of accelerators attached to the runtime
+	AcceleratorCount int64
+	// InstallGpuDriver indicates whether NVIDIA GPU driver is installed automatically
+	InstallGpuDriver bool
+	// NoPublicIP indicates whether the runtime VM is created without public IP address, which requires Network and Subnet
+	NoPublicIP bool
+	// Tags indicates the network tags attached to the runtime VM
+	Tags []string
+	// Labels indicates the labels attached to the runtime VM
+	Labels map[string]string
+	// IdleTimeout indicates the idle time before the runtime is shut down automatically, zero means idle shutdown is not configured
+	IdleTimeout time.Duration
+}
+
+type RuntimeStatus struct {
+	Name   string
+	URL    string
+	Status RuntimeState
+	// MachineType indicates the machine type of the runtime VM, e.g. "n1-standard-4"
+	MachineType string
+	// AcceleratorType indicates the accelerator attached to the runtime, e.g. "NVIDIA_TESLA_T4", empty if none is attached
+	AcceleratorType string
+	// AcceleratorCount indicates the number of accelerators attached to the runtime
+	AcceleratorCount int64
+	Labels           map[string]string
+}
+
+// RuntimeState is the state of managed notebook runtime.
+// The values are the same as notebookspb.Runtime_State names, so that they are compatible with RuntimeStatus recorded as string.
+type RuntimeState string
+
+const (
+	RuntimeStateUnspecified  RuntimeState = "STATE_UNSPECIFIED"
+	RuntimeStateStarting     RuntimeState = "STARTING"
+	RuntimeStateProvisioning RuntimeState = "PROVISIONING"
+	RuntimeStateActive       RuntimeState = "ACTIVE"
+	RuntimeStateStopping     RuntimeState = "STOPPING"
+	RuntimeStateStopped      RuntimeState = "STOPPED"
+	RuntimeStateDeleting     RuntimeState = "DELETING"
+	RuntimeStateUpgrading    RuntimeState = "UPGRADING"
+	RuntimeStateInitializing RuntimeState = "INITIALIZING"
+)
+
+var runtimeStates = map[notebookspb.Runtime_State]RuntimeState{
+	notebookspb.Runtime_STATE_UNSPECIFIED: RuntimeStateUnspecified,
+	notebookspb.Runtime_STARTING:          RuntimeStateStarting,
+	notebookspb.Runtime_PROVISIONING:      RuntimeStateProvisioning,
+	notebookspb.Runtime_ACTIVE:            RuntimeStateActive,
+	notebookspb.Runtime_STOPPING:          RuntimeStateStopping,
+	notebookspb.Runtime_STOPPED:           RuntimeStateStopped,
+	notebookspb.Runtime_DELETING:          RuntimeStateDeleting,
+	notebookspb.Runtime_UPGRADING:         RuntimeStateUpgrading,
+	notebookspb.Runtime_INITIALIZING:      RuntimeStateInitializing,
+}
+
+// runtimeStateFromProto converts the state returned from Managed Notebooks API, unknown states added to the API are treated as unspecified.
+func runtimeStateFromProto(state notebookspb.Runtime_State) RuntimeState {
+	if s, ok := runtimeStates[state]; ok {
+		return s
+	}
+	return RuntimeStateUnspecified
+}
+
+// IsRunning reports whether the runtime is ready to be accessed.
+func (s RuntimeState) IsRunning() bool {
+	return s == RuntimeStateActive
+}
+
+// IsTransitional reports whether the runtime is changing to another state by itself, so that callers should wait for it.
+func (s RuntimeState) IsTransitional() bool {
+	switch s {
+	case RuntimeStateStarting, RuntimeStateProvisioning, RuntimeStateStopping, RuntimeStateUpgrading, RuntimeStateInitializing:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsStopped reports whether the runtime is not running and stays so until it is started.
+func (s RuntimeState) IsStopped() bool {
+	return s == RuntimeStateStopped
+}
+
+// IsTerminal reports whether the runtime can never be running again.
+func (s RuntimeState) IsTerminal() bool {
+	return s == RuntimeStateDeleting
+}
+
+// Validate checks the option to create managed notebook runtime.
+// All problems found are returned at once, wrapped with ErrInvalidOption.
+func (o *RuntimeOption) Validate() error {
+	var errs []error
+	if o.Name == "" {
+		errs = append(errs, errors.New("name is required"))
+	}
+	if o.ProjectId == "" {
+		errs = append(errs, errors.New("project ID is required"))
+	}
+	if o.Location == "" {
+		errs = append(errs, errors.New("location is required"))
+	}
+	if o.Email == "" {
+		errs = append(errs, errors.New("email of the runtime owner is required"))
+	}
+
+	if o.Network == "" && o.Subnet != "" {
+		errs = append(errs, errors.New("subnet requires network"))
+	}
+	if o.NoPublicIP && (o.Network == "" || o.Subnet == "") {
+		errs = append(errs, errors.New("runtime without public IP address requires network and subnet"))
+	}
+
+	if o.DataDiskType != "" {
+		if _, err := parseRuntimeDiskType(o.DataDiskType); err != nil {
+			errs = append(errs, fmt.Errorf("data disk: %w", err))
+		}
+	}
+	if o.DataDiskSizeGB != 0 && (o.DataDiskSizeGB < DefaultRuntimeDataDiskSizeGB || o.DataDiskSizeGB > MaxDiskSizeGB) {
+		errs = append(errs, fmt.Errorf("data disk size must be between %d and %d GB: %d", DefaultRuntimeDataDiskSizeGB, MaxDiskSizeGB, o.DataDiskSizeGB))
+	}
+	if o.KmsKey != "" {
+		if err := checkDiskEncryption(notebookspb.Instance_CMEK.String(), o.KmsKey); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := checkTags(o.Tags); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkLabels(o.Labels); err != nil {
+		errs = append(errs, err)
+	}
+	if o.IdleTimeout != 0 {
+		if err := checkIdleTimeout(o.IdleTimeout); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := checkRuntimeAccelerator(o.machineType(), o.AcceleratorType, o.AcceleratorCount, o.InstallGpuDriver); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrInvalidOption, errors.Join(errs...))
+}
+
+// ValidateSwitch checks the machine type and accelerator that the runtime is switched to.
+func (o *RuntimeOption) ValidateSwitch() error {
+	if o.MachineType == "" {
+		return fmt.Errorf("%w: machine type is required", ErrInvalidOption)
+	}
+	if err := checkRuntimeAccelerator(o.MachineType, o.AcceleratorType, o.AcceleratorCount, false); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidOption, err)
+	}
+	return nil
+}
+
+func (o *RuntimeOption) machineType() string {
+	if o.MachineType == "" {
+		return DefaultRuntimeMachineType
+	}
+	return o.MachineType
+}
+
+// proto returns the runtime to create.
+func (o *RuntimeOption) proto() *notebookspb.Runtime {
+	diskType := o.DataDiskType
+	if diskType == "" {
+		diskType = DefaultDiskType
+	}
+	t, _ := parseRuntimeDiskType(diskType)
+	sizeGB := o.DataDiskSizeGB
+	if sizeGB == 0 {
+		sizeGB = DefaultRuntimeDataDiskSizeGB
+	}
+
+	vm := &notebookspb.VirtualMachineConfig{
+		MachineType: o.machineType(),
+		DataDisk: &notebookspb.LocalDisk{
+			InitializeParams: &notebookspb.LocalDiskInitializeParams{
+				DiskSizeGb: sizeGB,
+				DiskType:   t,
+			},
+		},
+		AcceleratorConfig: o.acceleratorConfig(),
+		InternalIpOnly:    o.NoPublicIP,
+		Tags:              o.Tags,
+		Labels:            o.Labels,
+	}
+	if o.Network != "" {
+		vm.Network = fmt.Sprintf("projects/%s/global/networks/%s", o.ProjectId, o.Network)
+	}
+	if o.Subnet != "" {
+		vm.Subnet = fmt.Sprintf("projects/%s/regions/%s/subnetworks/%s", o.ProjectId, o.Location, o.Subnet)
+	}
+	if o.KmsKey != "" {
+		vm.EncryptionConfig = &notebookspb.EncryptionConfig{KmsKey: o.KmsKey}
+	}
+
+	software := &notebookspb.RuntimeSoftwareConfig{
+		InstallGpuDriver: o.InstallGpuDriver,
+	}
+	if o.IdleTimeout != 0 {
+		idleShutdown := true
+		software.IdleShutdown = &idleShutdown
+		software.IdleShutdownTimeout = int32(o.IdleTimeout / time.Minute)
+	}
+
+	return &notebookspb.Runtime{
+		RuntimeType: &notebookspb.Runtime_VirtualMachine{VirtualMachine: &notebookspb.VirtualMachine{
+			VirtualMachineConfig: vm,
+		}},
+		AccessConfig: &notebookspb.RuntimeAccessConfig{
+			AccessType:   notebookspb.RuntimeAccessConfig_SINGLE_USER,
+			RuntimeOwner: o.Email,
+		},
+		SoftwareConfig: software,
+	}
+}
+
+// acceleratorConfig returns the accelerator to attach to runtime, or nil if it is not specified.
+func (o *RuntimeOption) acceleratorConfig() *notebookspb.RuntimeAcceleratorConfig {
+	if o.AcceleratorType == "" {
+		return nil
+	}
+	v := notebookspb.RuntimeAcceleratorConfig_AcceleratorType_value[strings.ToUpper(o.AcceleratorType)]
+	return &notebookspb.RuntimeAcceleratorConfig{
+		Type:      notebookspb.RuntimeAcceleratorConfig_AcceleratorType(v),
+		CoreCount: o.AcceleratorCount,
+	}
+}
+
+// checkRuntimeAccelerator is the same as checkAccelerator, but also checks that the accelerator is supported by runtimes.
+func checkRuntimeAccelerator(machineType, acceleratorType string, count int64, installGpuDriver bool) error {
+	if err := checkAccelerator(machineType, acceleratorType, count, installGpuDriver); err != nil {
+		return err
+	}
+	if acceleratorType == "" {
+		return nil
+	}
+	if _, ok := notebookspb.RuntimeAcceleratorConfig_AcceleratorType_value[strings.ToUpper(acceleratorType)]; !ok {
+		return fmt.Errorf("accelerator type %q is not supported by managed notebook runtimes", acceleratorType)
+	}
+	return nil
+}
+
+func parseRuntimeDiskType(s string) (notebookspb.LocalDiskInitializeParams_DiskType, error) {
+	v, ok := notebookspb.LocalDiskInitializeParams_DiskType_value[strings.ToUpper(s)]
+	if !ok || v == int32(notebookspb.LocalDiskInitializeParams_DISK_TYPE_UNSPECIFIED) {
+		return notebookspb.LocalDiskInitializeParams_DISK_TYPE_UNSPECIFIED, fmt.Errorf("unknown disk type %q, use one of PD_STANDARD, PD_SSD, PD_BALANCED or PD_EXTREME", s)
+	}
+	return notebookspb.LocalDiskInitializeParams_DiskType(v), nil
+}
+
+func runtimeFullname(projectID, location, name string) string {
+	return fmt.Sprintf("projects/%s/locations/%s/runtimes/%s", projectID, location, name)
+}
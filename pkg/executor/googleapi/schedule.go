@@ -0,0 +1,136 @@
+package googleapi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/notebooks/apiv1/notebookspb"
+)
+
+// DefaultScheduleTimeZone is the time zone the cron schedule is interpreted in when omitted
+const DefaultScheduleTimeZone = "UTC"
+
+// ScheduleOption is the option of notebook schedule, which creates the notebook execution from Template at each scheduled time.
+type ScheduleOption struct {
+	// Name indicates the schedule ID, which must be unique in the location
+	Name string
+	// Location indicates the region running the executions, e.g. "asia-northeast1"
+	Location string
+	// ProjectId indicates the GCP project ID
+	ProjectId string
+	// CronSchedule indicates when the executions are created in unix-cron format, e.g. "0 9 * * 1-5"
+	CronSchedule string
+	// TimeZone indicates the IANA time zone the cron schedule is interpreted in, DefaultScheduleTimeZone is used if omitted
+	TimeZone string
+	// Description indicates the description of the schedule
+	Description string
+	// Template indicates the execution created at each scheduled time, only required to create the schedule.
+	// Name, Location and ProjectId of the template are taken from the schedule.
+	Template *ExecutionOption
+}
+
+type ScheduleStatus struct {
+	Name         string
+	State        ScheduleState
+	CronSchedule string
+	TimeZone     string
+	Description  string
+	// RecentExecutions indicates the executions recently created by the schedule, including the triggered ones
+	RecentExecutions []ExecutionStatus
+}
+
+// ScheduleState is the state of notebook schedule, the values are the same as notebookspb.Schedule_State names.
+type ScheduleState string
+
+const (
+	ScheduleStateUnspecified  ScheduleState = "STATE_UNSPECIFIED"
+	ScheduleStateEnabled      ScheduleState = "ENABLED"
+	ScheduleStatePaused       ScheduleState = "PAUSED"
+	ScheduleStateDisabled     ScheduleState = "DISABLED"
+	ScheduleStateUpdateFailed ScheduleState = "UPDATE_FAILED"
+	ScheduleStateInitializing ScheduleState = "INITIALIZING"
+	ScheduleStateDeleting     ScheduleState = "DELETING"
+)
+
+var scheduleStates = map[notebookspb.Schedule_State]ScheduleState{
+	notebookspb.Schedule_STATE_UNSPECIFIED: ScheduleStateUnspecified,
+	notebookspb.Schedule_ENABLED:           ScheduleStateEnabled,
+	notebookspb.Schedule_PAUSED:            ScheduleStatePaused,
+	notebookspb.Schedule_DISABLED:          ScheduleStateDisabled,
+	notebookspb.Schedule_UPDATE_FAILED:     ScheduleStateUpdateFailed,
+	notebookspb.Schedule_INITIALIZING:      ScheduleStateInitializing,
+	notebookspb.Schedule_DELETING:          ScheduleStateDeleting,
+}
+
+// scheduleStateFromProto converts the state returned from Notebooks API, unknown states added to the API are treated as unspecified.
+func scheduleStateFromProto(state notebookspb.Schedule_State) ScheduleState {
+	if s, ok := scheduleStates[state]; ok {
+		return s
+	}
+	return ScheduleStateUnspecified
+}
+
+func scheduleStatusFromProto(s *notebookspb.Schedule) ScheduleStatus {
+	status := ScheduleStatus{
+		Name:         s.GetName(),
+		State:        scheduleStateFromProto(s.GetState()),
+		CronSchedule: s.GetCronSchedule(),
+		TimeZone:     s.GetTimeZone(),
+		Description:  s.GetDescription(),
+	}
+	for _, e := range s.GetRecentExecutions() {
+		status.RecentExecutions = append(status.RecentExecutions, executionStatusFromProto(e))
+	}
+	return status
+}
+
+// Validate checks the option to create notebook schedule, including the execution template.
+// All problems found are returned at once, wrapped with ErrInvalidOption.
+func (o *ScheduleOption) Validate() error {
+	var errs []error
+	// unix-cron の構文は Notebooks API 側で検証されるので、ここではフィールドの数だけを確認する
+	if len(strings.Fields(o.CronSchedule)) != 5 {
+		errs = append(errs, fmt.Errorf("invalid cron schedule %q, use unix-cron format with 5 fields, e.g. \"0 9 * * 1-5\"", o.CronSchedule))
+	}
+	if o.Template == nil {
+		errs = append(errs, errors.New("execution template is required"))
+	} else {
+		errs = append(errs, o.template().check()...)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrInvalidOption, errors.Join(errs...))
+}
+
+// template returns the execution template with the name and location of the schedule.
+func (o *ScheduleOption) template() *ExecutionOption {
+	t := *o.Template
+	t.Name = o.Name
+	t.Location = o.Location
+	t.ProjectId = o.ProjectId
+	return &t
+}
+
+func (o *ScheduleOption) timeZone() string {
+	if o.TimeZone == "" {
+		return DefaultScheduleTimeZone
+	}
+	return o.TimeZone
+}
+
+// proto returns the schedule to create.
+func (o *ScheduleOption) proto() *notebookspb.Schedule {
+	return &notebookspb.Schedule{
+		DisplayName:       o.Name,
+		Description:       o.Description,
+		CronSchedule:      o.CronSchedule,
+		TimeZone:          o.timeZone(),
+		ExecutionTemplate: o.template().template(),
+	}
+}
+
+func scheduleFullname(projectID, location, name string) string {
+	return fmt.Sprintf("projects/%s/locations/%s/schedules/%s", projectID, location, name)
+}
@@ -0,0 +1,160 @@
+package googleapi
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"cloud.google.com/go/notebooks/apiv1/notebookspb"
+)
+
+const (
+	// MaxTags is the maximum number of network tags attached to an instance
+	MaxTags = 64
+)
+
+var (
+	// tagRegexp is the requirement of network tags, which must be RFC 1035 compliant.
+	// https://cloud.google.com/vpc/docs/add-remove-network-tags#restrictions
+	tagRegexp = regexp.MustCompile(`^[a-z]([-a-z0-9]{0,61}[a-z0-9])?$`)
+	// serviceAccountRegexp roughly checks the service account is specified with email address, not with its unique ID or name.
+	serviceAccountRegexp = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+)
+
+// SecurityProfile is the security settings applied by the worker to instances created without them,
+// so that all instances follow the security baseline even if callers forget to specify them.
+type SecurityProfile struct {
+	// NoPublicIP indicates whether instances are created without public IP address
+	NoPublicIP *bool
+	// ServiceAccount indicates the service account email that instances run as
+	ServiceAccount string
+	// Tags indicates the network tags attached to instances, e.g. to apply firewall rules
+	Tags []string
+	// ShieldedSecureBoot indicates whether instances boot only with verified software
+	ShieldedSecureBoot *bool
+	// ShieldedVtpm indicates whether instances have virtual Trusted Platform Module
+	ShieldedVtpm *bool
+	// ShieldedIntegrityMonitoring indicates whether boot integrity of instances is monitored, which requires vTPM
+	ShieldedIntegrityMonitoring *bool
+	// DiskEncryption indicates how boot and data disks are encrypted, "GMEK" or "CMEK"
+	DiskEncryption string
+	// KmsKey indicates the Cloud KMS key to encrypt disks, which is required when DiskEncryption is "CMEK"
+	KmsKey string
+}
+
+// Validate checks the security profile.
+// All problems found are returned at once, wrapped with ErrInvalidOption.
+func (p *SecurityProfile) Validate() error {
+	var errs []error
+	if err := checkServiceAccount(p.ServiceAccount); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkTags(p.Tags); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkShieldedInstance(p.ShieldedVtpm, p.ShieldedIntegrityMonitoring); err != nil {
+		errs = append(errs, err)
+	}
+	if err := checkDiskEncryption(p.DiskEncryption, p.KmsKey); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %w", ErrInvalidOption, errors.Join(errs...))
+}
+
+// ApplySecurityProfile fills the security settings omitted in the option with the profile.
+// Settings explicitly specified in the option, including false, take precedence over the profile.
+func (o *Option) ApplySecurityProfile(p *SecurityProfile) {
+	if p == nil {
+		return
+	}
+	if o.NoPublicIP == nil {
+		o.NoPublicIP = p.NoPublicIP
+	}
+	if o.ServiceAccount == "" {
+		o.ServiceAccount = p.ServiceAccount
+	}
+	if len(o.Tags) == 0 {
+		o.Tags = p.Tags
+	}
+	if o.ShieldedSecureBoot == nil {
+		o.ShieldedSecureBoot = p.ShieldedSecureBoot
+	}
+	if o.ShieldedVtpm == nil {
+		o.ShieldedVtpm = p.ShieldedVtpm
+	}
+	if o.ShieldedIntegrityMonitoring == nil {
+		o.ShieldedIntegrityMonitoring = p.ShieldedIntegrityMonitoring
+	}
+	// KMS キーだけを上書きすると暗号化方式と食い違うので、暗号化の設定はまとめて適用する
+	if o.DiskEncryption == "" && o.KmsKey == "" {
+		o.DiskEncryption = p.DiskEncryption
+		o.KmsKey = p.KmsKey
+	}
+}
+
+// shieldedInstanceConfig returns the Shielded VM settings, or nil to use the defaults of Notebooks API if none is specified.
+func (o *Option) shieldedInstanceConfig() *notebookspb.Instance_ShieldedInstanceConfig {
+	if o.ShieldedSecureBoot == nil && o.ShieldedVtpm == nil && o.ShieldedIntegrityMonitoring == nil {
+		return nil
+	}
+	// 指定されなかった項目は Notebooks API のデフォルト値 (セキュアブートは無効、vTPM と整合性モニタリングは有効) にする
+	return &notebookspb.Instance_ShieldedInstanceConfig{
+		EnableSecureBoot:          boolOrDefault(o.ShieldedSecureBoot, false),
+		EnableVtpm:                boolOrDefault(o.ShieldedVtpm, true),
+		EnableIntegrityMonitoring: boolOrDefault(o.ShieldedIntegrityMonitoring, true),
+	}
+}
+
+func boolOrDefault(b *bool, defaultValue bool) bool {
+	if b == nil {
+		return defaultValue
+	}
+	return *b
+}
+
+func checkServiceAccount(serviceAccount string) error {
+	if serviceAccount != "" && !serviceAccountRegexp.MatchString(serviceAccount) {
+		return fmt.Errorf("invalid service account %q, use email address, e.g. \"sa-name@project-id.iam.gserviceaccount.com\"", serviceAccount)
+	}
+	return nil
+}
+
+func checkTags(tags []string) error {
+	if len(tags) > MaxTags {
+		return fmt.Errorf("up to %d network tags can be attached, but %d specified", MaxTags, len(tags))
+	}
+	var errs []error
+	for _, tag := range tags {
+		if !tagRegexp.MatchString(tag) {
+			errs = append(errs, fmt.Errorf("invalid network tag %q, must start with lowercase letter and contain only lowercase letters, numbers and dashes up to 63 characters", tag))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func checkShieldedInstance(vtpm, integrityMonitoring *bool) error {
+	if !boolOrDefault(vtpm, true) && boolOrDefault(integrityMonitoring, true) {
+		return errors.New("integrity monitoring requires vTPM, disable integrity monitoring as well")
+	}
+	return nil
+}
+
+func checkDiskEncryption(encryption, kmsKey string) error {
+	if encryption != "" {
+		if _, err := parseDiskEncryption(encryption); err != nil {
+			return err
+		}
+	}
+	cmek := strings.EqualFold(encryption, notebookspb.Instance_CMEK.String())
+	if cmek && kmsKey == "" {
+		return errors.New("KMS key is required for CMEK disk encryption")
+	}
+	if !cmek && kmsKey != "" {
+		return errors.New("KMS key can be used only with CMEK disk encryption")
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package googleapi
+
+import (
+	"cloud.google.com/go/notebooks/apiv1/notebookspb"
+)
+
+// InstanceState is the state of Workbench instance.
+// The values are the same as notebookspb.Instance_State names, so that they are compatible with Status recorded as string.
+type InstanceState string
+
+const (
+	InstanceStateUnspecified  InstanceState = "STATE_UNSPECIFIED"
+	InstanceStateStarting     InstanceState = "STARTING"
+	InstanceStateProvisioning InstanceState = "PROVISIONING"
+	InstanceStateActive       InstanceState = "ACTIVE"
+	InstanceStateStopping     InstanceState = "STOPPING"
+	InstanceStateStopped      InstanceState = "STOPPED"
+	InstanceStateDeleted      InstanceState = "DELETED"
+	InstanceStateUpgrading    InstanceState = "UPGRADING"
+	InstanceStateInitializing InstanceState = "INITIALIZING"
+	InstanceStateRegistering  InstanceState = "REGISTERING"
+	InstanceStateSuspending   InstanceState = "SUSPENDING"
+	InstanceStateSuspended    InstanceState = "SUSPENDED"
+)
+
+var instanceStates = map[notebookspb.Instance_State]InstanceState{
+	notebookspb.Instance_STATE_UNSPECIFIED: InstanceStateUnspecified,
+	notebookspb.Instance_STARTING:          InstanceStateStarting,
+	notebookspb.Instance_PROVISIONING:      InstanceStateProvisioning,
+	notebookspb.Instance_ACTIVE:            InstanceStateActive,
+	notebookspb.Instance_STOPPING:          InstanceStateStopping,
+	notebookspb.Instance_STOPPED:           InstanceStateStopped,
+	notebookspb.Instance_DELETED:           InstanceStateDeleted,
+	notebookspb.Instance_UPGRADING:         InstanceStateUpgrading,
+	notebookspb.Instance_INITIALIZING:      InstanceStateInitializing,
+	notebookspb.Instance_REGISTERING:       InstanceStateRegistering,
+	notebookspb.Instance_SUSPENDING:        InstanceStateSuspending,
+	notebookspb.Instance_SUSPENDED:         InstanceStateSuspended,
+}
+
+// instanceStateFromProto converts the state returned from Notebooks API, unknown states added to the API are treated as unspecified.
+func instanceStateFromProto(state notebookspb.Instance_State) InstanceState {
+	if s, ok := instanceStates[state]; ok {
+		return s
+	}
+	return InstanceStateUnspecified
+}
+
+// IsRunning reports whether the instance is ready to be accessed.
+func (s InstanceState) IsRunning() bool {
+	return s == InstanceStateActive
+}
+
+// IsTransitional reports whether the instance is changing to another state by itself, so that callers should wait for it.
+func (s InstanceState) IsTransitional() bool {
+	switch s {
+	case InstanceStateStarting, InstanceStateProvisioning, InstanceStateStopping, InstanceStateUpgrading,
+		InstanceStateInitializing, InstanceStateRegistering, InstanceStateSuspending:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsStopped reports whether the instance is not running and stays so until it is started.
+// Suspended instances are not included, because Notebooks API cannot start them.
+func (s InstanceState) IsStopped() bool {
+	return s == InstanceStateStopped
+}
+
+// IsTerminal reports whether the instance can never be running again.
+func (s InstanceState) IsTerminal() bool {
+	return s == InstanceStateDeleted
+}
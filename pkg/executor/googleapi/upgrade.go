@@ -0,0 +1,74 @@
+package googleapi
+
+import (
+	"time"
+
+	"cloud.google.com/go/notebooks/apiv1/notebookspb"
+)
+
+// Actions and states of UpgradeHistoryEntry, the same as notebookspb.Instance_UpgradeHistoryEntry_* names.
+const (
+	UpgradeActionUpgrade  = "UPGRADE"
+	UpgradeActionRollback = "ROLLBACK"
+
+	UpgradeStateStarted   = "STARTED"
+	UpgradeStateSucceeded = "SUCCEEDED"
+	UpgradeStateFailed    = "FAILED"
+)
+
+// Upgradeability is whether the image of the instance can be upgraded, returned by IsNotebookInstanceUpgradeable
+type Upgradeability struct {
+	// Upgradeable indicates whether newer image is available for the instance
+	Upgradeable bool
+	// Version indicates the version the instance will be upgraded to
+	Version string
+	// Info indicates the reason why the instance is not upgradeable, or additional information about the upgrade
+	Info string
+	// Image indicates the VM image or container image the instance will be upgraded to
+	Image string
+}
+
+// UpgradeHistoryEntry is the record of an upgrade or a rollback of the instance
+type UpgradeHistoryEntry struct {
+	// Snapshot indicates the snapshot of the boot disk taken before the upgrade, which the instance can be rolled back to
+	Snapshot string
+	// Version indicates the version of the instance before the action
+	Version string
+	// TargetVersion indicates the version of the instance after the action
+	TargetVersion string
+	// Action indicates the action, UpgradeActionUpgrade or UpgradeActionRollback
+	Action string
+	// State indicates the progress of the action, one of UpgradeState* values
+	State string
+	// CreateTime indicates when the action started
+	CreateTime time.Time
+}
+
+// LatestUpgradeSnapshot returns the snapshot taken before the latest upgrade in the entries,
+// or empty string if no upgrade has taken a snapshot.
+func LatestUpgradeSnapshot(entries []UpgradeHistoryEntry) string {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Action == UpgradeActionUpgrade && entries[i].Snapshot != "" {
+			return entries[i].Snapshot
+		}
+	}
+	return ""
+}
+
+func upgradeHistoryFromProto(entries []*notebookspb.Instance_UpgradeHistoryEntry) []UpgradeHistoryEntry {
+	if len(entries) == 0 {
+		return nil
+	}
+	history := make([]UpgradeHistoryEntry, 0, len(entries))
+	for _, e := range entries {
+		history = append(history, UpgradeHistoryEntry{
+			Snapshot:      e.GetSnapshot(),
+			Version:       e.GetVersion(),
+			TargetVersion: e.GetTargetVersion(),
+			Action:        e.GetAction().String(),
+			State:         e.GetState().String(),
+			CreateTime:    e.GetCreateTime().AsTime(),
+		})
+	}
+	return history
+}
@@ -3,58 +3,146 @@ package googleapi
 import (
 	"context"
 	"fmt"
+	"path"
 
+	compute "cloud.google.com/go/compute/apiv1"
+	"cloud.google.com/go/compute/apiv1/computepb"
 	"cloud.google.com/go/longrunning/autogen/longrunningpb"
 	notebooks "cloud.google.com/go/notebooks/apiv1"
 	"cloud.google.com/go/notebooks/apiv1/notebookspb"
+	"github.com/googleapis/gax-go/v2"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 var (
-	_ NotebookService = &workbench{}
+	_ Executor = &workbench{}
 )
 
 type workbench struct {
 	notebookClient *notebooks.NotebookClient
+	*computeClient
 }
 
-func NewWorkbench(ctx context.Context) (Executor, error) {
-	notebookClient, err := notebooks.NewNotebookClient(ctx)
+// computeClient implements ComputeService, shared by the executors of Notebooks v1 and v2 API
+type computeClient struct {
+	acceleratorTypeClient *compute.AcceleratorTypesClient
+	instanceClient        *compute.InstancesClient
+}
+
+// WorkbenchOption configures Google Cloud API clients used by the workbench executor.
+type WorkbenchOption func(*workbenchOptions)
+
+type workbenchOptions struct {
+	notebookClientOptions []option.ClientOption
+	computeClientOptions  []option.ClientOption
+}
+
+// WithClientOptions adds client options to all Google Cloud API clients, e.g. credentials.
+func WithClientOptions(opts ...option.ClientOption) WorkbenchOption {
+	return func(o *workbenchOptions) {
+		o.notebookClientOptions = append(o.notebookClientOptions, opts...)
+		o.computeClientOptions = append(o.computeClientOptions, opts...)
+	}
+}
+
+// WithNotebookClientOptions adds client options to Notebooks API client, e.g. to connect to fake server for testing.
+func WithNotebookClientOptions(opts ...option.ClientOption) WorkbenchOption {
+	return func(o *workbenchOptions) {
+		o.notebookClientOptions = append(o.notebookClientOptions, opts...)
+	}
+}
+
+// WithComputeClientOptions adds client options to Compute Engine API client, e.g. to connect to fake server for testing.
+func WithComputeClientOptions(opts ...option.ClientOption) WorkbenchOption {
+	return func(o *workbenchOptions) {
+		o.computeClientOptions = append(o.computeClientOptions, opts...)
+	}
+}
+
+// NewWorkbench returns Executor backed by user managed notebooks API and Compute Engine API.
+func NewWorkbench(ctx context.Context, opts ...WorkbenchOption) (Executor, error) {
+	o := &workbenchOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	notebookClient, err := notebooks.NewNotebookClient(ctx, o.notebookClientOptions...)
 	if err != nil {
-		return &workbench{}, fmt.Errorf("failed to initialize compute service: %s", err)
+		return &workbench{}, fmt.Errorf("failed to initialize notebook service: %s", err)
+	}
+	computeClient, err := newComputeClient(ctx, o.computeClientOptions...)
+	if err != nil {
+		return &workbench{}, err
 	}
 
 	return &workbench{
 		notebookClient: notebookClient,
+		computeClient:  computeClient,
+	}, nil
+}
+
+func newComputeClient(ctx context.Context, opts ...option.ClientOption) (*computeClient, error) {
+	// Compute Engine API の gRPC エンドポイントは提供されていないので REST クライアントを使用する
+	acceleratorTypeClient, err := compute.NewAcceleratorTypesRESTClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize compute service: %s", err)
+	}
+	instanceClient, err := compute.NewInstancesRESTClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize compute service: %s", err)
+	}
+	return &computeClient{
+		acceleratorTypeClient: acceleratorTypeClient,
+		instanceClient:        instanceClient,
 	}, nil
 }
 
 func (w *workbench) CreateNotebookInstance(ctx context.Context, option *Option) (string, error) {
+	metadata, err := option.metadata()
+	if err != nil {
+		return "", err
+	}
+	bootDiskType, bootDiskSizeGB := option.bootDisk()
+	dataDiskType, dataDiskSizeGB := option.dataDisk()
+	instance := &notebookspb.Instance{
+		BootDiskType:   bootDiskType,
+		BootDiskSizeGb: bootDiskSizeGB,
+		DataDiskType:   dataDiskType,
+		DataDiskSizeGb: dataDiskSizeGB,
+		DiskEncryption: option.diskEncryption(),
+		KmsKey:         option.KmsKey,
+		Network:        fmt.Sprintf("projects/%s/global/networks/%s", option.ProjectId, option.Network),
+		Subnet:         fmt.Sprintf("projects/%s/regions/%s/subnetworks/%s", option.ProjectId, option.Location, option.Subnet),
+		InstanceOwners: []string{option.Email},
+		MachineType:    option.MachineType,
+
+		NoPublicIp:             boolOrDefault(option.NoPublicIP, false),
+		ServiceAccount:         option.ServiceAccount,
+		Tags:                   option.Tags,
+		ShieldedInstanceConfig: option.shieldedInstanceConfig(),
+
+		AcceleratorConfig: option.acceleratorConfig(),
+		InstallGpuDriver:  option.InstallGpuDriver,
+
+		Labels:   option.Labels,
+		Metadata: metadata,
+	}
+	if container := option.containerImage(); container != nil {
+		instance.Environment = &notebookspb.Instance_ContainerImage{ContainerImage: container}
+	} else {
+		instance.Environment = &notebookspb.Instance_VmImage{VmImage: option.vmImage()}
+	}
 	req := &notebookspb.CreateInstanceRequest{
 		Parent:     fmt.Sprintf("projects/%s/locations/%s", option.ProjectId, option.Zone),
 		InstanceId: option.Name,
-		Instance: &notebookspb.Instance{
-			Environment: &notebookspb.Instance_VmImage{
-				&notebookspb.VmImage{
-					Project: "deeplearning-platform-release",
-					Image: &notebookspb.VmImage_ImageFamily{
-						ImageFamily: "common-cpu-notebooks",
-					},
-				},
-			},
-			BootDiskType: notebookspb.Instance_PD_BALANCED,
-			// 最小構成で 50 GB は必要
-			BootDiskSizeGb: 50,
-			DataDiskType:   notebookspb.Instance_PD_BALANCED,
-			DataDiskSizeGb: 20,
-			Network:        fmt.Sprintf("projects/%s/global/networks/%s", option.ProjectId, option.Network),
-			Subnet:         fmt.Sprintf("projects/%s/regions/%s/subnetworks/%s", option.ProjectId, option.Location, option.Subnet),
-			InstanceOwners: []string{option.Email},
-			MachineType:    option.MachineType,
-		},
+		Instance:   instance,
 	}
 	op, err := w.notebookClient.CreateInstance(ctx, req)
 	if err != nil {
-		return "", fmt.Errorf("failed to create user managed notebook instance: %w", err)
+		return "", classifyError(fmt.Errorf("failed to create user managed notebook instance: %w", err))
 	}
 	return op.Name(), nil
 }
@@ -65,12 +153,20 @@ func (w *workbench) DescribeNotebookInstance(ctx context.Context, option *Option
 	}
 	wb, err := w.notebookClient.GetInstance(ctx, req)
 	if err != nil {
-		return nil, err
+		return nil, classifyError(err)
 	}
+	acceleratorType, acceleratorCount := acceleratorFromProto(wb.AcceleratorConfig)
 	return &Status{
 		Name:   wb.Name,
 		URL:    wb.ProxyUri,
-		Status: wb.State.String(),
+		Status: instanceStateFromProto(wb.State),
+		// マシンタイプは "https://www.googleapis.com/compute/v1/projects/{project}/zones/{zone}/machineTypes/{name}" の形式で返されることがある
+		MachineType:      path.Base(wb.MachineType),
+		AcceleratorType:  acceleratorType,
+		AcceleratorCount: acceleratorCount,
+		Labels:           wb.Labels,
+		Metadata:         wb.Metadata,
+		UpgradeHistory:   upgradeHistoryFromProto(wb.UpgradeHistory),
 	}, nil
 }
 
@@ -79,7 +175,7 @@ func (w *workbench) StartNotebookInstance(ctx context.Context, option *Option) (
 		Name: notebookInstanceFullname(option.ProjectId, option.Zone, option.Name),
 	})
 	if err != nil {
-		return "", err
+		return "", classifyError(err)
 	}
 	return op.Name(), nil
 }
@@ -89,7 +185,7 @@ func (w *workbench) StopNotebookInstance(ctx context.Context, option *Option) (s
 		Name: notebookInstanceFullname(option.ProjectId, option.Zone, option.Name),
 	})
 	if err != nil {
-		return "", err
+		return "", classifyError(err)
 	}
 	return op.Name(), nil
 }
@@ -99,20 +195,312 @@ func (w *workbench) DeleteNotebookInstance(ctx context.Context, option *Option)
 		Name: notebookInstanceFullname(option.ProjectId, option.Zone, option.Name),
 	})
 	if err != nil {
-		return "", err
+		return "", classifyError(err)
+	}
+	return op.Name(), nil
+}
+
+func (w *workbench) SetNotebookInstanceLabels(ctx context.Context, option *Option) (string, error) {
+	op, err := w.notebookClient.SetInstanceLabels(ctx, &notebookspb.SetInstanceLabelsRequest{
+		Name:   notebookInstanceFullname(option.ProjectId, option.Zone, option.Name),
+		Labels: option.Labels,
+	})
+	if err != nil {
+		return "", classifyError(err)
+	}
+	return op.Name(), nil
+}
+
+func (w *workbench) SetNotebookInstanceMachineType(ctx context.Context, option *Option) (string, error) {
+	op, err := w.notebookClient.SetInstanceMachineType(ctx, &notebookspb.SetInstanceMachineTypeRequest{
+		Name:        notebookInstanceFullname(option.ProjectId, option.Zone, option.Name),
+		MachineType: option.MachineType,
+	})
+	if err != nil {
+		return "", classifyError(err)
+	}
+	return op.Name(), nil
+}
+
+func (w *workbench) IsNotebookInstanceUpgradeable(ctx context.Context, option *Option) (*Upgradeability, error) {
+	resp, err := w.notebookClient.IsInstanceUpgradeable(ctx, &notebookspb.IsInstanceUpgradeableRequest{
+		NotebookInstance: notebookInstanceFullname(option.ProjectId, option.Zone, option.Name),
+	})
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	return &Upgradeability{
+		Upgradeable: resp.GetUpgradeable(),
+		Version:     resp.GetUpgradeVersion(),
+		Info:        resp.GetUpgradeInfo(),
+		Image:       resp.GetUpgradeImage(),
+	}, nil
+}
+
+func (w *workbench) UpgradeNotebookInstance(ctx context.Context, option *Option) (string, error) {
+	op, err := w.notebookClient.UpgradeInstance(ctx, &notebookspb.UpgradeInstanceRequest{
+		Name: notebookInstanceFullname(option.ProjectId, option.Zone, option.Name),
+	})
+	if err != nil {
+		return "", classifyError(err)
+	}
+	return op.Name(), nil
+}
+
+func (w *workbench) RollbackNotebookInstance(ctx context.Context, option *Option) (string, error) {
+	op, err := w.notebookClient.RollbackInstance(ctx, &notebookspb.RollbackInstanceRequest{
+		Name:           notebookInstanceFullname(option.ProjectId, option.Zone, option.Name),
+		TargetSnapshot: option.Snapshot,
+	})
+	if err != nil {
+		return "", classifyError(err)
+	}
+	return op.Name(), nil
+}
+
+func (w *workbench) ResetNotebookInstance(ctx context.Context, option *Option) (string, error) {
+	op, err := w.notebookClient.ResetInstance(ctx, &notebookspb.ResetInstanceRequest{
+		Name: notebookInstanceFullname(option.ProjectId, option.Zone, option.Name),
+	})
+	if err != nil {
+		return "", classifyError(err)
+	}
+	return op.Name(), nil
+}
+
+func (w *workbench) DiagnoseNotebookInstance(ctx context.Context, option *Option) (string, error) {
+	if option.Diagnostic == nil {
+		return "", fmt.Errorf("%w: diagnostic configuration is required", ErrInvalidOption)
+	}
+	op, err := w.notebookClient.DiagnoseInstance(ctx, &notebookspb.DiagnoseInstanceRequest{
+		Name:             notebookInstanceFullname(option.ProjectId, option.Zone, option.Name),
+		DiagnosticConfig: option.Diagnostic.proto(),
+	})
+	if err != nil {
+		return "", classifyError(err)
+	}
+	return op.Name(), nil
+}
+
+func (w *workbench) CreateExecution(ctx context.Context, option *ExecutionOption) (string, error) {
+	op, err := w.notebookClient.CreateExecution(ctx, &notebookspb.CreateExecutionRequest{
+		Parent:      fmt.Sprintf("projects/%s/locations/%s", option.ProjectId, option.Location),
+		ExecutionId: option.Name,
+		Execution:   option.proto(),
+	})
+	if err != nil {
+		return "", classifyError(fmt.Errorf("failed to create notebook execution: %w", err))
+	}
+	return op.Name(), nil
+}
+
+func (w *workbench) DescribeExecution(ctx context.Context, option *ExecutionOption) (*ExecutionStatus, error) {
+	e, err := w.notebookClient.GetExecution(ctx, &notebookspb.GetExecutionRequest{
+		Name: executionFullname(option.ProjectId, option.Location, option.Name),
+	})
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	status := executionStatusFromProto(e)
+	return &status, nil
+}
+
+func (w *workbench) CreateSchedule(ctx context.Context, option *ScheduleOption) (string, error) {
+	op, err := w.notebookClient.CreateSchedule(ctx, &notebookspb.CreateScheduleRequest{
+		Parent:     fmt.Sprintf("projects/%s/locations/%s", option.ProjectId, option.Location),
+		ScheduleId: option.Name,
+		Schedule:   option.proto(),
+	})
+	if err != nil {
+		return "", classifyError(fmt.Errorf("failed to create notebook schedule: %w", err))
+	}
+	return op.Name(), nil
+}
+
+func (w *workbench) DescribeSchedule(ctx context.Context, option *ScheduleOption) (*ScheduleStatus, error) {
+	s, err := w.notebookClient.GetSchedule(ctx, &notebookspb.GetScheduleRequest{
+		Name: scheduleFullname(option.ProjectId, option.Location, option.Name),
+	})
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	status := scheduleStatusFromProto(s)
+	return &status, nil
+}
+
+func (w *workbench) ListSchedules(ctx context.Context, option *ScheduleOption) ([]ScheduleStatus, error) {
+	it := w.notebookClient.ListSchedules(ctx, &notebookspb.ListSchedulesRequest{
+		Parent: fmt.Sprintf("projects/%s/locations/%s", option.ProjectId, option.Location),
+	})
+	var schedules []ScheduleStatus
+	for {
+		s, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, classifyError(fmt.Errorf("failed to list notebook schedules in %s: %w", option.Location, err))
+		}
+		schedules = append(schedules, scheduleStatusFromProto(s))
+	}
+	return schedules, nil
+}
+
+func (w *workbench) DeleteSchedule(ctx context.Context, option *ScheduleOption) (string, error) {
+	op, err := w.notebookClient.DeleteSchedule(ctx, &notebookspb.DeleteScheduleRequest{
+		Name: scheduleFullname(option.ProjectId, option.Location, option.Name),
+	})
+	if err != nil {
+		return "", classifyError(err)
+	}
+	return op.Name(), nil
+}
+
+func (w *workbench) TriggerSchedule(ctx context.Context, option *ScheduleOption) (string, error) {
+	op, err := w.notebookClient.TriggerSchedule(ctx, &notebookspb.TriggerScheduleRequest{
+		Name: scheduleFullname(option.ProjectId, option.Location, option.Name),
+	})
+	if err != nil {
+		return "", classifyError(err)
+	}
+	return op.Name(), nil
+}
+
+func (w *workbench) CreateEnvironment(ctx context.Context, option *EnvironmentOption) (string, error) {
+	op, err := w.notebookClient.CreateEnvironment(ctx, &notebookspb.CreateEnvironmentRequest{
+		Parent:        fmt.Sprintf("projects/%s/locations/%s", option.ProjectId, option.Zone),
+		EnvironmentId: option.Name,
+		Environment:   option.proto(),
+	})
+	if err != nil {
+		return "", classifyError(fmt.Errorf("failed to create notebook environment: %w", err))
 	}
 	return op.Name(), nil
 }
 
+func (w *workbench) DescribeEnvironment(ctx context.Context, option *EnvironmentOption) (*EnvironmentStatus, error) {
+	e, err := w.notebookClient.GetEnvironment(ctx, &notebookspb.GetEnvironmentRequest{
+		Name: environmentFullname(option.ProjectId, option.Zone, option.Name),
+	})
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	status := environmentStatusFromProto(e)
+	return &status, nil
+}
+
+func (w *workbench) ListEnvironments(ctx context.Context, option *EnvironmentOption) ([]EnvironmentStatus, error) {
+	it := w.notebookClient.ListEnvironments(ctx, &notebookspb.ListEnvironmentsRequest{
+		Parent: fmt.Sprintf("projects/%s/locations/%s", option.ProjectId, option.Zone),
+	})
+	var environments []EnvironmentStatus
+	for {
+		e, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, classifyError(fmt.Errorf("failed to list notebook environments in %s: %w", option.Zone, err))
+		}
+		environments = append(environments, environmentStatusFromProto(e))
+	}
+	return environments, nil
+}
+
+func (w *workbench) DeleteEnvironment(ctx context.Context, option *EnvironmentOption) (string, error) {
+	op, err := w.notebookClient.DeleteEnvironment(ctx, &notebookspb.DeleteEnvironmentRequest{
+		Name: environmentFullname(option.ProjectId, option.Zone, option.Name),
+	})
+	if err != nil {
+		return "", classifyError(err)
+	}
+	return op.Name(), nil
+}
+
+func (w *workbench) SetNotebookInstanceAccelerator(ctx context.Context, option *Option) (string, error) {
+	t, err := parseAcceleratorType(option.AcceleratorType)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidOption, err)
+	}
+	op, err := w.notebookClient.SetInstanceAccelerator(ctx, &notebookspb.SetInstanceAcceleratorRequest{
+		Name:      notebookInstanceFullname(option.ProjectId, option.Zone, option.Name),
+		Type:      t,
+		CoreCount: option.AcceleratorCount,
+	})
+	if err != nil {
+		return "", classifyError(err)
+	}
+	return op.Name(), nil
+}
+
+func (w *workbench) SetNotebookInstanceIdleTimeout(ctx context.Context, option *Option) error {
+	_, err := w.notebookClient.UpdateInstanceMetadataItems(ctx, &notebookspb.UpdateInstanceMetadataItemsRequest{
+		Name:  notebookInstanceFullname(option.ProjectId, option.Zone, option.Name),
+		Items: option.IdleTimeoutMetadata(),
+	})
+	if err != nil {
+		return classifyError(err)
+	}
+	return nil
+}
+
 func (w workbench) HasOperationDone(ctx context.Context, opName string) (bool, error) {
-	op, err := w.notebookClient.GetOperation(ctx, &longrunningpb.GetOperationRequest{
+	return hasOperationDone(ctx, w.notebookClient.GetOperation, opName)
+}
+
+func (c *computeClient) ListAcceleratorTypes(ctx context.Context, option *Option) ([]AcceleratorType, error) {
+	it := c.acceleratorTypeClient.List(ctx, &computepb.ListAcceleratorTypesRequest{
+		Project: option.ProjectId,
+		Zone:    option.Zone,
+	})
+	var types []AcceleratorType
+	for {
+		t, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, classifyError(fmt.Errorf("failed to list accelerator types in zone %s: %w", option.Zone, err))
+		}
+		types = append(types, AcceleratorType{
+			Name:     acceleratorTypeFromCompute(t.GetName()),
+			MaxCount: int64(t.GetMaximumCardsPerInstance()),
+		})
+	}
+	return types, nil
+}
+
+func (c *computeClient) GetGuestAttributes(ctx context.Context, option *Option, namespace string) (map[string]string, error) {
+	// user managed notebooks と Workbench Instances のインスタンスは同じ名前の Compute Engine インスタンスとして作成される
+	attrs, err := c.instanceClient.GetGuestAttributes(ctx, &computepb.GetGuestAttributesInstanceRequest{
+		Instance:  option.Name,
+		Project:   option.ProjectId,
+		Zone:      option.Zone,
+		QueryPath: proto.String(namespace + "/"),
+	})
+	if err != nil {
+		return nil, classifyError(fmt.Errorf("failed to get guest attributes in namespace %s: %w", namespace, err))
+	}
+	values := map[string]string{}
+	for _, item := range attrs.GetQueryValue().GetItems() {
+		values[item.GetKey()] = item.GetValue()
+	}
+	return values, nil
+}
+
+// operationGetter is GetOperation method of Google Cloud API clients
+type operationGetter func(ctx context.Context, req *longrunningpb.GetOperationRequest, opts ...gax.CallOption) (*longrunningpb.Operation, error)
+
+// hasOperationDone reports whether the long-running operation has finished, or returns error if it has aborted.
+func hasOperationDone(ctx context.Context, get operationGetter, opName string) (bool, error) {
+	op, err := get(ctx, &longrunningpb.GetOperationRequest{
 		Name: opName,
 	})
 	if err != nil {
-		return false, fmt.Errorf("failed to get notebook operation %q: %w", opName, err)
+		return false, classifyError(fmt.Errorf("failed to get notebook operation %q: %w", opName, err))
 	}
 	if op.GetError() != nil {
-		return false, fmt.Errorf("notebook operation %q aborted: %s", opName, op.GetError().GetMessage())
+		return false, classifyError(fmt.Errorf("notebook operation %q aborted: %w", opName, status.ErrorProto(op.GetError())))
 	}
 	if !op.GetDone() {
 		return false, nil
@@ -0,0 +1,444 @@
+package googleapi
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	v1pb "cloud.google.com/go/notebooks/apiv1/notebookspb"
+	notebooksv2 "cloud.google.com/go/notebooks/apiv2"
+	"cloud.google.com/go/notebooks/apiv2/notebookspb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+const (
+	// DefaultImageProjectV2 is the project of Workbench Instances images
+	DefaultImageProjectV2 = "cloud-notebooks-managed"
+	// DefaultImageFamilyV2 is the image family of Workbench Instances used when neither VM image nor container image is specified
+	DefaultImageFamilyV2 = "workbench-instances"
+)
+
+var (
+	_ Executor = &workbenchV2{}
+
+	errEnvironmentsUnsupportedV2 = fmt.Errorf("%w: notebook environments are not supported by Notebooks v2 API, use %q executor", ErrUnimplemented, ExecutorNameGoogleAPI)
+	errSchedulesUnsupportedV2    = fmt.Errorf("%w: notebook schedules are not supported by Notebooks v2 API, use %q executor", ErrUnimplemented, ExecutorNameGoogleAPI)
+)
+
+// workbenchV2 is the executor backed by Notebooks v2 API (Workbench Instances), which replaces user managed notebooks.
+// Option is mapped onto the GceSetup of the instance, so the same workflows work with both executors.
+type workbenchV2 struct {
+	notebookClient *notebooksv2.NotebookClient
+	*computeClient
+}
+
+// NewWorkbenchV2 returns Executor backed by Notebooks v2 API and Compute Engine API.
+func NewWorkbenchV2(ctx context.Context, opts ...WorkbenchOption) (Executor, error) {
+	o := &workbenchOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	notebookClient, err := notebooksv2.NewNotebookClient(ctx, o.notebookClientOptions...)
+	if err != nil {
+		return &workbenchV2{}, fmt.Errorf("failed to initialize notebook service: %s", err)
+	}
+	computeClient, err := newComputeClient(ctx, o.computeClientOptions...)
+	if err != nil {
+		return &workbenchV2{}, err
+	}
+
+	return &workbenchV2{
+		notebookClient: notebookClient,
+		computeClient:  computeClient,
+	}, nil
+}
+
+func (w *workbenchV2) CreateNotebookInstance(ctx context.Context, option *Option) (string, error) {
+	metadata, err := option.metadata()
+	if err != nil {
+		return "", err
+	}
+	accelerators, err := acceleratorConfigsV2(option)
+	if err != nil {
+		return "", err
+	}
+	bootDiskType, bootDiskSizeGB := option.bootDisk()
+	dataDiskType, dataDiskSizeGB := option.dataDisk()
+	encryption := notebookspb.DiskEncryption(notebookspb.DiskEncryption_value[option.diskEncryption().String()])
+	setup := &notebookspb.GceSetup{
+		MachineType:        option.MachineType,
+		AcceleratorConfigs: accelerators,
+		BootDisk: &notebookspb.BootDisk{
+			DiskType:       diskTypeV2(bootDiskType),
+			DiskSizeGb:     bootDiskSizeGB,
+			DiskEncryption: encryption,
+			KmsKey:         option.KmsKey,
+		},
+		DataDisks: []*notebookspb.DataDisk{{
+			DiskType:       diskTypeV2(dataDiskType),
+			DiskSizeGb:     dataDiskSizeGB,
+			DiskEncryption: encryption,
+			KmsKey:         option.KmsKey,
+		}},
+		NetworkInterfaces: []*notebookspb.NetworkInterface{{
+			Network: fmt.Sprintf("projects/%s/global/networks/%s", option.ProjectId, option.Network),
+			Subnet:  fmt.Sprintf("projects/%s/regions/%s/subnetworks/%s", option.ProjectId, option.Location, option.Subnet),
+		}},
+		DisablePublicIp:        boolOrDefault(option.NoPublicIP, false),
+		Tags:                   option.Tags,
+		ShieldedInstanceConfig: shieldedInstanceConfigV2(option.shieldedInstanceConfig()),
+		GpuDriverConfig:        &notebookspb.GPUDriverConfig{EnableGpuDriver: option.InstallGpuDriver},
+		Metadata:               metadata,
+	}
+	if option.ServiceAccount != "" {
+		setup.ServiceAccounts = []*notebookspb.ServiceAccount{{Email: option.ServiceAccount}}
+	}
+	if container := option.containerImage(); container != nil {
+		setup.Image = &notebookspb.GceSetup_ContainerImage{ContainerImage: &notebookspb.ContainerImage{
+			Repository: container.Repository,
+			Tag:        container.Tag,
+		}}
+	} else {
+		setup.Image = &notebookspb.GceSetup_VmImage{VmImage: vmImageV2(option)}
+	}
+	req := &notebookspb.CreateInstanceRequest{
+		Parent:     fmt.Sprintf("projects/%s/locations/%s", option.ProjectId, option.Zone),
+		InstanceId: option.Name,
+		Instance: &notebookspb.Instance{
+			Infrastructure: &notebookspb.Instance_GceSetup{GceSetup: setup},
+			InstanceOwners: []string{option.Email},
+			Labels:         option.Labels,
+		},
+	}
+	op, err := w.notebookClient.CreateInstance(ctx, req)
+	if err != nil {
+		return "", classifyError(fmt.Errorf("failed to create Workbench instance: %w", err))
+	}
+	return op.Name(), nil
+}
+
+func (w *workbenchV2) DescribeNotebookInstance(ctx context.Context, option *Option) (*Status, error) {
+	wb, err := w.getInstance(ctx, option)
+	if err != nil {
+		return nil, err
+	}
+	setup := wb.GetGceSetup()
+	st := &Status{
+		Name:   wb.Name,
+		URL:    wb.ProxyUri,
+		Status: instanceStateFromProtoV2(wb.State),
+		// マシンタイプは "https://www.googleapis.com/compute/v1/projects/{project}/zones/{zone}/machineTypes/{name}" の形式で返されることがある
+		MachineType:    path.Base(setup.GetMachineType()),
+		Labels:         wb.Labels,
+		Metadata:       setup.GetMetadata(),
+		UpgradeHistory: upgradeHistoryFromProtoV2(wb.UpgradeHistory),
+	}
+	// Workbench Instances にアタッチできるアクセラレータの設定は 1 つだけ
+	if configs := setup.GetAcceleratorConfigs(); len(configs) > 0 && configs[0].GetType() != notebookspb.AcceleratorConfig_ACCELERATOR_TYPE_UNSPECIFIED {
+		st.AcceleratorType = configs[0].GetType().String()
+		st.AcceleratorCount = configs[0].GetCoreCount()
+	}
+	return st, nil
+}
+
+func (w *workbenchV2) StartNotebookInstance(ctx context.Context, option *Option) (string, error) {
+	op, err := w.notebookClient.StartInstance(ctx, &notebookspb.StartInstanceRequest{
+		Name: notebookInstanceFullname(option.ProjectId, option.Zone, option.Name),
+	})
+	if err != nil {
+		return "", classifyError(err)
+	}
+	return op.Name(), nil
+}
+
+func (w *workbenchV2) StopNotebookInstance(ctx context.Context, option *Option) (string, error) {
+	op, err := w.notebookClient.StopInstance(ctx, &notebookspb.StopInstanceRequest{
+		Name: notebookInstanceFullname(option.ProjectId, option.Zone, option.Name),
+	})
+	if err != nil {
+		return "", classifyError(err)
+	}
+	return op.Name(), nil
+}
+
+func (w *workbenchV2) DeleteNotebookInstance(ctx context.Context, option *Option) (string, error) {
+	op, err := w.notebookClient.DeleteInstance(ctx, &notebookspb.DeleteInstanceRequest{
+		Name: notebookInstanceFullname(option.ProjectId, option.Zone, option.Name),
+	})
+	if err != nil {
+		return "", classifyError(err)
+	}
+	return op.Name(), nil
+}
+
+func (w *workbenchV2) SetNotebookInstanceLabels(ctx context.Context, option *Option) (string, error) {
+	return w.updateInstance(ctx, option, &notebookspb.Instance{Labels: option.Labels}, "labels")
+}
+
+func (w *workbenchV2) SetNotebookInstanceMachineType(ctx context.Context, option *Option) (string, error) {
+	return w.updateInstance(ctx, option, &notebookspb.Instance{
+		Infrastructure: &notebookspb.Instance_GceSetup{GceSetup: &notebookspb.GceSetup{
+			MachineType: option.MachineType,
+		}},
+	}, "gce_setup.machine_type")
+}
+
+func (w *workbenchV2) SetNotebookInstanceAccelerator(ctx context.Context, option *Option) (string, error) {
+	accelerators, err := acceleratorConfigsV2(option)
+	if err != nil {
+		return "", err
+	}
+	return w.updateInstance(ctx, option, &notebookspb.Instance{
+		Infrastructure: &notebookspb.Instance_GceSetup{GceSetup: &notebookspb.GceSetup{
+			AcceleratorConfigs: accelerators,
+		}},
+	}, "gce_setup.accelerator_configs")
+}
+
+func (w *workbenchV2) SetNotebookInstanceIdleTimeout(ctx context.Context, option *Option) error {
+	wb, err := w.getInstance(ctx, option)
+	if err != nil {
+		return err
+	}
+	// v2 API にはメタデータの一部だけを更新するメソッドがないので、既存のメタデータにマージして全体を置き換える
+	metadata := copyMap(wb.GetGceSetup().GetMetadata())
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	for k, v := range option.IdleTimeoutMetadata() {
+		metadata[k] = v
+	}
+	op, err := w.notebookClient.UpdateInstance(ctx, &notebookspb.UpdateInstanceRequest{
+		Instance: &notebookspb.Instance{
+			Name: notebookInstanceFullname(option.ProjectId, option.Zone, option.Name),
+			Infrastructure: &notebookspb.Instance_GceSetup{GceSetup: &notebookspb.GceSetup{
+				Metadata: metadata,
+			}},
+		},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"gce_setup.metadata"}},
+	})
+	if err != nil {
+		return classifyError(err)
+	}
+	// v1 API と同じく同期的に反映されるように、long-running operation の完了を待つ
+	if _, err := op.Wait(ctx); err != nil {
+		return classifyError(fmt.Errorf("failed to update metadata of Workbench instance: %w", err))
+	}
+	return nil
+}
+
+func (w *workbenchV2) IsNotebookInstanceUpgradeable(ctx context.Context, option *Option) (*Upgradeability, error) {
+	resp, err := w.notebookClient.CheckInstanceUpgradability(ctx, &notebookspb.CheckInstanceUpgradabilityRequest{
+		NotebookInstance: notebookInstanceFullname(option.ProjectId, option.Zone, option.Name),
+	})
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	return &Upgradeability{
+		Upgradeable: resp.GetUpgradeable(),
+		Version:     resp.GetUpgradeVersion(),
+		Info:        resp.GetUpgradeInfo(),
+		Image:       resp.GetUpgradeImage(),
+	}, nil
+}
+
+func (w *workbenchV2) UpgradeNotebookInstance(ctx context.Context, option *Option) (string, error) {
+	op, err := w.notebookClient.UpgradeInstance(ctx, &notebookspb.UpgradeInstanceRequest{
+		Name: notebookInstanceFullname(option.ProjectId, option.Zone, option.Name),
+	})
+	if err != nil {
+		return "", classifyError(err)
+	}
+	return op.Name(), nil
+}
+
+func (w *workbenchV2) RollbackNotebookInstance(ctx context.Context, option *Option) (string, error) {
+	op, err := w.notebookClient.RollbackInstance(ctx, &notebookspb.RollbackInstanceRequest{
+		Name:           notebookInstanceFullname(option.ProjectId, option.Zone, option.Name),
+		TargetSnapshot: option.Snapshot,
+	})
+	if err != nil {
+		return "", classifyError(err)
+	}
+	return op.Name(), nil
+}
+
+func (w *workbenchV2) ResetNotebookInstance(ctx context.Context, option *Option) (string, error) {
+	op, err := w.notebookClient.ResetInstance(ctx, &notebookspb.ResetInstanceRequest{
+		Name: notebookInstanceFullname(option.ProjectId, option.Zone, option.Name),
+	})
+	if err != nil {
+		return "", classifyError(err)
+	}
+	return op.Name(), nil
+}
+
+func (w *workbenchV2) DiagnoseNotebookInstance(ctx context.Context, option *Option) (string, error) {
+	if option.Diagnostic == nil {
+		return "", fmt.Errorf("%w: diagnostic configuration is required", ErrInvalidOption)
+	}
+	op, err := w.notebookClient.DiagnoseInstance(ctx, &notebookspb.DiagnoseInstanceRequest{
+		Name: notebookInstanceFullname(option.ProjectId, option.Zone, option.Name),
+		DiagnosticConfig: &notebookspb.DiagnosticConfig{
+			GcsBucket:               option.Diagnostic.GcsBucket,
+			RelativePath:            option.Diagnostic.RelativePath,
+			EnableRepairFlag:        option.Diagnostic.Repair,
+			EnablePacketCaptureFlag: option.Diagnostic.PacketCapture,
+			EnableCopyHomeFilesFlag: option.Diagnostic.CopyHomeFiles,
+		},
+	})
+	if err != nil {
+		return "", classifyError(err)
+	}
+	return op.Name(), nil
+}
+
+// CreateExecution is not supported, since Notebooks v2 API has no executions and they are moved to Vertex AI.
+func (w *workbenchV2) CreateExecution(ctx context.Context, option *ExecutionOption) (string, error) {
+	return "", fmt.Errorf("%w: notebook executions are not supported by Notebooks v2 API, use %q executor", ErrUnimplemented, ExecutorNameGoogleAPI)
+}
+
+func (w *workbenchV2) DescribeExecution(ctx context.Context, option *ExecutionOption) (*ExecutionStatus, error) {
+	return nil, fmt.Errorf("%w: notebook executions are not supported by Notebooks v2 API, use %q executor", ErrUnimplemented, ExecutorNameGoogleAPI)
+}
+
+// Schedules are not supported for the same reason as executions.
+func (w *workbenchV2) CreateSchedule(ctx context.Context, option *ScheduleOption) (string, error) {
+	return "", errSchedulesUnsupportedV2
+}
+
+func (w *workbenchV2) DescribeSchedule(ctx context.Context, option *ScheduleOption) (*ScheduleStatus, error) {
+	return nil, errSchedulesUnsupportedV2
+}
+
+func (w *workbenchV2) ListSchedules(ctx context.Context, option *ScheduleOption) ([]ScheduleStatus, error) {
+	return nil, errSchedulesUnsupportedV2
+}
+
+func (w *workbenchV2) DeleteSchedule(ctx context.Context, option *ScheduleOption) (string, error) {
+	return "", errSchedulesUnsupportedV2
+}
+
+func (w *workbenchV2) TriggerSchedule(ctx context.Context, option *ScheduleOption) (string, error) {
+	return "", errSchedulesUnsupportedV2
+}
+
+// Environments are not supported since Notebooks v2 API has no environment resource.
+func (w *workbenchV2) CreateEnvironment(ctx context.Context, option *EnvironmentOption) (string, error) {
+	return "", errEnvironmentsUnsupportedV2
+}
+
+func (w *workbenchV2) DescribeEnvironment(ctx context.Context, option *EnvironmentOption) (*EnvironmentStatus, error) {
+	return nil, errEnvironmentsUnsupportedV2
+}
+
+func (w *workbenchV2) ListEnvironments(ctx context.Context, option *EnvironmentOption) ([]EnvironmentStatus, error) {
+	return nil, errEnvironmentsUnsupportedV2
+}
+
+func (w *workbenchV2) DeleteEnvironment(ctx context.Context, option *EnvironmentOption) (string, error) {
+	return "", errEnvironmentsUnsupportedV2
+}
+
+func (w *workbenchV2) HasOperationDone(ctx context.Context, opName string) (bool, error) {
+	return hasOperationDone(ctx, w.notebookClient.GetOperation, opName)
+}
+
+func (w *workbenchV2) getInstance(ctx context.Context, option *Option) (*notebookspb.Instance, error) {
+	wb, err := w.notebookClient.GetInstance(ctx, &notebookspb.GetInstanceRequest{
+		Name: notebookInstanceFullname(option.ProjectId, option.Zone, option.Name),
+	})
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	return wb, nil
+}
+
+// updateInstance updates the fields of the instance in the mask, v2 API has UpdateInstance instead of SetInstance* methods.
+func (w *workbenchV2) updateInstance(ctx context.Context, option *Option, instance *notebookspb.Instance, paths ...string) (string, error) {
+	instance.Name = notebookInstanceFullname(option.ProjectId, option.Zone, option.Name)
+	op, err := w.notebookClient.UpdateInstance(ctx, &notebookspb.UpdateInstanceRequest{
+		Instance:   instance,
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: paths},
+	})
+	if err != nil {
+		return "", classifyError(err)
+	}
+	return op.Name(), nil
+}
+
+// v1 API と v2 API の列挙型は同じ名前を持つので、名前で変換する
+
+// instanceStateFromProtoV2 converts the state returned from Notebooks v2 API in the same way as instanceStateFromProto.
+func instanceStateFromProtoV2(state notebookspb.State) InstanceState {
+	return instanceStateFromProto(v1pb.Instance_State(v1pb.Instance_State_value[state.String()]))
+}
+
+func diskTypeV2(t v1pb.Instance_DiskType) notebookspb.DiskType {
+	return notebookspb.DiskType(notebookspb.DiskType_value[t.String()])
+}
+
+// acceleratorConfigsV2 returns the accelerator to attach to instance, or nil if it is not specified.
+// Some accelerators of user managed notebooks, e.g. NVIDIA_TESLA_K80 and TPUs, are not supported by Workbench Instances.
+func acceleratorConfigsV2(option *Option) ([]*notebookspb.AcceleratorConfig, error) {
+	if option.AcceleratorType == "" {
+		return nil, nil
+	}
+	v, ok := notebookspb.AcceleratorConfig_AcceleratorType_value[strings.ToUpper(option.AcceleratorType)]
+	if !ok || v == int32(notebookspb.AcceleratorConfig_ACCELERATOR_TYPE_UNSPECIFIED) {
+		return nil, fmt.Errorf("%w: accelerator type %q is not supported by Workbench Instances", ErrInvalidOption, option.AcceleratorType)
+	}
+	return []*notebookspb.AcceleratorConfig{{
+		Type:      notebookspb.AcceleratorConfig_AcceleratorType(v),
+		CoreCount: option.AcceleratorCount,
+	}}, nil
+}
+
+// vmImageV2 returns the VM image to create instance from like Option.vmImage, but Workbench Instances images are used if omitted.
+// Deep Learning VM images used by user managed notebooks cannot be used for Workbench Instances.
+func vmImageV2(option *Option) *notebookspb.VmImage {
+	v2 := &notebookspb.VmImage{Project: option.ImageProject}
+	if v2.Project == "" {
+		v2.Project = DefaultImageProjectV2
+	}
+	switch {
+	case option.ImageName != "":
+		v2.Image = &notebookspb.VmImage_Name{Name: option.ImageName}
+	case option.ImageFamily != "":
+		v2.Image = &notebookspb.VmImage_Family{Family: option.ImageFamily}
+	default:
+		v2.Image = &notebookspb.VmImage_Family{Family: DefaultImageFamilyV2}
+	}
+	return v2
+}
+
+func shieldedInstanceConfigV2(config *v1pb.Instance_ShieldedInstanceConfig) *notebookspb.ShieldedInstanceConfig {
+	if config == nil {
+		return nil
+	}
+	return &notebookspb.ShieldedInstanceConfig{
+		EnableSecureBoot:          config.EnableSecureBoot,
+		EnableVtpm:                config.EnableVtpm,
+		EnableIntegrityMonitoring: config.EnableIntegrityMonitoring,
+	}
+}
+
+func upgradeHistoryFromProtoV2(entries []*notebookspb.UpgradeHistoryEntry) []UpgradeHistoryEntry {
+	if len(entries) == 0 {
+		return nil
+	}
+	history := make([]UpgradeHistoryEntry, 0, len(entries))
+	for _, e := range entries {
+		history = append(history, UpgradeHistoryEntry{
+			Snapshot:      e.GetSnapshot(),
+			Version:       e.GetVersion(),
+			TargetVersion: e.GetTargetVersion(),
+			Action:        e.GetAction().String(),
+			State:         e.GetState().String(),
+			CreateTime:    e.GetCreateTime().AsTime(),
+		})
+	}
+	return history
+}
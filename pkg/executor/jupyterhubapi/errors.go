@@ -0,0 +1,96 @@
+package jupyterhubapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Errors classified from HTTP status codes returned by JupyterHub.
+// Use errors.Is to check them, details are available through HTTPError using errors.As.
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrConflict     = errors.New("conflict")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrServerError  = errors.New("server error")
+)
+
+// HTTPError is returned when JupyterHub responds with unexpected status code.
+type HTTPError struct {
+	// Operation indicates what the executor was trying to do, e.g. "get user"
+	Operation string
+	// StatusCode is the HTTP status code returned from JupyterHub
+	StatusCode int
+	// Message is the error message in the response body if any
+	Message string
+	// RetryAfter is the duration to wait before retrying requested by Retry-After header, zero if not requested
+	RetryAfter time.Duration
+}
+
+func (e *HTTPError) Error() string {
+	msg := fmt.Sprintf("failed to %s: %d %s", e.Operation, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Message != "" {
+		msg = fmt.Sprintf("%s: %s", msg, e.Message)
+	}
+	return msg
+}
+
+func (e *HTTPError) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusForbidden:
+		return ErrForbidden
+	case e.StatusCode == http.StatusConflict:
+		return ErrConflict
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode >= 500:
+		return ErrServerError
+	default:
+		return nil
+	}
+}
+
+// Temporary reports whether the request may succeed when retried later.
+func (e *HTTPError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// newHTTPError builds HTTPError from the response. The body has already been read by the generated client.
+func newHTTPError(operation string, resp *http.Response, body []byte) *HTTPError {
+	e := &HTTPError{
+		Operation:  operation,
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+	// JupyterHub returns errors in {"status": <code>, "message": <message>} format
+	var errBody struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &errBody); err == nil {
+		e.Message = errBody.Message
+	}
+	return e
+}
+
+// parseRetryAfter parses Retry-After header value in either delay-seconds or HTTP-date format.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
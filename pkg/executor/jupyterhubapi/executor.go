@@ -26,7 +26,7 @@ type Option struct {
 type Status struct {
 	Name   string
 	URL    string
-	Status string
+	Status ServerState
 }
 
 // NotebookService is an interface for interacting with Google Cloud Notebooks API
@@ -0,0 +1,345 @@
+package jupyterhubapi
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/toVersus/wbtemporal/pkg/client/jupyterhub"
+)
+
+const (
+	// DefaultFakeSpawnDelay is the time it takes for a fake user server to become ready
+	DefaultFakeSpawnDelay = 10 * time.Second
+	// DefaultFakeStopDelay is the time it takes for a fake user server to be stopped
+	DefaultFakeStopDelay = 5 * time.Second
+	// DefaultFakeBaseURL is the JupyterHub base URL used to generate user server URLs
+	DefaultFakeBaseURL = "http://localhost:8000"
+)
+
+var (
+	_ Executor = &FakeClient{}
+)
+
+// FakeClient is a stateful in-memory implementation of Executor.
+// It models users, named servers and their pending states without running JupyterHub,
+// so that workflows can be developed without Kubernetes.
+type FakeClient struct {
+	mu sync.Mutex
+
+	baseURL    string
+	spawnDelay time.Duration
+	stopDelay  time.Duration
+	now        func() time.Time
+
+	users  map[string]*fakeUser
+	errors map[string]*fakeError
+}
+
+type fakeUser struct {
+	created time.Time
+	servers map[string]*fakeServer
+}
+
+type fakeServer struct {
+	started time.Time
+	pending jupyterhub.ServerPending
+	// readyAt or stoppedAt is the time that pending action finishes
+	readyAt   time.Time
+	stoppedAt time.Time
+}
+
+type fakeError struct {
+	err error
+	// times is the remaining number of calls that fail, negative value means the error never expires
+	times int
+}
+
+// FakeClientOption configures FakeClient.
+type FakeClientOption func(*FakeClient)
+
+// WithFakeBaseURL sets the JupyterHub base URL used to generate user server URLs.
+func WithFakeBaseURL(baseURL string) FakeClientOption {
+	return func(f *FakeClient) {
+		f.baseURL = baseURL
+	}
+}
+
+// WithFakeSpawnDelay sets the time it takes for user servers to become ready.
+func WithFakeSpawnDelay(delay time.Duration) FakeClientOption {
+	return func(f *FakeClient) {
+		f.spawnDelay = delay
+	}
+}
+
+// WithFakeStopDelay sets the time it takes for user servers to be stopped.
+func WithFakeStopDelay(delay time.Duration) FakeClientOption {
+	return func(f *FakeClient) {
+		f.stopDelay = delay
+	}
+}
+
+// WithFakeClock replaces the clock used to decide whether pending actions have finished.
+func WithFakeClock(now func() time.Time) FakeClientOption {
+	return func(f *FakeClient) {
+		f.now = now
+	}
+}
+
+func NewFakeClient(opts ...FakeClientOption) *FakeClient {
+	f := &FakeClient{
+		baseURL:    DefaultFakeBaseURL,
+		spawnDelay: DefaultFakeSpawnDelay,
+		stopDelay:  DefaultFakeStopDelay,
+		now:        time.Now,
+		users:      map[string]*fakeUser{},
+		errors:     map[string]*fakeError{},
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+	return f
+}
+
+// InjectError makes the next `times` calls of the method return err.
+// The method is the name of the Executor method, e.g. "CreateUserServer".
+// If times is zero or negative, the method keeps failing until ClearErrors is called.
+// Inject *HTTPError to simulate error responses from JupyterHub, e.g. &HTTPError{Operation: "create server", StatusCode: http.StatusTooManyRequests, RetryAfter: time.Second}.
+func (f *FakeClient) InjectError(method string, err error, times int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if times <= 0 {
+		times = -1
+	}
+	f.errors[method] = &fakeError{err: err, times: times}
+}
+
+// ClearErrors removes all injected errors.
+func (f *FakeClient) ClearErrors() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.errors = map[string]*fakeError{}
+}
+
+func (f *FakeClient) GetUser(ctx context.Context, option *Option) (*jupyterhub.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("GetUser"); err != nil {
+		return nil, err
+	}
+
+	user, ok := f.users[option.User]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	return f.user(option.User, user), nil
+}
+
+func (f *FakeClient) CreateUser(ctx context.Context, option *Option) (*jupyterhub.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("CreateUser"); err != nil {
+		return nil, err
+	}
+
+	if _, ok := f.users[option.User]; ok {
+		return nil, fmt.Errorf("failed to create user: user %s already exists", option.User)
+	}
+	user := &fakeUser{
+		created: f.now(),
+		servers: map[string]*fakeServer{},
+	}
+	f.users[option.User] = user
+	return f.user(option.User, user), nil
+}
+
+func (f *FakeClient) GetUserServer(ctx context.Context, option *Option) (*Status, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("GetUserServer"); err != nil {
+		return nil, err
+	}
+
+	user, ok := f.users[option.User]
+	if !ok {
+		return nil, fmt.Errorf("failed to get or create user: %v", ErrUserNotFound)
+	}
+	server, ok := user.servers[option.Server]
+	if !ok {
+		return nil, ErrServerNotFound
+	}
+	return serverStatus(f.baseURL, option.Server, f.server(option.User, option.Server, server))
+}
+
+func (f *FakeClient) CreateUserServer(ctx context.Context, option *Option) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("CreateUserServer"); err != nil {
+		return err
+	}
+
+	user, ok := f.users[option.User]
+	if !ok {
+		return ErrUserNotFound
+	}
+	if server, ok := user.servers[option.Server]; ok {
+		if server.pending == jupyterhub.ServerPendingStop {
+			return fmt.Errorf("failed to create server: server %s is pending stop", option.Server)
+		}
+		// server is already ready or spawning
+		return nil
+	}
+
+	now := f.now()
+	user.servers[option.Server] = &fakeServer{
+		started: now,
+		pending: jupyterhub.ServerPendingSpawn,
+		readyAt: now.Add(f.spawnDelay),
+	}
+	return nil
+}
+
+func (f *FakeClient) DeleteUserServer(ctx context.Context, option *Option) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("DeleteUserServer"); err != nil {
+		return err
+	}
+
+	user, ok := f.users[option.User]
+	if !ok {
+		return ErrUserNotFound
+	}
+	server, ok := user.servers[option.Server]
+	if !ok {
+		// server is already stopped
+		return nil
+	}
+	switch server.pending {
+	case jupyterhub.ServerPendingSpawn:
+		return fmt.Errorf("failed to delete server: server %s is pending spawn", option.Server)
+	case jupyterhub.ServerPendingStop:
+		return nil
+	}
+	server.pending = jupyterhub.ServerPendingStop
+	server.stoppedAt = f.now().Add(f.stopDelay)
+	return nil
+}
+
+func (f *FakeClient) IsUserServerReady(ctx context.Context, option *Option) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("IsUserServerReady"); err != nil {
+		return false, err
+	}
+
+	user, ok := f.users[option.User]
+	if !ok {
+		return false, ErrUserNotFound
+	}
+	server, ok := user.servers[option.Server]
+	if !ok {
+		return false, fmt.Errorf("server %s not found", option.Server)
+	}
+	return server.pending == "", nil
+}
+
+func (f *FakeClient) IsUserServerDeleted(ctx context.Context, option *Option) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reconcile()
+
+	if err := f.injectedError("IsUserServerDeleted"); err != nil {
+		return false, err
+	}
+
+	user, ok := f.users[option.User]
+	if !ok {
+		return false, ErrUserNotFound
+	}
+	_, ok = user.servers[option.Server]
+	return !ok, nil
+}
+
+// reconcile finishes all pending actions whose deadline has passed. The caller must hold f.mu.
+func (f *FakeClient) reconcile() {
+	now := f.now()
+	for _, user := range f.users {
+		for name, server := range user.servers {
+			switch server.pending {
+			case jupyterhub.ServerPendingSpawn:
+				if !now.Before(server.readyAt) {
+					server.pending = ""
+				}
+			case jupyterhub.ServerPendingStop:
+				if !now.Before(server.stoppedAt) {
+					// stopped servers are not included in the user model by default
+					delete(user.servers, name)
+				}
+			}
+		}
+	}
+}
+
+// user converts the fake user into the JupyterHub user model. The caller must hold f.mu.
+func (f *FakeClient) user(name string, user *fakeUser) *jupyterhub.User {
+	servers := map[string]jupyterhub.Server{}
+	for serverName, server := range user.servers {
+		servers[serverName] = f.server(name, serverName, server)
+	}
+	admin := false
+	return &jupyterhub.User{
+		Admin:        &admin,
+		Name:         &name,
+		LastActivity: &user.created,
+		Servers:      &servers,
+	}
+}
+
+// server converts the fake server into the JupyterHub server model. The caller must hold f.mu.
+func (f *FakeClient) server(userName, serverName string, server *fakeServer) jupyterhub.Server {
+	ready := server.pending == ""
+	stopped := false
+	url := fmt.Sprintf("/user/%s/%s/", userName, serverName)
+	started := server.started
+	s := jupyterhub.Server{
+		Name:    &serverName,
+		Ready:   &ready,
+		Stopped: &stopped,
+		Started: &started,
+		Url:     &url,
+	}
+	if server.pending != "" {
+		pending := server.pending
+		s.Pending = &pending
+	}
+	return s
+}
+
+// injectedError returns the error injected into the method if any. The caller must hold f.mu.
+func (f *FakeClient) injectedError(method string) error {
+	e, ok := f.errors[method]
+	if !ok {
+		return nil
+	}
+	if e.times > 0 {
+		e.times--
+		if e.times == 0 {
+			delete(f.errors, method)
+		}
+	}
+	return e.err
+}
@@ -2,29 +2,21 @@ package jupyterhubapi
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"net/http"
 	"net/url"
 
 	"github.com/toVersus/wbtemporal/pkg/client/jupyterhub"
 )
 
-const (
-	UserServerStatusReady   = "Ready"
-	UserServerStatusPending = "Pending"
-	UserServerStatusStopped = "Stopped"
-)
-
 var (
 	ErrUserNotFound   = errors.New("user not found")
 	ErrServerNotFound = errors.New("server not found")
 )
 
 type notebook struct {
-	*jupyterhub.Client
+	*jupyterhub.ClientWithResponses
 	baseURL    string
 	apiBaseURL string
 }
@@ -35,7 +27,7 @@ func NewExecutor(ctx context.Context, baseURL, token string) (Executor, error) {
 		return nil, fmt.Errorf("failed to generate JupyterHub API base URL: %v", err)
 	}
 
-	client, err := jupyterhub.NewClient(apiBaseURL, jupyterhub.WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
+	client, err := jupyterhub.NewClientWithResponses(apiBaseURL, jupyterhub.WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
 		req.Header.Set("Authorization", fmt.Sprintf("token %s", token))
 		return nil
 	}))
@@ -43,82 +35,45 @@ func NewExecutor(ctx context.Context, baseURL, token string) (Executor, error) {
 		return nil, fmt.Errorf("failed to create JupyterHub API client: %w", err)
 	}
 
-	return &notebook{Client: client, baseURL: baseURL, apiBaseURL: apiBaseURL}, nil
+	return &notebook{ClientWithResponses: client, baseURL: baseURL, apiBaseURL: apiBaseURL}, nil
 }
 
 func (n *notebook) GetUser(ctx context.Context, option *Option) (*jupyterhub.User, error) {
-	resp, err := n.GetUsersName(ctx, option.User)
+	resp, err := n.GetUsersNameWithResponse(ctx, option.User)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user: %v", err)
+		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode == http.StatusNotFound {
+	if resp.StatusCode() == http.StatusNotFound {
 		return nil, ErrUserNotFound
-	} else if resp.StatusCode > 299 && resp.StatusCode < 200 {
-		return nil, fmt.Errorf("unexpected status code returned from getting user: %s", resp.Status)
-	}
-
-	result, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %v", err)
 	}
-	var user jupyterhub.User
-	if err := json.Unmarshal(result, &user); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response body: %v", err)
+	if resp.JSON200 == nil {
+		return nil, newHTTPError("get user", resp.HTTPResponse, resp.Body)
 	}
-	return &user, nil
+	return resp.JSON200, nil
 }
 
 func (n *notebook) CreateUser(ctx context.Context, option *Option) (*jupyterhub.User, error) {
-	resp, err := n.PostUsersName(ctx, option.User)
+	resp, err := n.PostUsersNameWithResponse(ctx, option.User)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create user: %v", err)
-	}
-	if resp.StatusCode > 299 && resp.StatusCode < 200 {
-		return nil, fmt.Errorf("unexpected status code returned from creating user: %v", resp.Status)
+		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
-
-	result, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %v", err)
+	if resp.JSON201 == nil {
+		return nil, newHTTPError("create user", resp.HTTPResponse, resp.Body)
 	}
-	var user jupyterhub.User
-	if err := json.Unmarshal(result, &user); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response body: %v", err)
-	}
-	return &user, nil
+	return resp.JSON201, nil
 }
 
 func (n *notebook) GetUserServer(ctx context.Context, option *Option) (*Status, error) {
 	user, err := n.GetUser(ctx, option)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get or create user: %v", err)
+		return nil, fmt.Errorf("failed to get or create user: %w", err)
 	}
 
-	for name, server := range *user.Servers {
-		if name != option.Server {
-			continue
-		}
-		var status string
-		if *server.Ready {
-			status = UserServerStatusReady
-		} else if *server.Pending == jupyterhub.ServerPendingSpawn || *server.Pending == jupyterhub.ServerPendingStop {
-			status = UserServerStatusPending
-		} else if *server.Stopped {
-			status = UserServerStatusStopped
-		}
-		serverURL, err := url.JoinPath(n.baseURL, *server.Url)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate server URL: %v", err)
-		}
-
-		return &Status{
-			Name:   name,
-			URL:    serverURL,
-			Status: status,
-		}, nil
+	server, ok := userServer(user, option.Server)
+	if !ok {
+		return nil, ErrServerNotFound
 	}
-	return nil, ErrServerNotFound
+	return serverStatus(n.baseURL, option.Server, server)
 }
 
 func (n *notebook) CreateUserServer(ctx context.Context, option *Option) error {
@@ -126,24 +81,24 @@ func (n *notebook) CreateUserServer(ctx context.Context, option *Option) error {
 	if err != nil {
 		return err
 	}
-	servers := *user.Servers
-	if len(servers) != 0 {
-		for _, server := range servers {
-			if *server.Name == option.Server && *server.Ready {
-				return nil
-			}
+	if server, ok := userServer(user, option.Server); ok {
+		switch serverStateFromModel(server) {
+		case ServerStateReady, ServerStateSpawning:
+			return nil
+		case ServerStateStopping:
+			// JupyterHub は停止中のサーバの起動リクエストを 400 で拒否するので、停止を待ってからリトライさせる
+			return fmt.Errorf("server %s is pending stop", option.Server)
 		}
 	}
 
-	resp, err := n.PostUsersNameServersServerName(ctx, option.User, option.Server, nil)
+	resp, err := n.PostUsersNameServersServerNameWithResponse(ctx, option.User, option.Server, jupyterhub.PostUsersNameServersServerNameJSONRequestBody{})
 	if err != nil {
-		return fmt.Errorf("failed to create server: %v", err)
+		return fmt.Errorf("failed to create server: %w", err)
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode > 299 && resp.StatusCode < 200 {
-		return fmt.Errorf("failed to create server: %v", resp.Status)
+	// 201 は起動済み、202 は起動中を表す
+	if resp.StatusCode() != http.StatusCreated && resp.StatusCode() != http.StatusAccepted {
+		return newHTTPError("create server", resp.HTTPResponse, resp.Body)
 	}
-
 	return nil
 }
 
@@ -152,25 +107,27 @@ func (n *notebook) DeleteUserServer(ctx context.Context, option *Option) error {
 	if err != nil {
 		return err
 	}
-	servers := *user.Servers
-	if len(servers) != 0 {
-		for _, server := range servers {
-			if *server.Name == option.Server && *server.Stopped {
-				return nil
-			}
+	if server, ok := userServer(user, option.Server); ok {
+		switch serverStateFromModel(server) {
+		case ServerStateStopped, ServerStateStopping:
+			return nil
+		case ServerStateSpawning:
+			// JupyterHub は起動中のサーバの停止リクエストを 400 で拒否するので、起動を待ってからリトライさせる
+			return fmt.Errorf("server %s is pending spawn", option.Server)
 		}
 	}
 
-	resp, err := n.DeleteUsersNameServersServerName(ctx, option.User, option.Server)
+	resp, err := n.DeleteUsersNameServersServerNameWithResponse(ctx, option.User, option.Server)
 	if err != nil {
-		return fmt.Errorf("failed to delete server: %v", err)
+		return fmt.Errorf("failed to delete server: %w", err)
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode > 299 && resp.StatusCode < 200 {
-		return fmt.Errorf("failed to delete server: %v", resp.Status)
+	// 204 は停止済み、202 は停止中を表し、404 はすでに削除済みなので成功とみなす
+	switch resp.StatusCode() {
+	case http.StatusNoContent, http.StatusAccepted, http.StatusNotFound:
+		return nil
+	default:
+		return newHTTPError("delete server", resp.HTTPResponse, resp.Body)
 	}
-
-	return nil
 }
 
 func (n *notebook) IsUserServerReady(ctx context.Context, option *Option) (bool, error) {
@@ -179,13 +136,11 @@ func (n *notebook) IsUserServerReady(ctx context.Context, option *Option) (bool,
 		return false, err
 	}
 
-	for name, server := range *user.Servers {
-		if name != option.Server {
-			continue
-		}
-		return *server.Ready, nil
+	server, ok := userServer(user, option.Server)
+	if !ok {
+		return false, fmt.Errorf("server %s not found", option.Server)
 	}
-	return false, fmt.Errorf("server %s not found", option.Server)
+	return serverStateFromModel(server).IsRunning(), nil
 }
 
 func (n *notebook) IsUserServerDeleted(ctx context.Context, option *Option) (bool, error) {
@@ -194,12 +149,38 @@ func (n *notebook) IsUserServerDeleted(ctx context.Context, option *Option) (boo
 		return false, err
 	}
 
-	deleted := true
-	for name, _ := range *user.Servers {
-		if name == option.Server {
-			deleted = false
-			continue
-		}
+	_, ok := userServer(user, option.Server)
+	return !ok, nil
+}
+
+// userServer returns the named server of the user. The servers field is optional in the API spec,
+// e.g. omitted for users without the permission to read servers, so the missing field is treated as no servers.
+func userServer(user *jupyterhub.User, name string) (jupyterhub.Server, bool) {
+	if user.Servers == nil {
+		return jupyterhub.Server{}, false
+	}
+	server, ok := (*user.Servers)[name]
+	return server, ok
+}
+
+func isPending(server jupyterhub.Server, pending jupyterhub.ServerPending) bool {
+	return server.Pending != nil && *server.Pending == pending
+}
+
+func serverStatus(baseURL, name string, server jupyterhub.Server) (*Status, error) {
+	// URL はサーバの起動が要求されるまで返されないことがある
+	var path string
+	if server.Url != nil {
+		path = *server.Url
 	}
-	return deleted, nil
+	serverURL, err := url.JoinPath(baseURL, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate server URL: %v", err)
+	}
+
+	return &Status{
+		Name:   name,
+		URL:    serverURL,
+		Status: serverStateFromModel(server),
+	}, nil
 }
@@ -0,0 +1,56 @@
+package jupyterhubapi
+
+import (
+	"github.com/toVersus/wbtemporal/pkg/client/jupyterhub"
+)
+
+// ServerState is the state of JupyterHub user server, derived from ready, pending and stopped fields of the server model.
+type ServerState string
+
+const (
+	ServerStateUnknown  ServerState = "Unknown"
+	ServerStateReady    ServerState = "Ready"
+	ServerStateSpawning ServerState = "Spawning"
+	ServerStateStopping ServerState = "Stopping"
+	ServerStateStopped  ServerState = "Stopped"
+)
+
+const (
+	// Deprecated: Use ServerStateReady instead.
+	UserServerStatusReady = ServerStateReady
+	// Deprecated: Pending servers are now reported as ServerStateSpawning or ServerStateStopping.
+	UserServerStatusPending ServerState = "Pending"
+	// Deprecated: Use ServerStateStopped instead.
+	UserServerStatusStopped = ServerStateStopped
+)
+
+// serverStateFromModel converts the server returned from JupyterHub REST API.
+func serverStateFromModel(server jupyterhub.Server) ServerState {
+	switch {
+	case server.Ready != nil && *server.Ready:
+		return ServerStateReady
+	case isPending(server, jupyterhub.ServerPendingSpawn):
+		return ServerStateSpawning
+	case isPending(server, jupyterhub.ServerPendingStop):
+		return ServerStateStopping
+	case server.Stopped != nil && *server.Stopped:
+		return ServerStateStopped
+	default:
+		return ServerStateUnknown
+	}
+}
+
+// IsRunning reports whether the user server is ready to be accessed.
+func (s ServerState) IsRunning() bool {
+	return s == ServerStateReady
+}
+
+// IsTransitional reports whether the user server is being spawned or stopped, so that callers should wait for it.
+func (s ServerState) IsTransitional() bool {
+	return s == ServerStateSpawning || s == ServerStateStopping
+}
+
+// IsStopped reports whether the user server is not running and stays so until it is spawned again.
+func (s ServerState) IsStopped() bool {
+	return s == ServerStateStopped
+}
@@ -0,0 +1,567 @@
+package fakehub
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/toVersus/wbtemporal/pkg/client/jupyterhub"
+)
+
+var (
+	_ jupyterhub.ServerInterface = &handler{}
+)
+
+// handler implements the JupyterHub REST API on top of the in-memory state of Server.
+// Endpoints outside users, servers, tokens and groups respond with 501 Not Implemented.
+type handler struct {
+	s *Server
+}
+
+func (h *handler) Get(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, map[string]string{"version": Version})
+}
+
+func (h *handler) GetInfo(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, map[string]interface{}{
+		"version": Version,
+		"spawner": map[string]string{"class": "fakehub.Spawner", "version": Version},
+	})
+}
+
+func (h *handler) GetUsers(ctx echo.Context, params jupyterhub.GetUsersParams) error {
+	h.s.mu.Lock()
+	defer h.s.mu.Unlock()
+	h.s.reconcile()
+
+	includeStoppedServers := params.IncludeStoppedServers != nil && *params.IncludeStoppedServers
+	users := []jupyterhub.User{}
+	for _, u := range h.s.sortedUsers() {
+		if params.State != nil && !matchState(u, *params.State) {
+			continue
+		}
+		users = append(users, h.s.userModel(u, includeStoppedServers))
+	}
+	return ctx.JSON(http.StatusOK, paginate(users, params.Offset, params.Limit))
+}
+
+func (h *handler) PostUsers(ctx echo.Context) error {
+	var body jupyterhub.PostUsersJSONRequestBody
+	if err := bindJSON(ctx, &body); err != nil {
+		return err
+	}
+	if body.Usernames == nil || len(*body.Usernames) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "Must specify at least one user to create")
+	}
+
+	h.s.mu.Lock()
+	defer h.s.mu.Unlock()
+	h.s.reconcile()
+
+	admin := body.Admin != nil && *body.Admin
+	users := []jupyterhub.User{}
+	for _, name := range *body.Usernames {
+		if _, ok := h.s.users[name]; ok {
+			continue
+		}
+		u := h.s.newUser(name, admin)
+		h.s.users[name] = u
+		users = append(users, h.s.userModel(u, false))
+	}
+	if len(users) == 0 {
+		return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("All %d users already exist", len(*body.Usernames)))
+	}
+	return ctx.JSON(http.StatusCreated, users)
+}
+
+func (h *handler) GetUser(ctx echo.Context) error {
+	return notImplemented(ctx)
+}
+
+func (h *handler) GetUsersName(ctx echo.Context, name string) error {
+	h.s.mu.Lock()
+	defer h.s.mu.Unlock()
+	h.s.reconcile()
+
+	u, err := h.s.lookupUser(name)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, h.s.userModel(u, false))
+}
+
+func (h *handler) PostUsersName(ctx echo.Context, name string) error {
+	h.s.mu.Lock()
+	defer h.s.mu.Unlock()
+	h.s.reconcile()
+
+	if _, ok := h.s.users[name]; ok {
+		return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("User %s already exists", name))
+	}
+	u := h.s.newUser(name, false)
+	h.s.users[name] = u
+	return ctx.JSON(http.StatusCreated, h.s.userModel(u, false))
+}
+
+func (h *handler) PatchUsersName(ctx echo.Context, name string) error {
+	var body jupyterhub.PatchUsersNameJSONRequestBody
+	if err := bindJSON(ctx, &body); err != nil {
+		return err
+	}
+
+	h.s.mu.Lock()
+	defer h.s.mu.Unlock()
+	h.s.reconcile()
+
+	u, err := h.s.lookupUser(name)
+	if err != nil {
+		return err
+	}
+	if body.Name != nil && *body.Name != name {
+		if _, ok := h.s.users[*body.Name]; ok {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("User %s already exists, username must be unique", *body.Name))
+		}
+		delete(h.s.users, name)
+		u.name = *body.Name
+		h.s.users[u.name] = u
+		for _, g := range h.s.groups {
+			for i, member := range g.users {
+				if member == name {
+					g.users[i] = u.name
+				}
+			}
+		}
+	}
+	if body.Admin != nil {
+		u.admin = *body.Admin
+	}
+	return ctx.JSON(http.StatusOK, h.s.userModel(u, false))
+}
+
+func (h *handler) DeleteUsersName(ctx echo.Context, name string) error {
+	h.s.mu.Lock()
+	defer h.s.mu.Unlock()
+	h.s.reconcile()
+
+	u, err := h.s.lookupUser(name)
+	if err != nil {
+		return err
+	}
+	for _, sv := range u.servers {
+		if sv.pending != "" {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("%s's server %q is pending %s, please wait", name, sv.name, sv.pending))
+		}
+	}
+	delete(h.s.users, name)
+	for _, g := range h.s.groups {
+		g.users = removeMember(g.users, name)
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) PostUsersNameActivity(ctx echo.Context, name string) error {
+	h.s.mu.Lock()
+	defer h.s.mu.Unlock()
+
+	u, err := h.s.lookupUser(name)
+	if err != nil {
+		return err
+	}
+	u.lastActivity = h.s.now()
+	return ctx.NoContent(http.StatusOK)
+}
+
+func (h *handler) PostUsersNameServer(ctx echo.Context, name string) error {
+	return h.startServer(ctx, name, "")
+}
+
+func (h *handler) DeleteUsersNameServer(ctx echo.Context, name string) error {
+	return h.stopServer(ctx, name, "")
+}
+
+func (h *handler) PostUsersNameServersServerName(ctx echo.Context, name string, serverName string) error {
+	return h.startServer(ctx, name, serverName)
+}
+
+func (h *handler) DeleteUsersNameServersServerName(ctx echo.Context, name string, serverName string) error {
+	return h.stopServer(ctx, name, serverName)
+}
+
+func (h *handler) startServer(ctx echo.Context, name, serverName string) error {
+	var userOptions map[string]interface{}
+	if err := bindJSON(ctx, &userOptions); err != nil {
+		return err
+	}
+
+	h.s.mu.Lock()
+	defer h.s.mu.Unlock()
+	h.s.reconcile()
+
+	u, err := h.s.lookupUser(name)
+	if err != nil {
+		return err
+	}
+	if sv, ok := u.servers[serverName]; ok && sv.active() {
+		if sv.pending != "" {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("%s:%s is pending %s", name, serverName, sv.pending))
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("%s:%s is already running", name, serverName))
+	}
+
+	now := h.s.now()
+	sv := &server{
+		name:        serverName,
+		started:     now,
+		userOptions: userOptions,
+	}
+	u.servers[serverName] = sv
+	if h.s.spawnDelay == 0 {
+		return ctx.NoContent(http.StatusCreated)
+	}
+	sv.pending = jupyterhub.ServerPendingSpawn
+	sv.readyAt = now.Add(h.s.spawnDelay)
+	return ctx.NoContent(http.StatusAccepted)
+}
+
+func (h *handler) stopServer(ctx echo.Context, name, serverName string) error {
+	h.s.mu.Lock()
+	defer h.s.mu.Unlock()
+	h.s.reconcile()
+
+	u, err := h.s.lookupUser(name)
+	if err != nil {
+		return err
+	}
+	sv, ok := u.servers[serverName]
+	if !ok {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("%s has no server named '%s'", name, serverName))
+	}
+	switch {
+	case sv.stopped:
+		return ctx.NoContent(http.StatusNoContent)
+	case sv.pending == jupyterhub.ServerPendingStop:
+		return ctx.NoContent(http.StatusAccepted)
+	case sv.pending == jupyterhub.ServerPendingSpawn:
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("%s:%s is pending spawn, please wait", name, serverName))
+	}
+
+	if h.s.stopDelay == 0 {
+		sv.stopped = true
+		return ctx.NoContent(http.StatusNoContent)
+	}
+	sv.pending = jupyterhub.ServerPendingStop
+	sv.stoppedAt = h.s.now().Add(h.s.stopDelay)
+	return ctx.NoContent(http.StatusAccepted)
+}
+
+func (h *handler) GetUsersNameTokens(ctx echo.Context, name string) error {
+	h.s.mu.Lock()
+	defer h.s.mu.Unlock()
+
+	u, err := h.s.lookupUser(name)
+	if err != nil {
+		return err
+	}
+	tokens := []jupyterhub.Token{}
+	for _, t := range u.tokens {
+		tokens = append(tokens, tokenModel(u.name, t, false))
+	}
+	return ctx.JSON(http.StatusOK, tokens)
+}
+
+func (h *handler) PostUsersNameTokens(ctx echo.Context, name string) error {
+	var body jupyterhub.PostUsersNameTokensJSONRequestBody
+	if err := bindJSON(ctx, &body); err != nil {
+		return err
+	}
+
+	h.s.mu.Lock()
+	defer h.s.mu.Unlock()
+
+	u, err := h.s.lookupUser(name)
+	if err != nil {
+		return err
+	}
+	if body.Roles != nil && len(*body.Roles) != 0 {
+		return echo.NewHTTPError(http.StatusForbidden, "Requested role does not exist")
+	}
+	note := "Requested via api"
+	if body.Note != nil {
+		note = fmt.Sprintf("%s: %s", note, *body.Note)
+	}
+	scopes := []string{"inherit"}
+	if body.Scopes != nil {
+		scopes = *body.Scopes
+	}
+	var expiresIn time.Duration
+	if body.ExpiresIn != nil {
+		expiresIn = time.Duration(*body.ExpiresIn) * time.Second
+	}
+	t, err := h.s.newToken(u, note, scopes, expiresIn)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusCreated, tokenModel(u.name, t, true))
+}
+
+func (h *handler) GetUsersNameTokensTokenId(ctx echo.Context, name string, tokenId string) error {
+	h.s.mu.Lock()
+	defer h.s.mu.Unlock()
+
+	u, err := h.s.lookupUser(name)
+	if err != nil {
+		return err
+	}
+	t, ok := u.tokens[tokenId]
+	if !ok {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("No such token: %s", tokenId))
+	}
+	return ctx.JSON(http.StatusOK, tokenModel(u.name, t, false))
+}
+
+func (h *handler) DeleteUsersNameTokensTokenId(ctx echo.Context, name string, tokenId string) error {
+	h.s.mu.Lock()
+	defer h.s.mu.Unlock()
+
+	u, err := h.s.lookupUser(name)
+	if err != nil {
+		return err
+	}
+	if _, ok := u.tokens[tokenId]; !ok {
+		return echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("No such token: %s", tokenId))
+	}
+	delete(u.tokens, tokenId)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) GetGroups(ctx echo.Context, params jupyterhub.GetGroupsParams) error {
+	h.s.mu.Lock()
+	defer h.s.mu.Unlock()
+
+	groups := []jupyterhub.Group{}
+	for _, g := range h.s.sortedGroups() {
+		groups = append(groups, groupModel(g))
+	}
+	return ctx.JSON(http.StatusOK, paginate(groups, params.Offset, params.Limit))
+}
+
+func (h *handler) GetGroupsName(ctx echo.Context, name string) error {
+	h.s.mu.Lock()
+	defer h.s.mu.Unlock()
+
+	g, err := h.s.lookupGroup(name)
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, groupModel(g))
+}
+
+func (h *handler) PostGroupsName(ctx echo.Context, name string) error {
+	h.s.mu.Lock()
+	defer h.s.mu.Unlock()
+
+	if _, ok := h.s.groups[name]; ok {
+		return echo.NewHTTPError(http.StatusConflict, fmt.Sprintf("Group %s already exists", name))
+	}
+	g := &group{name: name, users: []string{}, properties: map[string]interface{}{}}
+	h.s.groups[name] = g
+	return ctx.JSON(http.StatusCreated, groupModel(g))
+}
+
+func (h *handler) DeleteGroupsName(ctx echo.Context, name string) error {
+	h.s.mu.Lock()
+	defer h.s.mu.Unlock()
+
+	if _, err := h.s.lookupGroup(name); err != nil {
+		return err
+	}
+	delete(h.s.groups, name)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (h *handler) PostGroupsNameUsers(ctx echo.Context, name string) error {
+	var body jupyterhub.PostGroupsNameUsersJSONRequestBody
+	if err := bindJSON(ctx, &body); err != nil {
+		return err
+	}
+
+	h.s.mu.Lock()
+	defer h.s.mu.Unlock()
+
+	g, err := h.s.lookupGroup(name)
+	if err != nil {
+		return err
+	}
+	if body.Users == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Must specify users to add")
+	}
+	for _, member := range *body.Users {
+		if _, err := h.s.lookupUser(member); err != nil {
+			return err
+		}
+		g.users = append(removeMember(g.users, member), member)
+	}
+	return ctx.JSON(http.StatusOK, groupModel(g))
+}
+
+func (h *handler) DeleteGroupsNameUsers(ctx echo.Context, name string) error {
+	// The spec doesn't declare request body on DELETE, but JupyterHub reads users to remove from it
+	var body jupyterhub.PostGroupsNameUsersJSONBody
+	if err := bindJSON(ctx, &body); err != nil {
+		return err
+	}
+
+	h.s.mu.Lock()
+	defer h.s.mu.Unlock()
+
+	g, err := h.s.lookupGroup(name)
+	if err != nil {
+		return err
+	}
+	if body.Users == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Must specify users to remove")
+	}
+	for _, member := range *body.Users {
+		g.users = removeMember(g.users, member)
+	}
+	return ctx.NoContent(http.StatusOK)
+}
+
+func (h *handler) PutGroupsNameProperties(ctx echo.Context, name string) error {
+	var body jupyterhub.PutGroupsNamePropertiesJSONRequestBody
+	if err := bindJSON(ctx, &body); err != nil {
+		return err
+	}
+
+	h.s.mu.Lock()
+	defer h.s.mu.Unlock()
+
+	g, err := h.s.lookupGroup(name)
+	if err != nil {
+		return err
+	}
+	g.properties = body
+	return ctx.JSON(http.StatusOK, groupModel(g))
+}
+
+func (h *handler) GetAuthorizationsCookieCookieNameCookieValue(ctx echo.Context, cookieName string, cookieValue string) error {
+	return notImplemented(ctx)
+}
+
+func (h *handler) PostAuthorizationsToken(ctx echo.Context) error {
+	return notImplemented(ctx)
+}
+
+func (h *handler) GetAuthorizationsTokenToken(ctx echo.Context, token string) error {
+	return notImplemented(ctx)
+}
+
+func (h *handler) GetOauth2Authorize(ctx echo.Context, params jupyterhub.GetOauth2AuthorizeParams) error {
+	return notImplemented(ctx)
+}
+
+func (h *handler) PostOauth2Token(ctx echo.Context) error {
+	return notImplemented(ctx)
+}
+
+func (h *handler) GetProxy(ctx echo.Context, params jupyterhub.GetProxyParams) error {
+	return notImplemented(ctx)
+}
+
+func (h *handler) PatchProxy(ctx echo.Context) error {
+	return notImplemented(ctx)
+}
+
+func (h *handler) PostProxy(ctx echo.Context) error {
+	return notImplemented(ctx)
+}
+
+func (h *handler) GetServices(ctx echo.Context) error {
+	return notImplemented(ctx)
+}
+
+func (h *handler) GetServicesName(ctx echo.Context, name string) error {
+	return notImplemented(ctx)
+}
+
+func (h *handler) PostShutdown(ctx echo.Context) error {
+	return notImplemented(ctx)
+}
+
+// lookupUser returns the user or 404 error. The caller must hold s.mu.
+func (s *Server) lookupUser(name string) (*user, error) {
+	u, ok := s.users[name]
+	if !ok {
+		return nil, echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("No such user: %s", name))
+	}
+	return u, nil
+}
+
+// lookupGroup returns the group or 404 error. The caller must hold s.mu.
+func (s *Server) lookupGroup(name string) (*group, error) {
+	g, ok := s.groups[name]
+	if !ok {
+		return nil, echo.NewHTTPError(http.StatusNotFound, fmt.Sprintf("No such group: %s", name))
+	}
+	return g, nil
+}
+
+func matchState(u *user, state jupyterhub.GetUsersParamsState) bool {
+	var active, ready bool
+	for _, sv := range u.servers {
+		active = active || sv.active()
+		ready = ready || sv.ready()
+	}
+	switch state {
+	case jupyterhub.Active:
+		return active
+	case jupyterhub.Ready:
+		return ready
+	case jupyterhub.Inactive:
+		return !active
+	}
+	return true
+}
+
+func paginate[T any](items []T, offset, limit *float32) []T {
+	if offset != nil {
+		o := int(*offset)
+		if o > len(items) {
+			o = len(items)
+		}
+		items = items[o:]
+	}
+	if limit != nil && int(*limit) < len(items) {
+		items = items[:int(*limit)]
+	}
+	return items
+}
+
+func removeMember(members []string, name string) []string {
+	result := []string{}
+	for _, member := range members {
+		if member != name {
+			result = append(result, member)
+		}
+	}
+	return result
+}
+
+// bindJSON decodes the JSON request body into v, an empty body leaves v untouched.
+func bindJSON(ctx echo.Context, v interface{}) error {
+	body, err := readBody(ctx)
+	if err != nil || body == nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid JSON in body of request: %v", err))
+	}
+	return nil
+}
+
+func notImplemented(ctx echo.Context) error {
+	return echo.NewHTTPError(http.StatusNotImplemented, fmt.Sprintf("%s %s is not implemented by fake hub", ctx.Request().Method, ctx.Path()))
+}
@@ -0,0 +1,246 @@
+package fakehub
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/toVersus/wbtemporal/pkg/client/jupyterhub"
+)
+
+type user struct {
+	name         string
+	admin        bool
+	created      time.Time
+	lastActivity time.Time
+	servers      map[string]*server
+	tokens       map[string]*token
+}
+
+type server struct {
+	name        string
+	started     time.Time
+	pending     jupyterhub.ServerPending
+	stopped     bool
+	userOptions map[string]interface{}
+	// readyAt or stoppedAt is the time that pending action finishes
+	readyAt   time.Time
+	stoppedAt time.Time
+}
+
+type token struct {
+	id        string
+	token     string
+	note      string
+	scopes    []string
+	created   time.Time
+	expiresAt *time.Time
+}
+
+type group struct {
+	name       string
+	users      []string
+	properties map[string]interface{}
+}
+
+// newUser returns new user without any servers. The caller must hold s.mu.
+func (s *Server) newUser(name string, admin bool) *user {
+	now := s.now()
+	return &user{
+		name:         name,
+		admin:        admin,
+		created:      now,
+		lastActivity: now,
+		servers:      map[string]*server{},
+		tokens:       map[string]*token{},
+	}
+}
+
+// newToken issues new API token for the user. The caller must hold s.mu.
+func (s *Server) newToken(u *user, note string, scopes []string, expiresIn time.Duration) (*token, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+	s.seq++
+	t := &token{
+		id:      fmt.Sprintf("a%d", s.seq),
+		token:   hex.EncodeToString(b),
+		note:    note,
+		scopes:  scopes,
+		created: s.now(),
+	}
+	if expiresIn > 0 {
+		expiresAt := t.created.Add(expiresIn)
+		t.expiresAt = &expiresAt
+	}
+	u.tokens[t.id] = t
+	return t, nil
+}
+
+// reconcile finishes all pending actions whose deadline has passed. The caller must hold s.mu.
+func (s *Server) reconcile() {
+	now := s.now()
+	for _, u := range s.users {
+		for _, sv := range u.servers {
+			switch sv.pending {
+			case jupyterhub.ServerPendingSpawn:
+				if !now.Before(sv.readyAt) {
+					sv.pending = ""
+				}
+			case jupyterhub.ServerPendingStop:
+				if !now.Before(sv.stoppedAt) {
+					sv.pending = ""
+					sv.stopped = true
+				}
+			}
+		}
+	}
+}
+
+func (sv *server) active() bool {
+	return !sv.stopped
+}
+
+func (sv *server) ready() bool {
+	return !sv.stopped && sv.pending == ""
+}
+
+// userModel converts the user into the JupyterHub user model. The caller must hold s.mu.
+func (s *Server) userModel(u *user, includeStoppedServers bool) jupyterhub.User {
+	name := u.name
+	admin := u.admin
+	lastActivity := u.lastActivity
+	roles := []string{"user"}
+	if admin {
+		roles = append(roles, "admin")
+	}
+	groups := []string{}
+	for _, g := range s.sortedGroups() {
+		for _, member := range g.users {
+			if member == u.name {
+				groups = append(groups, g.name)
+				break
+			}
+		}
+	}
+
+	servers := map[string]jupyterhub.Server{}
+	for _, sv := range u.servers {
+		if !sv.active() && !includeStoppedServers {
+			continue
+		}
+		servers[sv.name] = serverModel(u.name, sv)
+	}
+
+	model := jupyterhub.User{
+		Name:         &name,
+		Admin:        &admin,
+		Roles:        &roles,
+		Groups:       &groups,
+		LastActivity: &lastActivity,
+		Servers:      &servers,
+	}
+	if sv, ok := u.servers[""]; ok && sv.active() {
+		url := serverURL(u.name, "")
+		model.Server = &url
+		if sv.pending != "" {
+			pending := jupyterhub.UserPending(sv.pending)
+			model.Pending = &pending
+		}
+	}
+	return model
+}
+
+func serverModel(userName string, sv *server) jupyterhub.Server {
+	name := sv.name
+	ready := sv.ready()
+	stopped := sv.stopped
+	started := sv.started
+	url := serverURL(userName, sv.name)
+	progressURL := fmt.Sprintf("%s/users/%s/servers/%s/progress", APIPrefix, userName, sv.name)
+	model := jupyterhub.Server{
+		Name:        &name,
+		Ready:       &ready,
+		Stopped:     &stopped,
+		Started:     &started,
+		Url:         &url,
+		ProgressUrl: &progressURL,
+	}
+	if sv.pending != "" {
+		pending := sv.pending
+		model.Pending = &pending
+	}
+	if sv.userOptions != nil {
+		userOptions := sv.userOptions
+		model.UserOptions = &userOptions
+	}
+	return model
+}
+
+func tokenModel(userName string, t *token, withToken bool) jupyterhub.Token {
+	id := t.id
+	note := t.note
+	created := t.created
+	scopes := append([]string{}, t.scopes...)
+	roles := []string{}
+	model := jupyterhub.Token{
+		Id:        &id,
+		User:      &userName,
+		Note:      &note,
+		Created:   &created,
+		ExpiresAt: t.expiresAt,
+		Scopes:    &scopes,
+		Roles:     &roles,
+	}
+	if withToken {
+		value := t.token
+		model.Token = &value
+	}
+	return model
+}
+
+func groupModel(g *group) jupyterhub.Group {
+	name := g.name
+	users := append([]string{}, g.users...)
+	roles := []string{}
+	properties := map[string]interface{}{}
+	for k, v := range g.properties {
+		properties[k] = v
+	}
+	return jupyterhub.Group{
+		Name:       &name,
+		Users:      &users,
+		Roles:      &roles,
+		Properties: &properties,
+	}
+}
+
+func serverURL(userName, serverName string) string {
+	if serverName == "" {
+		return fmt.Sprintf("/user/%s/", userName)
+	}
+	return fmt.Sprintf("/user/%s/%s/", userName, serverName)
+}
+
+// sortedUsers returns users ordered by name. The caller must hold s.mu.
+func (s *Server) sortedUsers() []*user {
+	users := make([]*user, 0, len(s.users))
+	for _, u := range s.users {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].name < users[j].name })
+	return users
+}
+
+// sortedGroups returns groups ordered by name. The caller must hold s.mu.
+func (s *Server) sortedGroups() []*group {
+	groups := make([]*group, 0, len(s.groups))
+	for _, g := range s.groups {
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].name < groups[j].name })
+	return groups
+}
@@ -0,0 +1,280 @@
+// Package fakehub provides an embeddable fake JupyterHub REST API server for integration tests.
+//
+// Every request and response is validated against the OpenAPI spec embedded in the generated
+// JupyterHub client, so the fake can only answer in the shapes the real hub documents.
+package fakehub
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/labstack/echo/v4"
+
+	"github.com/toVersus/wbtemporal/pkg/client/jupyterhub"
+)
+
+const (
+	// APIPrefix is the path prefix of JupyterHub REST API
+	APIPrefix = "/hub/api"
+	// DefaultToken is the API token accepted by the fake hub unless WithToken is given
+	DefaultToken = "fakehub-token"
+	// Version is the JupyterHub version reported by the fake hub
+	Version = "4.0.0"
+)
+
+// Server is a fake JupyterHub served by httptest.Server.
+type Server struct {
+	*httptest.Server
+
+	mu sync.Mutex
+
+	token      string
+	spawnDelay time.Duration
+	stopDelay  time.Duration
+	now        func() time.Time
+
+	seq    int
+	users  map[string]*user
+	groups map[string]*group
+
+	router           routers.Router
+	validationErrors []error
+}
+
+// Option configures Server.
+type Option func(*Server)
+
+// WithToken sets the API token that clients must send in Authorization header.
+func WithToken(token string) Option {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// WithSpawnDelay sets the time it takes for user servers to become ready.
+// When the delay is zero, servers are ready as soon as they are requested.
+func WithSpawnDelay(delay time.Duration) Option {
+	return func(s *Server) {
+		s.spawnDelay = delay
+	}
+}
+
+// WithStopDelay sets the time it takes for user servers to be stopped.
+// When the delay is zero, servers are stopped as soon as they are requested.
+func WithStopDelay(delay time.Duration) Option {
+	return func(s *Server) {
+		s.stopDelay = delay
+	}
+}
+
+// WithClock replaces the clock used to decide whether pending actions have finished.
+func WithClock(now func() time.Time) Option {
+	return func(s *Server) {
+		s.now = now
+	}
+}
+
+// NewServer starts new fake hub. Callers must call Close when finished.
+// Clients should use the URL of the server as JupyterHub base URL, e.g. jupyterhubapi.NewExecutor(ctx, s.URL, s.Token()).
+func NewServer(opts ...Option) (*Server, error) {
+	spec, err := jupyterhub.GetSwagger()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load JupyterHub OpenAPI spec: %w", err)
+	}
+	router, err := gorillamux.NewRouter(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create router from JupyterHub OpenAPI spec: %w", err)
+	}
+
+	s := &Server{
+		token:  DefaultToken,
+		now:    time.Now,
+		users:  map[string]*user{},
+		groups: map[string]*group{},
+		router: router,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	e := echo.New()
+	e.HideBanner = true
+	e.HidePort = true
+	e.HTTPErrorHandler = errorHandler
+	e.Use(s.validate)
+	jupyterhub.RegisterHandlersWithBaseURL(e, &handler{s: s}, APIPrefix)
+
+	s.Server = httptest.NewServer(e)
+	return s, nil
+}
+
+// Token returns the API token accepted by the fake hub.
+func (s *Server) Token() string {
+	return s.token
+}
+
+// ValidationErrors returns all requests and responses that violated the OpenAPI spec so far.
+func (s *Server) ValidationErrors() []error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]error(nil), s.validationErrors...)
+}
+
+// AddUser registers the user directly, without going through the REST API.
+func (s *Server) AddUser(name string, admin bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[name] = s.newUser(name, admin)
+}
+
+// SetServerReady finishes the pending spawn of the user server immediately.
+func (s *Server) SetServerReady(userName, serverName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[userName]
+	if !ok {
+		return fmt.Errorf("user %s not found", userName)
+	}
+	sv, ok := u.servers[serverName]
+	if !ok || !sv.active() {
+		return fmt.Errorf("server %s of user %s not found", serverName, userName)
+	}
+	sv.pending = ""
+	return nil
+}
+
+// validate is the echo middleware that validates requests and responses against the OpenAPI spec.
+// Requests violating the spec are rejected with 400, and responses violating the spec are replaced with 500.
+func (s *Server) validate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		route, pathParams, err := s.router.FindRoute(req)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+
+		reqInput := &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				AuthenticationFunc: s.authenticate,
+			},
+		}
+		if err := openapi3filter.ValidateRequest(req.Context(), reqInput); err != nil {
+			var secErr *openapi3filter.SecurityRequirementsError
+			if errors.As(err, &secErr) {
+				return echo.NewHTTPError(http.StatusUnauthorized, "Invalid API token")
+			}
+			s.recordValidationError(fmt.Errorf("invalid request %s %s: %w", req.Method, req.URL.Path, err))
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+
+		// Handlers write response into buffer to validate it before sending it to clients
+		rec := &responseRecorder{header: http.Header{}, status: http.StatusOK}
+		w := c.Response().Writer
+		c.Response().Writer = rec
+		if err := next(c); err != nil {
+			// エラーレスポンスも仕様に沿っているか検証するため、クライアントに返す形式でバッファに書き込む
+			errorHandler(err, c)
+		}
+		c.Response().Writer = w
+
+		respInput := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: reqInput,
+			Status:                 rec.status,
+			Header:                 rec.header,
+			Options:                &openapi3filter.Options{IncludeResponseStatus: false},
+		}
+		respInput.SetBodyBytes(rec.body.Bytes())
+		if err := openapi3filter.ValidateResponse(req.Context(), respInput); err != nil {
+			c.Response().Committed = false
+			s.recordValidationError(fmt.Errorf("invalid response %s %s: %w", req.Method, req.URL.Path, err))
+			return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+		}
+
+		for k, v := range rec.header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rec.status)
+		_, err = w.Write(rec.body.Bytes())
+		return err
+	}
+}
+
+// authenticate accepts both token and oauth2 security schemes when Authorization header has the expected token.
+func (s *Server) authenticate(ctx context.Context, input *openapi3filter.AuthenticationInput) error {
+	header := input.RequestValidationInput.Request.Header.Get("Authorization")
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || (!strings.EqualFold(scheme, "token") && !strings.EqualFold(scheme, "bearer")) || token != s.token {
+		return fmt.Errorf("invalid API token")
+	}
+	return nil
+}
+
+func (s *Server) recordValidationError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.validationErrors = append(s.validationErrors, err)
+}
+
+// errorHandler writes errors in the same JSON format as JupyterHub.
+func errorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+	code := http.StatusInternalServerError
+	message := err.Error()
+	var he *echo.HTTPError
+	if errors.As(err, &he) {
+		code = he.Code
+		message = fmt.Sprint(he.Message)
+	}
+	_ = c.JSON(code, map[string]interface{}{
+		"status":  code,
+		"message": message,
+	})
+}
+
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+var _ http.ResponseWriter = &responseRecorder{}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+// readBody returns the request body, an empty body is returned as nil.
+func readBody(c echo.Context) ([]byte, error) {
+	b, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("failed to read request body: %v", err))
+	}
+	if len(bytes.TrimSpace(b)) == 0 {
+		return nil, nil
+	}
+	return b, nil
+}
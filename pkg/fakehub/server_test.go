@@ -0,0 +1,205 @@
+package fakehub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/toVersus/wbtemporal/pkg/client/jupyterhub"
+)
+
+func newTestClient(t *testing.T, s *Server, token string) *jupyterhub.ClientWithResponses {
+	t.Helper()
+	c, err := jupyterhub.NewClientWithResponses(s.URL+APIPrefix, jupyterhub.WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("Authorization", fmt.Sprintf("token %s", token))
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return c
+}
+
+func newTestServer(t *testing.T, opts ...Option) *Server {
+	t.Helper()
+	s, err := NewServer(opts...)
+	if err != nil {
+		t.Fatalf("failed to start fake hub: %v", err)
+	}
+	t.Cleanup(func() {
+		s.Close()
+		for _, err := range s.ValidationErrors() {
+			t.Errorf("unexpected validation error: %v", err)
+		}
+	})
+	return s
+}
+
+// errorMessage decodes the error response in JupyterHub format.
+func errorMessage(t *testing.T, body []byte) (int, string) {
+	t.Helper()
+	var resp struct {
+		Status  int    `json:"status"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("failed to decode error response %q: %v", body, err)
+	}
+	return resp.Status, resp.Message
+}
+
+func TestServerLifecycle(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServer(t)
+	c := newTestClient(t, s, s.Token())
+
+	created, err := c.PostUsersNameWithResponse(ctx, "alice")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	if created.StatusCode() != http.StatusCreated || created.JSON201 == nil || *created.JSON201.Name != "alice" {
+		t.Fatalf("unexpected response to create user: %d %s", created.StatusCode(), created.Body)
+	}
+
+	started, err := c.PostUsersNameServersServerNameWithResponse(ctx, "alice", "s1", jupyterhub.PostUsersNameServersServerNameJSONRequestBody{})
+	if err != nil {
+		t.Fatalf("failed to start server: %v", err)
+	}
+	if started.StatusCode() != http.StatusCreated {
+		t.Fatalf("unexpected response to start server: %d %s", started.StatusCode(), started.Body)
+	}
+
+	user, err := c.GetUsersNameWithResponse(ctx, "alice")
+	if err != nil {
+		t.Fatalf("failed to get user: %v", err)
+	}
+	if user.JSON200 == nil || user.JSON200.Servers == nil {
+		t.Fatalf("unexpected response to get user: %d %s", user.StatusCode(), user.Body)
+	}
+	server, ok := (*user.JSON200.Servers)["s1"]
+	if !ok || server.Ready == nil || !*server.Ready {
+		t.Fatalf("server s1 is not ready: %s", user.Body)
+	}
+
+	deleted, err := c.DeleteUsersNameServersServerNameWithResponse(ctx, "alice", "s1")
+	if err != nil {
+		t.Fatalf("failed to stop server: %v", err)
+	}
+	if deleted.StatusCode() != http.StatusNoContent {
+		t.Fatalf("unexpected response to stop server: %d %s", deleted.StatusCode(), deleted.Body)
+	}
+}
+
+func TestServerErrorResponses(t *testing.T) {
+	ctx := context.Background()
+	s := newTestServer(t, WithSpawnDelay(time.Hour))
+	s.AddUser("alice", false)
+	c := newTestClient(t, s, s.Token())
+
+	started, err := c.PostUsersNameServersServerNameWithResponse(ctx, "alice", "s1", jupyterhub.PostUsersNameServersServerNameJSONRequestBody{})
+	if err != nil {
+		t.Fatalf("failed to start server: %v", err)
+	}
+	if started.StatusCode() != http.StatusAccepted {
+		t.Fatalf("unexpected response to start server: %d %s", started.StatusCode(), started.Body)
+	}
+
+	tests := []struct {
+		name    string
+		do      func() (int, []byte, error)
+		code    int
+		message string
+	}{
+		{
+			name: "user not found",
+			do: func() (int, []byte, error) {
+				resp, err := c.GetUsersNameWithResponse(ctx, "bob")
+				if err != nil {
+					return 0, nil, err
+				}
+				return resp.StatusCode(), resp.Body, nil
+			},
+			code:    http.StatusNotFound,
+			message: "No such user: bob",
+		},
+		{
+			name: "user already exists",
+			do: func() (int, []byte, error) {
+				resp, err := c.PostUsersNameWithResponse(ctx, "alice")
+				if err != nil {
+					return 0, nil, err
+				}
+				return resp.StatusCode(), resp.Body, nil
+			},
+			code:    http.StatusConflict,
+			message: "User alice already exists",
+		},
+		{
+			name: "start server pending spawn",
+			do: func() (int, []byte, error) {
+				resp, err := c.PostUsersNameServersServerNameWithResponse(ctx, "alice", "s1", jupyterhub.PostUsersNameServersServerNameJSONRequestBody{})
+				if err != nil {
+					return 0, nil, err
+				}
+				return resp.StatusCode(), resp.Body, nil
+			},
+			code:    http.StatusBadRequest,
+			message: "alice:s1 is pending spawn",
+		},
+		{
+			name: "stop server pending spawn",
+			do: func() (int, []byte, error) {
+				resp, err := c.DeleteUsersNameServersServerNameWithResponse(ctx, "alice", "s1")
+				if err != nil {
+					return 0, nil, err
+				}
+				return resp.StatusCode(), resp.Body, nil
+			},
+			code:    http.StatusBadRequest,
+			message: "alice:s1 is pending spawn, please wait",
+		},
+		{
+			name: "stop missing server",
+			do: func() (int, []byte, error) {
+				resp, err := c.DeleteUsersNameServersServerNameWithResponse(ctx, "alice", "s2")
+				if err != nil {
+					return 0, nil, err
+				}
+				return resp.StatusCode(), resp.Body, nil
+			},
+			code:    http.StatusNotFound,
+			message: "alice has no server named 's2'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, body, err := tt.do()
+			if err != nil {
+				t.Fatalf("failed to send request: %v", err)
+			}
+			if code != tt.code {
+				t.Fatalf("expected status %d, got %d: %s", tt.code, code, body)
+			}
+			status, message := errorMessage(t, body)
+			if status != tt.code || message != tt.message {
+				t.Errorf("expected error %d %q, got %d %q", tt.code, tt.message, status, message)
+			}
+		})
+	}
+}
+
+func TestServerRejectsInvalidToken(t *testing.T) {
+	s := newTestServer(t)
+	c := newTestClient(t, s, "invalid")
+
+	resp, err := c.GetUsersNameWithResponse(context.Background(), "alice")
+	if err != nil {
+		t.Fatalf("failed to get user: %v", err)
+	}
+	if resp.StatusCode() != http.StatusUnauthorized {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusUnauthorized, resp.StatusCode(), resp.Body)
+	}
+}
@@ -0,0 +1,156 @@
+package fakenotebooks
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strings"
+
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"google.golang.org/api/option"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// defaultAcceleratorTypes is the accelerator types available in every zone unless SetAcceleratorTypes is called
+var defaultAcceleratorTypes = []struct {
+	name string
+	max  int32
+}{
+	{name: "nvidia-tesla-k80", max: 8},
+	{name: "nvidia-tesla-p100", max: 4},
+	{name: "nvidia-tesla-p100-vws", max: 4},
+	{name: "nvidia-tesla-v100", max: 8},
+	{name: "nvidia-tesla-p4", max: 4},
+	{name: "nvidia-tesla-p4-vws", max: 4},
+	{name: "nvidia-tesla-t4", max: 4},
+	{name: "nvidia-tesla-t4-vws", max: 4},
+	{name: "nvidia-tesla-a100", max: 16},
+}
+
+// ComputeClientOptions returns the options to make Compute Engine REST clients talk to the fake server,
+// e.g. compute.NewAcceleratorTypesRESTClient(ctx, s.ComputeClientOptions()...).
+func (s *Server) ComputeClientOptions() []option.ClientOption {
+	return []option.ClientOption{
+		option.WithEndpoint(s.compute.URL),
+		option.WithHTTPClient(s.compute.Client()),
+	}
+}
+
+// SetAcceleratorTypes replaces the accelerator types available in the zone.
+func (s *Server) SetAcceleratorTypes(zone string, types ...*computepb.AcceleratorType) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.acceleratorTypes[zone] = types
+}
+
+// SetGuestAttribute writes the guest attribute of the instance, as if it is written from inside the VM.
+func (s *Server) SetGuestAttribute(project, zone, instance, namespace, key, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := path.Join(project, zone, instance)
+	if s.guestAttributes[id] == nil {
+		s.guestAttributes[id] = map[string]string{}
+	}
+	s.guestAttributes[id][path.Join(namespace, key)] = value
+}
+
+func newComputeServer(s *Server) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/compute/v1/projects/", s.handleCompute)
+	return httptest.NewServer(mux)
+}
+
+// handleCompute serves the subset of Compute Engine REST API used by the executor.
+func (s *Server) handleCompute(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/compute/v1/"), "/")
+	// /compute/v1/projects/{project}/zones/{zone}/acceleratorTypes
+	if r.Method == http.MethodGet && len(parts) == 5 && parts[0] == "projects" && parts[2] == "zones" && parts[4] == "acceleratorTypes" {
+		s.listAcceleratorTypes(w, parts[1], parts[3])
+		return
+	}
+	// /compute/v1/projects/{project}/zones/{zone}/instances/{instance}/getGuestAttributes
+	if r.Method == http.MethodGet && len(parts) == 7 && parts[0] == "projects" && parts[2] == "zones" && parts[4] == "instances" && parts[6] == "getGuestAttributes" {
+		s.getGuestAttributes(w, parts[1], parts[3], parts[5], r.URL.Query().Get("queryPath"))
+		return
+	}
+	writeComputeError(w, http.StatusNotFound, fmt.Sprintf("The requested URL %s was not found on this server.", r.URL.Path))
+}
+
+func (s *Server) listAcceleratorTypes(w http.ResponseWriter, project, zone string) {
+	s.mu.Lock()
+	types, ok := s.acceleratorTypes[zone]
+	if !ok {
+		for _, t := range defaultAcceleratorTypes {
+			types = append(types, &computepb.AcceleratorType{
+				Name:                    proto.String(t.name),
+				MaximumCardsPerInstance: proto.Int32(t.max),
+			})
+		}
+	}
+	s.mu.Unlock()
+
+	list := &computepb.AcceleratorTypeList{
+		Kind: proto.String("compute#acceleratorTypeList"),
+	}
+	for _, t := range types {
+		t = proto.Clone(t).(*computepb.AcceleratorType)
+		t.Kind = proto.String("compute#acceleratorType")
+		t.Zone = proto.String(fmt.Sprintf("projects/%s/zones/%s", project, zone))
+		list.Items = append(list.Items, t)
+	}
+	b, err := protojson.Marshal(list)
+	if err != nil {
+		writeComputeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
+
+// getGuestAttributes returns the guest attributes under the query path in "<namespace>/" format.
+func (s *Server) getGuestAttributes(w http.ResponseWriter, project, zone, instance, queryPath string) {
+	s.mu.Lock()
+	attrs := s.guestAttributes[path.Join(project, zone, instance)]
+	value := &computepb.GuestAttributesValue{}
+	for k, v := range attrs {
+		namespace, key := path.Split(k)
+		if namespace != queryPath {
+			continue
+		}
+		value.Items = append(value.Items, &computepb.GuestAttributesEntry{
+			Namespace: proto.String(strings.TrimSuffix(namespace, "/")),
+			Key:       proto.String(key),
+			Value:     proto.String(v),
+		})
+	}
+	s.mu.Unlock()
+
+	if len(value.Items) == 0 {
+		writeComputeError(w, http.StatusNotFound, fmt.Sprintf("The resource 'projects/%s/zones/%s/instances/%s/guestAttributes/%s' was not found", project, zone, instance, queryPath))
+		return
+	}
+	sort.Slice(value.Items, func(i, j int) bool {
+		return value.Items[i].GetKey() < value.Items[j].GetKey()
+	})
+	b, err := protojson.Marshal(&computepb.GuestAttributes{
+		Kind:       proto.String("compute#guestAttributes"),
+		QueryPath:  proto.String(queryPath),
+		QueryValue: value,
+	})
+	if err != nil {
+		writeComputeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(b)
+}
+
+// writeComputeError writes the error in the same JSON format as Google APIs.
+func writeComputeError(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = fmt.Fprintf(w, `{"error":{"code":%d,"message":%q}}`, code, message)
+}
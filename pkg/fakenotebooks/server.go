@@ -0,0 +1,569 @@
+// Package fakenotebooks provides an in-process fake of the Notebooks API gRPC server for testing.
+//
+// The server listens on bufconn, so the real notebooks.NotebookClient can dial it through
+// ClientOption without network access or Google Cloud credentials.
+// Compute Engine REST API used together with Notebooks API is served by httptest server through ComputeClientOptions.
+package fakenotebooks
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http/httptest"
+	"path"
+	"sync"
+
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"cloud.google.com/go/notebooks/apiv1/notebookspb"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	bufSize = 1024 * 1024
+)
+
+// Server is a fake NotebookService and Operations server.
+// Long-running operations stay pending until CompleteOperation or FailOperation is called,
+// unless the server is created with WithAutoComplete.
+type Server struct {
+	notebookspb.UnimplementedNotebookServiceServer
+	longrunningpb.UnimplementedOperationsServer
+
+	mu sync.Mutex
+
+	autoComplete bool
+
+	seq              int
+	instances        map[string]*notebookspb.Instance
+	operations       map[string]*operation
+	order            []string
+	acceleratorTypes map[string][]*computepb.AcceleratorType
+	// guestAttributes is keyed by "<project>/<zone>/<instance>" and then by "<namespace>/<key>"
+	guestAttributes map[string]map[string]string
+	// upgradeVersion is the image version that instances can be upgraded to, empty means no upgrade is available
+	upgradeVersion string
+
+	listener *bufconn.Listener
+	server   *grpc.Server
+	conn     *grpc.ClientConn
+	compute  *httptest.Server
+}
+
+type operation struct {
+	op *longrunningpb.Operation
+	// apply changes the instance when the operation finishes successfully and returns the operation response
+	apply func() proto.Message
+}
+
+// Option configures Server.
+type Option func(*Server)
+
+// WithAutoComplete makes long-running operations finish as soon as they are started.
+func WithAutoComplete() Option {
+	return func(s *Server) {
+		s.autoComplete = true
+	}
+}
+
+// NewServer starts new fake server. Callers must call Close when finished.
+func NewServer(ctx context.Context, opts ...Option) (*Server, error) {
+	s := &Server{
+		instances:        map[string]*notebookspb.Instance{},
+		operations:       map[string]*operation{},
+		acceleratorTypes: map[string][]*computepb.AcceleratorType{},
+		guestAttributes:  map[string]map[string]string{},
+		listener:         bufconn.Listen(bufSize),
+		server:           grpc.NewServer(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	notebookspb.RegisterNotebookServiceServer(s.server, s)
+	longrunningpb.RegisterOperationsServer(s.server, s)
+	go func() {
+		_ = s.server.Serve(s.listener)
+	}()
+
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return s.listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		s.server.Stop()
+		return nil, fmt.Errorf("failed to dial fake notebooks server: %w", err)
+	}
+	s.conn = conn
+	s.compute = newComputeServer(s)
+	return s, nil
+}
+
+// ClientOption returns the option to make Google Cloud gRPC clients talk to the fake server,
+// e.g. notebooks.NewNotebookClient(ctx, s.ClientOption()).
+func (s *Server) ClientOption() option.ClientOption {
+	return option.WithGRPCConn(s.conn)
+}
+
+// Close stops the server and closes the client connection.
+func (s *Server) Close() {
+	s.conn.Close()
+	s.server.Stop()
+	s.compute.Close()
+}
+
+// AddInstance registers the instance directly, without going through the API.
+func (s *Server) AddInstance(instance *notebookspb.Instance) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.instances[instance.GetName()] = proto.Clone(instance).(*notebookspb.Instance)
+}
+
+// Instance returns a copy of the instance, or nil if the instance doesn't exist.
+func (s *Server) Instance(name string) *notebookspb.Instance {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	instance, ok := s.instances[name]
+	if !ok {
+		return nil
+	}
+	return proto.Clone(instance).(*notebookspb.Instance)
+}
+
+// SetUpgradeVersion makes the image version available for instances to be upgraded to.
+func (s *Server) SetUpgradeVersion(version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.upgradeVersion = version
+}
+
+// Operations returns the names of all operations started so far, in the order they were started.
+func (s *Server) Operations() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.order...)
+}
+
+// CompleteOperation finishes the operation successfully and applies its change to the instance.
+func (s *Server) CompleteOperation(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	op, ok := s.operations[name]
+	if !ok {
+		return fmt.Errorf("operation %q not found", name)
+	}
+	return s.complete(op)
+}
+
+// FailOperation finishes the operation with the error. The instance is left as it is.
+func (s *Server) FailOperation(name string, code codes.Code, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	op, ok := s.operations[name]
+	if !ok {
+		return fmt.Errorf("operation %q not found", name)
+	}
+	if op.op.GetDone() {
+		return fmt.Errorf("operation %q is already done", name)
+	}
+	op.op.Done = true
+	op.op.Result = &longrunningpb.Operation_Error{
+		Error: status.New(code, message).Proto(),
+	}
+	return nil
+}
+
+func (s *Server) CreateInstance(ctx context.Context, req *notebookspb.CreateInstanceRequest) (*longrunningpb.Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if req.GetInstanceId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "instance_id is required")
+	}
+	name := fmt.Sprintf("%s/instances/%s", req.GetParent(), req.GetInstanceId())
+	if _, ok := s.instances[name]; ok {
+		return nil, status.Errorf(codes.AlreadyExists, "instance %q already exists", name)
+	}
+
+	instance := proto.Clone(req.GetInstance()).(*notebookspb.Instance)
+	if instance == nil {
+		instance = &notebookspb.Instance{}
+	}
+	instance.Name = name
+	instance.State = notebookspb.Instance_PROVISIONING
+	instance.CreateTime = timestamppb.Now()
+	instance.UpdateTime = instance.CreateTime
+	s.instances[name] = instance
+
+	return s.startOperation(req.GetParent(), name, "create", func() proto.Message {
+		instance.State = notebookspb.Instance_ACTIVE
+		instance.ProxyUri = fmt.Sprintf("%s-dot-fake.notebooks.googleusercontent.com", req.GetInstanceId())
+		instance.UpdateTime = timestamppb.Now()
+		return instance
+	})
+}
+
+func (s *Server) GetInstance(ctx context.Context, req *notebookspb.GetInstanceRequest) (*notebookspb.Instance, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	instance, err := s.instance(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	return proto.Clone(instance).(*notebookspb.Instance), nil
+}
+
+func (s *Server) StartInstance(ctx context.Context, req *notebookspb.StartInstanceRequest) (*longrunningpb.Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	instance, err := s.instance(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	if instance.State != notebookspb.Instance_STOPPED && instance.State != notebookspb.Instance_ACTIVE {
+		return nil, status.Errorf(codes.FailedPrecondition, "instance %q cannot be started in %s state", req.GetName(), instance.State)
+	}
+	if instance.State == notebookspb.Instance_STOPPED {
+		instance.State = notebookspb.Instance_STARTING
+	}
+	return s.startOperation(parent(req.GetName()), req.GetName(), "start", func() proto.Message {
+		instance.State = notebookspb.Instance_ACTIVE
+		instance.UpdateTime = timestamppb.Now()
+		return instance
+	})
+}
+
+func (s *Server) StopInstance(ctx context.Context, req *notebookspb.StopInstanceRequest) (*longrunningpb.Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	instance, err := s.instance(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	if instance.State != notebookspb.Instance_ACTIVE && instance.State != notebookspb.Instance_STOPPED {
+		return nil, status.Errorf(codes.FailedPrecondition, "instance %q cannot be stopped in %s state", req.GetName(), instance.State)
+	}
+	if instance.State == notebookspb.Instance_ACTIVE {
+		instance.State = notebookspb.Instance_STOPPING
+	}
+	return s.startOperation(parent(req.GetName()), req.GetName(), "stop", func() proto.Message {
+		instance.State = notebookspb.Instance_STOPPED
+		instance.UpdateTime = timestamppb.Now()
+		return instance
+	})
+}
+
+func (s *Server) SetInstanceMachineType(ctx context.Context, req *notebookspb.SetInstanceMachineTypeRequest) (*longrunningpb.Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	instance, err := s.instance(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	if instance.State != notebookspb.Instance_STOPPED {
+		return nil, status.Errorf(codes.FailedPrecondition, "machine type of instance %q cannot be changed in %s state", req.GetName(), instance.State)
+	}
+	machineType := req.GetMachineType()
+	return s.startOperation(parent(req.GetName()), req.GetName(), "update", func() proto.Message {
+		instance.MachineType = machineType
+		instance.UpdateTime = timestamppb.Now()
+		return instance
+	})
+}
+
+func (s *Server) IsInstanceUpgradeable(ctx context.Context, req *notebookspb.IsInstanceUpgradeableRequest) (*notebookspb.IsInstanceUpgradeableResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	instance, err := s.instance(req.GetNotebookInstance())
+	if err != nil {
+		return nil, err
+	}
+	if s.upgradeVersion == "" || currentVersion(instance) == s.upgradeVersion {
+		return &notebookspb.IsInstanceUpgradeableResponse{UpgradeInfo: "Instance is already on the latest version"}, nil
+	}
+	return &notebookspb.IsInstanceUpgradeableResponse{
+		Upgradeable:    true,
+		UpgradeVersion: s.upgradeVersion,
+		UpgradeImage:   "projects/deeplearning-platform-release/global/images/common-cpu-" + s.upgradeVersion,
+	}, nil
+}
+
+func (s *Server) UpgradeInstance(ctx context.Context, req *notebookspb.UpgradeInstanceRequest) (*longrunningpb.Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	instance, err := s.instance(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	if instance.State != notebookspb.Instance_ACTIVE {
+		return nil, status.Errorf(codes.FailedPrecondition, "instance %q cannot be upgraded in %s state", req.GetName(), instance.State)
+	}
+	if s.upgradeVersion == "" || currentVersion(instance) == s.upgradeVersion {
+		return nil, status.Errorf(codes.FailedPrecondition, "instance %q is already on the latest version", req.GetName())
+	}
+	entry := &notebookspb.Instance_UpgradeHistoryEntry{
+		Snapshot:      fmt.Sprintf("%s-snapshot-%d", path.Base(req.GetName()), len(instance.UpgradeHistory)+1),
+		Version:       currentVersion(instance),
+		TargetVersion: s.upgradeVersion,
+		Action:        notebookspb.Instance_UpgradeHistoryEntry_UPGRADE,
+		State:         notebookspb.Instance_UpgradeHistoryEntry_STARTED,
+		CreateTime:    timestamppb.Now(),
+	}
+	instance.UpgradeHistory = append(instance.UpgradeHistory, entry)
+	instance.State = notebookspb.Instance_UPGRADING
+	return s.startOperation(parent(req.GetName()), req.GetName(), "upgrade", func() proto.Message {
+		entry.State = notebookspb.Instance_UpgradeHistoryEntry_SUCCEEDED
+		instance.State = notebookspb.Instance_ACTIVE
+		instance.UpdateTime = timestamppb.Now()
+		return instance
+	})
+}
+
+func (s *Server) RollbackInstance(ctx context.Context, req *notebookspb.RollbackInstanceRequest) (*longrunningpb.Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	instance, err := s.instance(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	var target *notebookspb.Instance_UpgradeHistoryEntry
+	for _, e := range instance.UpgradeHistory {
+		if e.GetAction() == notebookspb.Instance_UpgradeHistoryEntry_UPGRADE && e.GetSnapshot() == req.GetTargetSnapshot() {
+			target = e
+		}
+	}
+	if req.GetTargetSnapshot() == "" || target == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "snapshot %q not found in upgrade history of instance %q", req.GetTargetSnapshot(), req.GetName())
+	}
+	entry := &notebookspb.Instance_UpgradeHistoryEntry{
+		Snapshot:      req.GetTargetSnapshot(),
+		Version:       currentVersion(instance),
+		TargetVersion: target.GetVersion(),
+		Action:        notebookspb.Instance_UpgradeHistoryEntry_ROLLBACK,
+		State:         notebookspb.Instance_UpgradeHistoryEntry_STARTED,
+		CreateTime:    timestamppb.Now(),
+	}
+	instance.UpgradeHistory = append(instance.UpgradeHistory, entry)
+	instance.State = notebookspb.Instance_UPGRADING
+	return s.startOperation(parent(req.GetName()), req.GetName(), "rollback", func() proto.Message {
+		entry.State = notebookspb.Instance_UpgradeHistoryEntry_SUCCEEDED
+		instance.State = notebookspb.Instance_ACTIVE
+		instance.UpdateTime = timestamppb.Now()
+		return instance
+	})
+}
+
+func (s *Server) ResetInstance(ctx context.Context, req *notebookspb.ResetInstanceRequest) (*longrunningpb.Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	instance, err := s.instance(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	if instance.State != notebookspb.Instance_ACTIVE {
+		return nil, status.Errorf(codes.FailedPrecondition, "instance %q cannot be reset in %s state", req.GetName(), instance.State)
+	}
+	return s.startOperation(parent(req.GetName()), req.GetName(), "reset", func() proto.Message {
+		instance.UpdateTime = timestamppb.Now()
+		return instance
+	})
+}
+
+func (s *Server) DiagnoseInstance(ctx context.Context, req *notebookspb.DiagnoseInstanceRequest) (*longrunningpb.Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	instance, err := s.instance(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	if req.GetDiagnosticConfig().GetGcsBucket() == "" {
+		return nil, status.Error(codes.InvalidArgument, "diagnostic_config.gcs_bucket is required")
+	}
+	if instance.State != notebookspb.Instance_ACTIVE {
+		return nil, status.Errorf(codes.FailedPrecondition, "instance %q cannot be diagnosed in %s state", req.GetName(), instance.State)
+	}
+	return s.startOperation(parent(req.GetName()), req.GetName(), "diagnose", func() proto.Message {
+		return instance
+	})
+}
+
+func (s *Server) SetInstanceAccelerator(ctx context.Context, req *notebookspb.SetInstanceAcceleratorRequest) (*longrunningpb.Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	instance, err := s.instance(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	if req.GetType() == notebookspb.Instance_ACCELERATOR_TYPE_UNSPECIFIED {
+		return nil, status.Error(codes.InvalidArgument, "accelerator type is required")
+	}
+	if instance.State != notebookspb.Instance_STOPPED {
+		return nil, status.Errorf(codes.FailedPrecondition, "accelerator of instance %q cannot be changed in %s state", req.GetName(), instance.State)
+	}
+	config := &notebookspb.Instance_AcceleratorConfig{Type: req.GetType(), CoreCount: req.GetCoreCount()}
+	return s.startOperation(parent(req.GetName()), req.GetName(), "update", func() proto.Message {
+		instance.AcceleratorConfig = config
+		instance.UpdateTime = timestamppb.Now()
+		return instance
+	})
+}
+
+func (s *Server) SetInstanceLabels(ctx context.Context, req *notebookspb.SetInstanceLabelsRequest) (*longrunningpb.Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	instance, err := s.instance(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	labels := map[string]string{}
+	for k, v := range req.GetLabels() {
+		labels[k] = v
+	}
+	return s.startOperation(parent(req.GetName()), req.GetName(), "update", func() proto.Message {
+		instance.Labels = labels
+		instance.UpdateTime = timestamppb.Now()
+		return instance
+	})
+}
+
+func (s *Server) UpdateInstanceMetadataItems(ctx context.Context, req *notebookspb.UpdateInstanceMetadataItemsRequest) (*notebookspb.UpdateInstanceMetadataItemsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	instance, err := s.instance(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+	if instance.Metadata == nil {
+		instance.Metadata = map[string]string{}
+	}
+	for k, v := range req.GetItems() {
+		instance.Metadata[k] = v
+	}
+	instance.UpdateTime = timestamppb.Now()
+	return &notebookspb.UpdateInstanceMetadataItemsResponse{Items: instance.Metadata}, nil
+}
+
+func (s *Server) DeleteInstance(ctx context.Context, req *notebookspb.DeleteInstanceRequest) (*longrunningpb.Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.instance(req.GetName()); err != nil {
+		return nil, err
+	}
+	name := req.GetName()
+	return s.startOperation(parent(name), name, "delete", func() proto.Message {
+		delete(s.instances, name)
+		return &emptypb.Empty{}
+	})
+}
+
+func (s *Server) GetOperation(ctx context.Context, req *longrunningpb.GetOperationRequest) (*longrunningpb.Operation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	op, ok := s.operations[req.GetName()]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "operation %q not found", req.GetName())
+	}
+	return proto.Clone(op.op).(*longrunningpb.Operation), nil
+}
+
+// startOperation registers new long-running operation. The caller must hold s.mu.
+func (s *Server) startOperation(parent, target, verb string, apply func() proto.Message) (*longrunningpb.Operation, error) {
+	s.seq++
+	metadata, err := anypb.New(&notebookspb.OperationMetadata{
+		CreateTime: timestamppb.Now(),
+		Target:     target,
+		Verb:       verb,
+		ApiVersion: "v1",
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to marshal operation metadata: %v", err)
+	}
+	op := &operation{
+		op: &longrunningpb.Operation{
+			Name:     fmt.Sprintf("%s/operations/operation-%d", parent, s.seq),
+			Metadata: metadata,
+		},
+		apply: apply,
+	}
+	s.operations[op.op.Name] = op
+	s.order = append(s.order, op.op.Name)
+	if s.autoComplete {
+		if err := s.complete(op); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+	return proto.Clone(op.op).(*longrunningpb.Operation), nil
+}
+
+// complete finishes the operation successfully. The caller must hold s.mu.
+func (s *Server) complete(op *operation) error {
+	if op.op.GetDone() {
+		return fmt.Errorf("operation %q is already done", op.op.GetName())
+	}
+	resp, err := anypb.New(op.apply())
+	if err != nil {
+		return fmt.Errorf("failed to marshal operation response: %w", err)
+	}
+	op.op.Done = true
+	op.op.Result = &longrunningpb.Operation_Response{Response: resp}
+	return nil
+}
+
+// instance returns the instance or NotFound error. The caller must hold s.mu.
+func (s *Server) instance(name string) (*notebookspb.Instance, error) {
+	instance, ok := s.instances[name]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "instance %q not found", name)
+	}
+	return instance, nil
+}
+
+// currentVersion returns the image version of the instance after the latest successful upgrade or rollback,
+// or empty string if the instance has never been upgraded.
+func currentVersion(instance *notebookspb.Instance) string {
+	history := instance.GetUpgradeHistory()
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].GetState() == notebookspb.Instance_UpgradeHistoryEntry_SUCCEEDED {
+			return history[i].GetTargetVersion()
+		}
+	}
+	return ""
+}
+
+// parent returns "projects/*/locations/*" part of the instance name.
+func parent(name string) string {
+	for i, slash := len(name)-1, 0; i >= 0; i-- {
+		if name[i] == '/' {
+			slash++
+			if slash == 2 {
+				return name[:i]
+			}
+		}
+	}
+	return name
+}
@@ -0,0 +1,174 @@
+package fakenotebooks_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/toVersus/wbtemporal/pkg/executor/googleapi"
+	"github.com/toVersus/wbtemporal/pkg/fakenotebooks"
+)
+
+func newTestExecutor(t *testing.T, opts ...fakenotebooks.Option) (*fakenotebooks.Server, googleapi.Executor) {
+	t.Helper()
+	ctx := context.Background()
+	s, err := fakenotebooks.NewServer(ctx, opts...)
+	if err != nil {
+		t.Fatalf("failed to start fake server: %v", err)
+	}
+	t.Cleanup(s.Close)
+
+	executor, err := googleapi.NewWorkbench(ctx,
+		googleapi.WithNotebookClientOptions(s.ClientOption()),
+		googleapi.WithComputeClientOptions(s.ComputeClientOptions()...),
+	)
+	if err != nil {
+		t.Fatalf("failed to create executor: %v", err)
+	}
+	return s, executor
+}
+
+func testOption() *googleapi.Option {
+	return &googleapi.Option{
+		Name:        "test-workbench",
+		Email:       "owner@example.com",
+		Zone:        "us-central1-a",
+		Location:    "us-central1",
+		ProjectId:   "test-project",
+		MachineType: "n1-standard-4",
+		Network:     "default",
+		Subnet:      "default",
+	}
+}
+
+// waitOperation checks that the operation is pending, completes it and checks that it is reported as done.
+func waitOperation(t *testing.T, s *fakenotebooks.Server, executor googleapi.Executor, opName string) {
+	t.Helper()
+	ctx := context.Background()
+	done, err := executor.HasOperationDone(ctx, opName)
+	if err != nil {
+		t.Fatalf("failed to get operation %s: %v", opName, err)
+	}
+	if done {
+		t.Fatalf("operation %s finished before it was completed", opName)
+	}
+	if err := s.CompleteOperation(opName); err != nil {
+		t.Fatalf("failed to complete operation %s: %v", opName, err)
+	}
+	done, err = executor.HasOperationDone(ctx, opName)
+	if err != nil {
+		t.Fatalf("failed to get operation %s: %v", opName, err)
+	}
+	if !done {
+		t.Fatalf("operation %s is not done after it was completed", opName)
+	}
+}
+
+func TestWorkbenchLifecycle(t *testing.T) {
+	ctx := context.Background()
+	s, executor := newTestExecutor(t)
+	option := testOption()
+
+	opName, err := executor.CreateNotebookInstance(ctx, option)
+	if err != nil {
+		t.Fatalf("failed to create instance: %v", err)
+	}
+	status, err := executor.DescribeNotebookInstance(ctx, option)
+	if err != nil {
+		t.Fatalf("failed to describe instance: %v", err)
+	}
+	if status.Status != googleapi.InstanceStateProvisioning {
+		t.Errorf("expected state %s while creating, got %s", googleapi.InstanceStateProvisioning, status.Status)
+	}
+
+	waitOperation(t, s, executor, opName)
+	status, err = executor.DescribeNotebookInstance(ctx, option)
+	if err != nil {
+		t.Fatalf("failed to describe instance: %v", err)
+	}
+	if status.Status != googleapi.InstanceStateActive {
+		t.Errorf("expected state %s after creation, got %s", googleapi.InstanceStateActive, status.Status)
+	}
+	if status.URL == "" {
+		t.Errorf("expected proxy URL after creation")
+	}
+	if status.MachineType != option.MachineType {
+		t.Errorf("expected machine type %s, got %s", option.MachineType, status.MachineType)
+	}
+
+	opName, err = executor.DeleteNotebookInstance(ctx, option)
+	if err != nil {
+		t.Fatalf("failed to delete instance: %v", err)
+	}
+	waitOperation(t, s, executor, opName)
+	if _, err := executor.DescribeNotebookInstance(ctx, option); !errors.Is(err, googleapi.ErrNotFound) {
+		t.Errorf("expected %v after deletion, got %v", googleapi.ErrNotFound, err)
+	}
+}
+
+func TestWorkbenchErrors(t *testing.T) {
+	ctx := context.Background()
+	_, executor := newTestExecutor(t, fakenotebooks.WithAutoComplete())
+	option := testOption()
+
+	if _, err := executor.DescribeNotebookInstance(ctx, option); !errors.Is(err, googleapi.ErrNotFound) {
+		t.Errorf("expected %v for missing instance, got %v", googleapi.ErrNotFound, err)
+	}
+	if _, err := executor.DeleteNotebookInstance(ctx, option); !errors.Is(err, googleapi.ErrNotFound) {
+		t.Errorf("expected %v to delete missing instance, got %v", googleapi.ErrNotFound, err)
+	}
+
+	opName, err := executor.CreateNotebookInstance(ctx, option)
+	if err != nil {
+		t.Fatalf("failed to create instance: %v", err)
+	}
+	if done, err := executor.HasOperationDone(ctx, opName); err != nil || !done {
+		t.Fatalf("expected auto-completed operation, got done=%t err=%v", done, err)
+	}
+	if _, err := executor.HasOperationDone(ctx, "projects/test-project/locations/us-central1-a/operations/missing"); !errors.Is(err, googleapi.ErrNotFound) {
+		t.Errorf("expected %v for missing operation, got %v", googleapi.ErrNotFound, err)
+	}
+}
+
+func TestWorkbenchOperationFailed(t *testing.T) {
+	ctx := context.Background()
+	s, executor := newTestExecutor(t)
+	option := testOption()
+
+	opName, err := executor.CreateNotebookInstance(ctx, option)
+	if err != nil {
+		t.Fatalf("failed to create instance: %v", err)
+	}
+	waitOperation(t, s, executor, opName)
+
+	option.MachineType = "n1-highmem-96"
+	if _, err := executor.SetNotebookInstanceMachineType(ctx, option); !errors.Is(err, googleapi.ErrFailedPrecondition) {
+		t.Errorf("expected %v to resize running instance, got %v", googleapi.ErrFailedPrecondition, err)
+	}
+	opName, err = executor.StopNotebookInstance(ctx, option)
+	if err != nil {
+		t.Fatalf("failed to stop instance: %v", err)
+	}
+	waitOperation(t, s, executor, opName)
+
+	opName, err = executor.SetNotebookInstanceMachineType(ctx, option)
+	if err != nil {
+		t.Fatalf("failed to set machine type: %v", err)
+	}
+	if err := s.FailOperation(opName, codes.ResourceExhausted, "zone does not have enough resources"); err != nil {
+		t.Fatalf("failed to fail operation %s: %v", opName, err)
+	}
+	if _, err := executor.HasOperationDone(ctx, opName); !errors.Is(err, googleapi.ErrResourceExhausted) {
+		t.Errorf("expected %v for aborted operation, got %v", googleapi.ErrResourceExhausted, err)
+	}
+
+	status, err := executor.DescribeNotebookInstance(ctx, option)
+	if err != nil {
+		t.Fatalf("failed to describe instance: %v", err)
+	}
+	if status.MachineType != "n1-standard-4" {
+		t.Errorf("expected machine type to be left unchanged, got %s", status.MachineType)
+	}
+}
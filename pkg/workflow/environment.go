@@ -0,0 +1,120 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/toVersus/wbtemporal/pkg/activity"
+	"github.com/toVersus/wbtemporal/pkg/executor/googleapi"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	CreateEnvironmentTaskQueue = "CREATE_ENVIRONMENT_TASK_QUEUE"
+	ListEnvironmentsTaskQueue  = "LIST_ENVIRONMENTS_TASK_QUEUE"
+	DeleteEnvironmentTaskQueue = "DELETE_ENVIRONMENT_TASK_QUEUE"
+)
+
+// withEnvironmentActivityOptions returns the context to run activities for notebook environments.
+func withEnvironmentActivityOptions(ctx workflow.Context) workflow.Context {
+	return workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		// アクティビティの実行時間のタイムアウト値
+		StartToCloseTimeout: 1 * time.Minute,
+		// アクティビティを 5 秒間隔で 36 回の合計 3 分間リトライする
+		// 環境はイメージの参照を登録するだけなので、スケジュールの操作と同じ程度に設定
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:        5 * time.Second,
+			MaximumInterval:        5 * time.Second,
+			MaximumAttempts:        36,
+			NonRetryableErrorTypes: []string{activity.ErrLongRunningOperationFailed},
+		},
+	})
+}
+
+// CreateEnvironment registers the VM image or the container image in the environment catalog of the zone.
+// The existing environment is returned as is, even if the image differs from the option.
+func CreateEnvironment(ctx workflow.Context, option *googleapi.EnvironmentOption) (*googleapi.EnvironmentStatus, error) {
+	var wa *activity.WorkbenchActivity
+
+	logger := defaultEnvironmentWorkflowLogger(ctx, option)
+	ctx = withEnvironmentActivityOptions(ctx)
+
+	logger.Info("Checking for the existence of notebook environment")
+	var exist bool
+	if err := workflow.ExecuteActivity(ctx, wa.ExistEnvironment, option).Get(ctx, &exist); err != nil {
+		return nil, fmt.Errorf("failed to check for the existence of notebook environment: %w", err)
+	}
+
+	if exist {
+		logger.Info("Notebook environment already exists")
+	} else {
+		logger.Info("Creating notebook environment")
+		var opName string
+		if err := workflow.ExecuteActivity(ctx, wa.CreateEnvironment, option).Get(ctx, &opName); err != nil {
+			return nil, fmt.Errorf("failed to create notebook environment: %w", err)
+		}
+
+		logger.Info("Waiting for notebook environment created")
+		if err := workflow.ExecuteActivity(ctx, wa.OperationCompleted, opName).Get(ctx, nil); err != nil {
+			return nil, fmt.Errorf("failed to watch operation to create notebook environment: %w", err)
+		}
+	}
+
+	var status googleapi.EnvironmentStatus
+	if err := workflow.ExecuteActivity(ctx, wa.DescribeEnvironment, option).Get(ctx, &status); err != nil {
+		return nil, fmt.Errorf("failed to describe notebook environment: %w", err)
+	}
+
+	logger.Info("Notebook environment created successfully!")
+	return &status, nil
+}
+
+// ListEnvironments returns the environment catalog in the project and zone of the option.
+func ListEnvironments(ctx workflow.Context, option *googleapi.EnvironmentOption) ([]googleapi.EnvironmentStatus, error) {
+	var wa *activity.WorkbenchActivity
+
+	logger := defaultEnvironmentWorkflowLogger(ctx, option)
+	ctx = withEnvironmentActivityOptions(ctx)
+
+	logger.Info("Listing notebook environments")
+	var environments []googleapi.EnvironmentStatus
+	if err := workflow.ExecuteActivity(ctx, wa.ListEnvironments, option).Get(ctx, &environments); err != nil {
+		return nil, fmt.Errorf("failed to list notebook environments: %w", err)
+	}
+
+	logger.Info("Notebook environments listed successfully!", "Count", len(environments))
+	return environments, nil
+}
+
+// DeleteEnvironment removes the environment from the catalog. Workbench instances created from it are not affected.
+func DeleteEnvironment(ctx workflow.Context, option *googleapi.EnvironmentOption) error {
+	var wa *activity.WorkbenchActivity
+
+	logger := defaultEnvironmentWorkflowLogger(ctx, option)
+	ctx = withEnvironmentActivityOptions(ctx)
+
+	logger.Info("Checking for the existence of notebook environment")
+	var exist bool
+	if err := workflow.ExecuteActivity(ctx, wa.ExistEnvironment, option).Get(ctx, &exist); err != nil {
+		return fmt.Errorf("failed to check for the existence of notebook environment: %w", err)
+	}
+	if !exist {
+		logger.Info("Notebook environment already deleted")
+		return nil
+	}
+
+	logger.Info("Deleting notebook environment")
+	var opName string
+	if err := workflow.ExecuteActivity(ctx, wa.DeleteEnvironment, option).Get(ctx, &opName); err != nil {
+		return fmt.Errorf("failed to delete notebook environment: %w", err)
+	}
+
+	logger.Info("Waiting for notebook environment deleted")
+	if err := workflow.ExecuteActivity(ctx, wa.OperationCompleted, opName).Get(ctx, nil); err != nil {
+		return fmt.Errorf("failed to watch operation to delete notebook environment: %w", err)
+	}
+
+	logger.Info("Notebook environment deleted successfully!")
+	return nil
+}
@@ -41,6 +41,14 @@ func CreateUserServer(ctx workflow.Context, option *jupyterhubapi.Option) (*jupy
 		return nil, fmt.Errorf("failed to get or create user: %w", err)
 	}
 
+	// 変更前に開始したワークフローの履歴には待機のアクティビティが含まれないので、新しく開始したワークフローのみ待機する
+	if v := workflow.GetVersion(ctx, "wait-steady-server", workflow.DefaultVersion, 1); v == 1 {
+		logger.Info("Waiting for user server to finish spawning or stopping")
+		if err := workflow.ExecuteActivity(ctx, wa.WaitUserServerSteady, option).Get(ctx, nil); err != nil {
+			return nil, fmt.Errorf("failed to wait for user server to finish spawning or stopping: %w", err)
+		}
+	}
+
 	logger.Info("Checking for the existence and readiness of user server")
 	var exist bool
 	if err := workflow.ExecuteActivity(ctx, wa.ExistUserServer, option).Get(ctx, &exist); err != nil {
@@ -95,11 +103,18 @@ func DeleteUserServer(ctx workflow.Context, option *jupyterhubapi.Option) error
 		return fmt.Errorf("failed to get or create user: %w", err)
 	}
 
+	// 変更前に開始したワークフローの履歴には待機のアクティビティが含まれないので、新しく開始したワークフローのみ待機する
+	if v := workflow.GetVersion(ctx, "wait-steady-server", workflow.DefaultVersion, 1); v == 1 {
+		logger.Info("Waiting for user server to finish spawning or stopping")
+		if err := workflow.ExecuteActivity(ctx, wa.WaitUserServerSteady, option).Get(ctx, nil); err != nil {
+			return fmt.Errorf("failed to wait for user server to finish spawning or stopping: %w", err)
+		}
+	}
+
 	logger.Info("Checking for the existence and readiness of user server")
 	var exist bool
 	if err := workflow.ExecuteActivity(ctx, wa.ExistUserServer, option).Get(ctx, &exist); err != nil {
-		logger.Info("User server already not exists")
-		return nil
+		return fmt.Errorf("failed to check for the existence and readiness of user server: %w", err)
 	}
 	if !exist {
 		logger.Info("User server already deleted")
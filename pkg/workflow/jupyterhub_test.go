@@ -0,0 +1,269 @@
+package workflow_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/toVersus/wbtemporal/pkg/activity"
+	"github.com/toVersus/wbtemporal/pkg/client/jupyterhub"
+	"github.com/toVersus/wbtemporal/pkg/executor/jupyterhubapi"
+	"github.com/toVersus/wbtemporal/pkg/workflow"
+)
+
+// hubExecutor serves CreateUserServer and DeleteUserServer activities, which can not be mocked
+// because the test environment looks up mocks by name and the workflows share the same names.
+type hubExecutor struct {
+	jupyterhubapi.Executor
+	created, deleted bool
+}
+
+func (e *hubExecutor) CreateUserServer(context.Context, *jupyterhubapi.Option) error {
+	e.created = true
+	return nil
+}
+
+func (e *hubExecutor) DeleteUserServer(context.Context, *jupyterhubapi.Option) error {
+	e.deleted = true
+	return nil
+}
+
+func newJupyterHubTestEnv() (*testsuite.TestWorkflowEnvironment, *hubExecutor) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestWorkflowEnvironment()
+	e := &hubExecutor{}
+	env.RegisterActivity(&activity.JupyterHubActivity{Executor: e})
+	return env, e
+}
+
+var errWaitFailed = temporal.NewNonRetryableApplicationError("non-retryable error found in waiting to become ready", activity.ErrOperationFailed, errors.New("server failed to spawn"))
+
+func testJupyterHubOption() *jupyterhubapi.Option {
+	return &jupyterhubapi.Option{User: "alice", Server: "test-server"}
+}
+
+func TestCreateUserServer(t *testing.T) {
+	env, e := newJupyterHubTestEnv()
+	r := recordActivities(env)
+	var ja *activity.JupyterHubActivity
+	env.OnActivity(ja.GetOrCreateUser, mock.Anything, mock.Anything).Return(&jupyterhub.User{}, nil)
+	env.OnActivity(ja.WaitUserServerSteady, mock.Anything, mock.Anything).Return(nil, nil)
+	env.OnActivity(ja.ExistUserServer, mock.Anything, mock.Anything).Return(false, nil)
+	// 起動の完了までリトライして待つことを確認する
+	env.OnActivity(ja.WaitUserServerReady, mock.Anything, mock.Anything).Return(errors.New("instance is not ready yet")).Times(2)
+	env.OnActivity(ja.WaitUserServerReady, mock.Anything, mock.Anything).Return(nil).Once()
+	env.OnActivity(ja.GetUserServer, mock.Anything, mock.Anything).Return(&jupyterhubapi.Status{Name: "test-server", URL: "/user/alice/test-server/"}, nil)
+
+	env.ExecuteWorkflow(workflow.CreateUserServer, testJupyterHubOption())
+	if err := env.GetWorkflowError(); err != nil {
+		t.Fatalf("workflow failed: %v", err)
+	}
+	var status jupyterhubapi.Status
+	if err := env.GetWorkflowResult(&status); err != nil {
+		t.Fatalf("failed to get workflow result: %v", err)
+	}
+	if status.URL == "" {
+		t.Errorf("expected URL of user server")
+	}
+	assertCalls(t, r, "GetOrCreateUser", "WaitUserServerSteady", "ExistUserServer", "CreateUserServer", "WaitUserServerReady", "GetUserServer")
+	if n := r.count("WaitUserServerReady"); n != 3 {
+		t.Errorf("expected 3 attempts to wait for user server, got %d", n)
+	}
+	if !e.created {
+		t.Error("expected user server to be created")
+	}
+}
+
+func TestCreateUserServerAlreadyExists(t *testing.T) {
+	env, e := newJupyterHubTestEnv()
+	r := recordActivities(env)
+	var ja *activity.JupyterHubActivity
+	env.OnActivity(ja.GetOrCreateUser, mock.Anything, mock.Anything).Return(&jupyterhub.User{}, nil)
+	env.OnActivity(ja.WaitUserServerSteady, mock.Anything, mock.Anything).Return(nil, nil)
+	env.OnActivity(ja.ExistUserServer, mock.Anything, mock.Anything).Return(true, nil)
+	env.OnActivity(ja.GetUserServer, mock.Anything, mock.Anything).Return(&jupyterhubapi.Status{Name: "test-server"}, nil)
+
+	env.ExecuteWorkflow(workflow.CreateUserServer, testJupyterHubOption())
+	if err := env.GetWorkflowError(); err != nil {
+		t.Fatalf("workflow failed: %v", err)
+	}
+	assertCalls(t, r, "GetOrCreateUser", "WaitUserServerSteady", "ExistUserServer", "GetUserServer")
+	if e.created {
+		t.Error("expected existing user server not to be created again")
+	}
+}
+
+func TestCreateUserServerWhileStopping(t *testing.T) {
+	env, e := newJupyterHubTestEnv()
+	r := recordActivities(env)
+	var ja *activity.JupyterHubActivity
+	env.OnActivity(ja.GetOrCreateUser, mock.Anything, mock.Anything).Return(&jupyterhub.User{}, nil)
+	// 停止中のサーバは停止が完了するまで待ってから作成し直すことを確認する
+	env.OnActivity(ja.WaitUserServerSteady, mock.Anything, mock.Anything).Return(nil, errors.New("user server is Stopping")).Times(2)
+	env.OnActivity(ja.WaitUserServerSteady, mock.Anything, mock.Anything).Return(nil, nil).Once()
+	env.OnActivity(ja.ExistUserServer, mock.Anything, mock.Anything).Return(false, nil)
+	env.OnActivity(ja.WaitUserServerReady, mock.Anything, mock.Anything).Return(nil)
+	env.OnActivity(ja.GetUserServer, mock.Anything, mock.Anything).Return(&jupyterhubapi.Status{Name: "test-server"}, nil)
+
+	env.ExecuteWorkflow(workflow.CreateUserServer, testJupyterHubOption())
+	if err := env.GetWorkflowError(); err != nil {
+		t.Fatalf("workflow failed: %v", err)
+	}
+	assertCalls(t, r, "GetOrCreateUser", "WaitUserServerSteady", "ExistUserServer", "CreateUserServer", "WaitUserServerReady", "GetUserServer")
+	if n := r.count("WaitUserServerSteady"); n != 3 {
+		t.Errorf("expected 3 attempts to wait for stopping user server, got %d", n)
+	}
+	if !e.created {
+		t.Error("expected user server to be created after stopped")
+	}
+}
+
+func TestCreateUserServerExistFailed(t *testing.T) {
+	env, e := newJupyterHubTestEnv()
+	r := recordActivities(env)
+	var ja *activity.JupyterHubActivity
+	env.OnActivity(ja.GetOrCreateUser, mock.Anything, mock.Anything).Return(&jupyterhub.User{}, nil)
+	env.OnActivity(ja.WaitUserServerSteady, mock.Anything, mock.Anything).Return(nil, nil)
+	env.OnActivity(ja.ExistUserServer, mock.Anything, mock.Anything).Return(false,
+		temporal.NewNonRetryableApplicationError("forbidden", activity.ErrForbidden, nil))
+
+	env.ExecuteWorkflow(workflow.CreateUserServer, testJupyterHubOption())
+	if err := env.GetWorkflowError(); !hasErrorType(err, activity.ErrForbidden) {
+		t.Fatalf("expected %s error, got %v", activity.ErrForbidden, err)
+	}
+	assertCalls(t, r, "GetOrCreateUser", "WaitUserServerSteady", "ExistUserServer")
+	if e.created {
+		t.Error("expected user server not to be created")
+	}
+}
+
+func TestCreateUserServerNotReady(t *testing.T) {
+	env, _ := newJupyterHubTestEnv()
+	r := recordActivities(env)
+	var ja *activity.JupyterHubActivity
+	env.OnActivity(ja.GetOrCreateUser, mock.Anything, mock.Anything).Return(&jupyterhub.User{}, nil)
+	env.OnActivity(ja.WaitUserServerSteady, mock.Anything, mock.Anything).Return(nil, nil)
+	env.OnActivity(ja.ExistUserServer, mock.Anything, mock.Anything).Return(false, nil)
+	env.OnActivity(ja.WaitUserServerReady, mock.Anything, mock.Anything).Return(errors.New("instance is not ready yet"))
+
+	env.ExecuteWorkflow(workflow.CreateUserServer, testJupyterHubOption())
+	if err := env.GetWorkflowError(); err == nil {
+		t.Fatal("expected workflow to fail when user server never becomes ready")
+	}
+	assertCalls(t, r, "GetOrCreateUser", "WaitUserServerSteady", "ExistUserServer", "CreateUserServer", "WaitUserServerReady")
+	if n := r.count("WaitUserServerReady"); n != 72 {
+		t.Errorf("expected 72 attempts to wait for user server, got %d", n)
+	}
+}
+
+func TestCreateUserServerWaitFailed(t *testing.T) {
+	env, _ := newJupyterHubTestEnv()
+	r := recordActivities(env)
+	var ja *activity.JupyterHubActivity
+	env.OnActivity(ja.GetOrCreateUser, mock.Anything, mock.Anything).Return(&jupyterhub.User{}, nil)
+	env.OnActivity(ja.WaitUserServerSteady, mock.Anything, mock.Anything).Return(nil, nil)
+	env.OnActivity(ja.ExistUserServer, mock.Anything, mock.Anything).Return(false, nil)
+	env.OnActivity(ja.WaitUserServerReady, mock.Anything, mock.Anything).Return(errWaitFailed)
+
+	env.ExecuteWorkflow(workflow.CreateUserServer, testJupyterHubOption())
+	if err := env.GetWorkflowError(); !hasErrorType(err, activity.ErrOperationFailed) {
+		t.Fatalf("expected %s error, got %v", activity.ErrOperationFailed, err)
+	}
+	if n := r.count("WaitUserServerReady"); n != 1 {
+		t.Errorf("expected failed wait not to be retried, got %d attempts", n)
+	}
+}
+
+func TestDeleteUserServer(t *testing.T) {
+	env, e := newJupyterHubTestEnv()
+	r := recordActivities(env)
+	var ja *activity.JupyterHubActivity
+	env.OnActivity(ja.GetOrCreateUser, mock.Anything, mock.Anything).Return(&jupyterhub.User{}, nil)
+	env.OnActivity(ja.WaitUserServerSteady, mock.Anything, mock.Anything).Return(nil, nil)
+	env.OnActivity(ja.ExistUserServer, mock.Anything, mock.Anything).Return(true, nil)
+	env.OnActivity(ja.WaitUserServerDeleted, mock.Anything, mock.Anything).Return(errors.New("instance is not deleted yet")).Once()
+	env.OnActivity(ja.WaitUserServerDeleted, mock.Anything, mock.Anything).Return(nil).Once()
+
+	env.ExecuteWorkflow(workflow.DeleteUserServer, testJupyterHubOption())
+	if err := env.GetWorkflowError(); err != nil {
+		t.Fatalf("workflow failed: %v", err)
+	}
+	assertCalls(t, r, "GetOrCreateUser", "WaitUserServerSteady", "ExistUserServer", "DeleteUserServer", "WaitUserServerDeleted")
+	if !e.deleted {
+		t.Error("expected user server to be deleted")
+	}
+}
+
+func TestDeleteUserServerExistFailed(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		errType  string
+		attempts int
+	}{
+		{
+			name:     "unauthorized",
+			err:      temporal.NewNonRetryableApplicationError("unauthorized", activity.ErrUnauthorized, nil),
+			errType:  activity.ErrUnauthorized,
+			attempts: 1,
+		},
+		{
+			name:     "forbidden",
+			err:      temporal.NewNonRetryableApplicationError("forbidden", activity.ErrForbidden, nil),
+			errType:  activity.ErrForbidden,
+			attempts: 1,
+		},
+		{
+			name:     "server error",
+			err:      temporal.NewApplicationError("internal server error", activity.ErrServerError),
+			errType:  activity.ErrServerError,
+			attempts: 36,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			env, e := newJupyterHubTestEnv()
+			r := recordActivities(env)
+			var ja *activity.JupyterHubActivity
+			env.OnActivity(ja.GetOrCreateUser, mock.Anything, mock.Anything).Return(&jupyterhub.User{}, nil)
+			env.OnActivity(ja.WaitUserServerSteady, mock.Anything, mock.Anything).Return(nil, nil)
+			env.OnActivity(ja.ExistUserServer, mock.Anything, mock.Anything).Return(false, tt.err)
+
+			// 存在確認に失敗した場合は削除済みとみなさずに、型付きのエラーで失敗することを確認する
+			env.ExecuteWorkflow(workflow.DeleteUserServer, testJupyterHubOption())
+			if err := env.GetWorkflowError(); !hasErrorType(err, tt.errType) {
+				t.Fatalf("expected %s error, got %v", tt.errType, err)
+			}
+			assertCalls(t, r, "GetOrCreateUser", "WaitUserServerSteady", "ExistUserServer")
+			if n := r.count("ExistUserServer"); n != tt.attempts {
+				t.Errorf("expected %d attempts to check for the existence, got %d", tt.attempts, n)
+			}
+			if e.deleted {
+				t.Error("expected user server not to be deleted")
+			}
+		})
+	}
+}
+
+func TestDeleteUserServerNotDeleted(t *testing.T) {
+	env, _ := newJupyterHubTestEnv()
+	r := recordActivities(env)
+	var ja *activity.JupyterHubActivity
+	env.OnActivity(ja.GetOrCreateUser, mock.Anything, mock.Anything).Return(&jupyterhub.User{}, nil)
+	env.OnActivity(ja.WaitUserServerSteady, mock.Anything, mock.Anything).Return(nil, nil)
+	env.OnActivity(ja.ExistUserServer, mock.Anything, mock.Anything).Return(true, nil)
+	env.OnActivity(ja.WaitUserServerDeleted, mock.Anything, mock.Anything).Return(errors.New("instance is not deleted yet"))
+
+	env.ExecuteWorkflow(workflow.DeleteUserServer, testJupyterHubOption())
+	if err := env.GetWorkflowError(); err == nil {
+		t.Fatal("expected workflow to fail when user server is never deleted")
+	}
+	if n := r.count("WaitUserServerDeleted"); n != 36 {
+		t.Errorf("expected 36 attempts to wait for deletion, got %d", n)
+	}
+}
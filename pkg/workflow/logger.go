@@ -15,6 +15,38 @@ func defaultGoogleAPIWorkflowLogger(ctx workflow.Context, option *googleapi.Opti
 	)
 }
 
+func defaultExecutionWorkflowLogger(ctx workflow.Context, option *googleapi.ExecutionOption) log.Logger {
+	return log.With(workflow.GetLogger(ctx),
+		"ProjectID", option.ProjectId,
+		"ExecutionName", option.Name,
+		"ExecutionLocation", option.Location,
+	)
+}
+
+func defaultScheduleWorkflowLogger(ctx workflow.Context, option *googleapi.ScheduleOption) log.Logger {
+	return log.With(workflow.GetLogger(ctx),
+		"ProjectID", option.ProjectId,
+		"ScheduleName", option.Name,
+		"ScheduleLocation", option.Location,
+	)
+}
+
+func defaultEnvironmentWorkflowLogger(ctx workflow.Context, option *googleapi.EnvironmentOption) log.Logger {
+	return log.With(workflow.GetLogger(ctx),
+		"ProjectID", option.ProjectId,
+		"EnvironmentName", option.Name,
+		"EnvironmentZone", option.Zone,
+	)
+}
+
+func defaultRuntimeWorkflowLogger(ctx workflow.Context, option *googleapi.RuntimeOption) log.Logger {
+	return log.With(workflow.GetLogger(ctx),
+		"ProjectID", option.ProjectId,
+		"RuntimeName", option.Name,
+		"RuntimeLocation", option.Location,
+	)
+}
+
 func defaultJupyterHubWorkflowLogger(ctx workflow.Context, option *jupyterhubapi.Option) log.Logger {
 	return log.With(workflow.GetLogger(ctx),
 		"User", option.User,
@@ -0,0 +1,70 @@
+package workflow
+
+// Registration is the workflow served by the worker polling the task queue.
+type Registration struct {
+	// Name describes what the worker does in log messages, e.g. "create workspace"
+	Name      string
+	TaskQueue string
+	Workflow  interface{}
+}
+
+// Workflows registered to each worker command. Both workers and the replayer are built from these lists,
+// so that new workflows are always replayed against recorded histories once they are served by workers.
+var (
+	WorkbenchRegistrations = []Registration{
+		{Name: "create workspace", TaskQueue: CreateWorkbenchTaskQueue, Workflow: CreateWorkbench},
+		{Name: "delete workspace", TaskQueue: DeleteWorkbenchTaskQueue, Workflow: DeleteWorkbench},
+		{Name: "start workspace", TaskQueue: StartWorkbenchTaskQueue, Workflow: StartWorkbench},
+		{Name: "stop workspace", TaskQueue: StopWorkbenchTaskQueue, Workflow: StopWorkbench},
+		{Name: "set workspace labels", TaskQueue: SetWorkbenchLabelsTaskQueue, Workflow: SetWorkbenchLabels},
+		{Name: "update workspace idle shutdown", TaskQueue: UpdateWorkbenchIdleShutdownTaskQueue, Workflow: UpdateWorkbenchIdleShutdown},
+		{Name: "resize workspace", TaskQueue: ResizeWorkbenchTaskQueue, Workflow: ResizeWorkbench},
+		{Name: "set workspace accelerator", TaskQueue: SetWorkbenchAcceleratorTaskQueue, Workflow: SetWorkbenchAccelerator},
+		{Name: "upgrade workspace", TaskQueue: UpgradeWorkbenchTaskQueue, Workflow: UpgradeWorkbench},
+		{Name: "rollback workspace", TaskQueue: RollbackWorkbenchTaskQueue, Workflow: RollbackWorkbench},
+		{Name: "reset workspace", TaskQueue: ResetWorkbenchTaskQueue, Workflow: ResetWorkbench},
+		{Name: "diagnose workspace", TaskQueue: DiagnoseWorkbenchTaskQueue, Workflow: DiagnoseWorkbench},
+		{Name: "execute notebook", TaskQueue: ExecuteNotebookTaskQueue, Workflow: ExecuteNotebook},
+		{Name: "create schedule", TaskQueue: CreateScheduleTaskQueue, Workflow: CreateSchedule},
+		{Name: "list schedules", TaskQueue: ListSchedulesTaskQueue, Workflow: ListSchedules},
+		{Name: "delete schedule", TaskQueue: DeleteScheduleTaskQueue, Workflow: DeleteSchedule},
+		{Name: "trigger schedule", TaskQueue: TriggerScheduleTaskQueue, Workflow: TriggerSchedule},
+		{Name: "create environment", TaskQueue: CreateEnvironmentTaskQueue, Workflow: CreateEnvironment},
+		{Name: "list environments", TaskQueue: ListEnvironmentsTaskQueue, Workflow: ListEnvironments},
+		{Name: "delete environment", TaskQueue: DeleteEnvironmentTaskQueue, Workflow: DeleteEnvironment},
+	}
+
+	RuntimeRegistrations = []Registration{
+		{Name: "create runtime", TaskQueue: CreateRuntimeTaskQueue, Workflow: CreateRuntime},
+		{Name: "delete runtime", TaskQueue: DeleteRuntimeTaskQueue, Workflow: DeleteRuntime},
+		{Name: "start runtime", TaskQueue: StartRuntimeTaskQueue, Workflow: StartRuntime},
+		{Name: "stop runtime", TaskQueue: StopRuntimeTaskQueue, Workflow: StopRuntime},
+		{Name: "switch runtime", TaskQueue: SwitchRuntimeTaskQueue, Workflow: SwitchRuntime},
+	}
+
+	JupyterHubRegistrations = []Registration{
+		{Name: "create JupyterHub user server", TaskQueue: CreateJupyterHubTaskQueue, Workflow: CreateUserServer},
+		{Name: "delete JupyterHub user server", TaskQueue: DeleteJupyterHubTaskQueue, Workflow: DeleteUserServer},
+	}
+)
+
+// Registrations returns the workflows registered to all worker commands.
+func Registrations() []Registration {
+	var all []Registration
+	all = append(all, WorkbenchRegistrations...)
+	all = append(all, RuntimeRegistrations...)
+	all = append(all, JupyterHubRegistrations...)
+	return all
+}
+
+// Registerer is implemented by both worker.Worker and worker.WorkflowReplayer.
+type Registerer interface {
+	RegisterWorkflow(w interface{})
+}
+
+// RegisterAll registers all workflows served by worker commands, e.g. to replay their histories.
+func RegisterAll(r Registerer) {
+	for _, reg := range Registrations() {
+		r.RegisterWorkflow(reg.Workflow)
+	}
+}
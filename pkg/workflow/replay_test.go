@@ -0,0 +1,44 @@
+package workflow_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"go.temporal.io/sdk/worker"
+
+	"github.com/toVersus/wbtemporal/pkg/logger"
+	"github.com/toVersus/wbtemporal/pkg/workflow"
+)
+
+// TestReplayHistories replays the recorded histories to detect non-deterministic changes in workflows.
+// Record new histories into testdata/histories with "replay --save-dir" when workflows are added or changed.
+func TestReplayHistories(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "..", "testdata", "histories", "*.json"))
+	if err != nil {
+		t.Fatalf("failed to list history files: %v", err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no workflow histories found in testdata/histories")
+	}
+
+	replayer := worker.NewWorkflowReplayer()
+	workflow.RegisterAll(replayer)
+	for _, file := range files {
+		file := file
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			if err := replayer.ReplayWorkflowHistoryFromJSONFile(logger.NewDefaultLogger("error"), file); err != nil {
+				t.Errorf("failed to replay %s: %v", file, err)
+			}
+		})
+	}
+}
+
+func TestRegistrationsAreUnique(t *testing.T) {
+	taskQueues := map[string]bool{}
+	for _, r := range workflow.Registrations() {
+		if taskQueues[r.TaskQueue] {
+			t.Errorf("task queue %s is registered more than once", r.TaskQueue)
+		}
+		taskQueues[r.TaskQueue] = true
+	}
+}
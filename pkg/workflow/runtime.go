@@ -0,0 +1,297 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/toVersus/wbtemporal/pkg/activity"
+	"github.com/toVersus/wbtemporal/pkg/executor/googleapi"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	CreateRuntimeTaskQueue = "CREATE_RUNTIME_TASK_QUEUE"
+	DeleteRuntimeTaskQueue = "DELETE_RUNTIME_TASK_QUEUE"
+	StartRuntimeTaskQueue  = "START_RUNTIME_TASK_QUEUE"
+	StopRuntimeTaskQueue   = "STOP_RUNTIME_TASK_QUEUE"
+	SwitchRuntimeTaskQueue = "SWITCH_RUNTIME_TASK_QUEUE"
+)
+
+// withRuntimeActivityOptions returns the context to operate managed notebook runtimes.
+func withRuntimeActivityOptions(ctx workflow.Context) workflow.Context {
+	return workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		// アクティビティの実行時間のタイムアウト値
+		StartToCloseTimeout: 1 * time.Minute,
+		// アクティビティを 10 秒間隔で 90 回の合計 15 分間リトライする
+		// ランタイムは VM に加えて Google 管理のプロキシも準備するので、Workbench のインスタンスよりも長めに設定
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:        10 * time.Second,
+			MaximumInterval:        10 * time.Second,
+			MaximumAttempts:        90,
+			NonRetryableErrorTypes: []string{activity.ErrLongRunningOperationFailed, activity.ErrUnexpectedState},
+		},
+	})
+}
+
+func CreateRuntime(ctx workflow.Context, option *googleapi.RuntimeOption) (*googleapi.RuntimeStatus, error) {
+	var ra *activity.RuntimeActivity
+
+	logger := defaultRuntimeWorkflowLogger(ctx, option)
+
+	ctx = withRuntimeActivityOptions(ctx)
+
+	logger.Info("Checking for the existence of runtime")
+	var exist bool
+	if err := workflow.ExecuteActivity(ctx, ra.ExistRuntime, option).Get(ctx, &exist); err != nil {
+		return nil, fmt.Errorf("failed to check for the existence of runtime: %w", err)
+	}
+
+	if exist {
+		logger.Info("Runtime already exists")
+		status, err := waitForRuntimeSteadyState(ctx, option)
+		if err != nil {
+			return nil, err
+		}
+		if status.Status.IsStopped() {
+			logger.Info("Starting stopped runtime")
+			if err := startRuntime(ctx, option); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		logger.Info("Creating new runtime")
+		var opName string
+		if err := workflow.ExecuteActivity(ctx, ra.CreateRuntime, option).Get(ctx, &opName); err != nil {
+			return nil, fmt.Errorf("failed to create runtime: %w", err)
+		}
+
+		logger.Info("Waiting for runtime created")
+		if err := workflow.ExecuteActivity(ctx, ra.RuntimeOperationCompleted, opName).Get(ctx, nil); err != nil {
+			return nil, fmt.Errorf("failed to watch operation to create runtime: %w", err)
+		}
+	}
+
+	var status googleapi.RuntimeStatus
+	logger.Info("Getting URL for accessing to runtime")
+	if err := workflow.ExecuteActivity(ctx, ra.GetRuntimeURL, option).Get(ctx, &status); err != nil {
+		return nil, fmt.Errorf("failed to get URL of runtime: %w", err)
+	}
+
+	logger.Info("Runtime created successfully!")
+	return &status, nil
+}
+
+func DeleteRuntime(ctx workflow.Context, option *googleapi.RuntimeOption) error {
+	var ra *activity.RuntimeActivity
+
+	logger := defaultRuntimeWorkflowLogger(ctx, option)
+
+	ctx = withRuntimeActivityOptions(ctx)
+
+	logger.Info("Checking for the existence of runtime")
+	var exist bool
+	if err := workflow.ExecuteActivity(ctx, ra.ExistRuntime, option).Get(ctx, &exist); err != nil {
+		return fmt.Errorf("failed to check for the existence of runtime: %w", err)
+	}
+	if !exist {
+		logger.Info("Runtime already deleted")
+		return nil
+	}
+
+	// 作成中や停止中のランタイムは削除できないので、状態が落ち着くのを待つ
+	if _, err := waitForRuntimeSteadyState(ctx, option); err != nil {
+		return err
+	}
+
+	logger.Info("Deleting runtime")
+	var opName string
+	if err := workflow.ExecuteActivity(ctx, ra.DeleteRuntime, option).Get(ctx, &opName); err != nil {
+		return fmt.Errorf("failed to delete runtime: %w", err)
+	}
+
+	logger.Info("Waiting for runtime deleted")
+	if err := workflow.ExecuteActivity(ctx, ra.RuntimeOperationCompleted, opName).Get(ctx, nil); err != nil {
+		return fmt.Errorf("failed to watch operation to delete runtime: %w", err)
+	}
+
+	logger.Info("Runtime deleted successfully!")
+	return nil
+}
+
+func StartRuntime(ctx workflow.Context, option *googleapi.RuntimeOption) (*googleapi.RuntimeStatus, error) {
+	var ra *activity.RuntimeActivity
+
+	logger := defaultRuntimeWorkflowLogger(ctx, option)
+
+	ctx = withRuntimeActivityOptions(ctx)
+
+	logger.Info("Checking for the existence of runtime")
+	var exist bool
+	if err := workflow.ExecuteActivity(ctx, ra.ExistRuntime, option).Get(ctx, &exist); err != nil {
+		return nil, fmt.Errorf("failed to check for the existence of runtime: %w", err)
+	}
+	if !exist {
+		return nil, temporal.NewNonRetryableApplicationError("runtime not found", activity.ErrNotFound, nil)
+	}
+
+	logger.Info("Starting runtime")
+	if err := startRuntime(ctx, option); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Getting URL for accessing to runtime")
+	var status googleapi.RuntimeStatus
+	if err := workflow.ExecuteActivity(ctx, ra.GetRuntimeURL, option).Get(ctx, &status); err != nil {
+		return nil, fmt.Errorf("failed to get URL of runtime: %w", err)
+	}
+
+	logger.Info("Runtime started successfully!")
+	return &status, nil
+}
+
+func StopRuntime(ctx workflow.Context, option *googleapi.RuntimeOption) error {
+	var ra *activity.RuntimeActivity
+
+	logger := defaultRuntimeWorkflowLogger(ctx, option)
+
+	ctx = withRuntimeActivityOptions(ctx)
+
+	logger.Info("Checking for the existence of runtime")
+	var exist bool
+	if err := workflow.ExecuteActivity(ctx, ra.ExistRuntime, option).Get(ctx, &exist); err != nil {
+		return fmt.Errorf("failed to check for the existence of runtime: %w", err)
+	}
+	if !exist {
+		return temporal.NewNonRetryableApplicationError("runtime not found", activity.ErrNotFound, nil)
+	}
+
+	logger.Info("Stopping runtime")
+	if err := stopRuntime(ctx, option); err != nil {
+		return err
+	}
+
+	logger.Info("Runtime stopped successfully!")
+	return nil
+}
+
+// SwitchRuntime changes the machine type and accelerator of the runtime.
+// The running runtime is stopped during the switch and started again afterwards.
+func SwitchRuntime(ctx workflow.Context, option *googleapi.RuntimeOption) (*googleapi.RuntimeStatus, error) {
+	var ra *activity.RuntimeActivity
+
+	logger := defaultRuntimeWorkflowLogger(ctx, option)
+
+	ctx = withRuntimeActivityOptions(ctx)
+
+	logger.Info("Checking for the existence of runtime")
+	var exist bool
+	if err := workflow.ExecuteActivity(ctx, ra.ExistRuntime, option).Get(ctx, &exist); err != nil {
+		return nil, fmt.Errorf("failed to check for the existence of runtime: %w", err)
+	}
+	if !exist {
+		return nil, temporal.NewNonRetryableApplicationError("runtime not found", activity.ErrNotFound, nil)
+	}
+
+	current, err := waitForRuntimeSteadyState(ctx, option)
+	if err != nil {
+		return nil, err
+	}
+	if current.MachineType == option.MachineType &&
+		strings.EqualFold(current.AcceleratorType, option.AcceleratorType) && current.AcceleratorCount == option.AcceleratorCount {
+		logger.Info("Runtime already has the machine type and accelerator", "MachineType", current.MachineType,
+			"AcceleratorType", current.AcceleratorType, "AcceleratorCount", current.AcceleratorCount)
+		return current, nil
+	}
+
+	running := current.Status.IsRunning()
+	if running {
+		logger.Info("Stopping runtime to switch machine type and accelerator")
+		if err := stopRuntime(ctx, option); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Info("Switching machine type and accelerator of runtime",
+		"From", fmt.Sprintf("%s (%s x %d)", current.MachineType, current.AcceleratorType, current.AcceleratorCount),
+		"To", fmt.Sprintf("%s (%s x %d)", option.MachineType, option.AcceleratorType, option.AcceleratorCount))
+	var opName string
+	if err := workflow.ExecuteActivity(ctx, ra.SwitchRuntime, option).Get(ctx, &opName); err != nil {
+		return nil, fmt.Errorf("failed to switch runtime: %w", err)
+	}
+
+	logger.Info("Waiting for runtime switched")
+	if err := workflow.ExecuteActivity(ctx, ra.RuntimeOperationCompleted, opName).Get(ctx, nil); err != nil {
+		return nil, fmt.Errorf("failed to watch operation to switch runtime: %w", err)
+	}
+
+	var status googleapi.RuntimeStatus
+	if running {
+		logger.Info("Starting runtime with new machine type and accelerator")
+		if err := startRuntime(ctx, option); err != nil {
+			return nil, err
+		}
+		logger.Info("Getting URL for accessing to runtime")
+		if err := workflow.ExecuteActivity(ctx, ra.GetRuntimeURL, option).Get(ctx, &status); err != nil {
+			return nil, fmt.Errorf("failed to get URL of runtime: %w", err)
+		}
+	} else {
+		if err := workflow.ExecuteActivity(ctx, ra.DescribeRuntime, option).Get(ctx, &status); err != nil {
+			return nil, fmt.Errorf("failed to describe runtime: %w", err)
+		}
+	}
+
+	logger.Info("Runtime switched successfully!")
+	return &status, nil
+}
+
+// waitForRuntimeSteadyState waits for the runtime in transition, e.g. provisioning or stopping, to be either running or stopped.
+func waitForRuntimeSteadyState(ctx workflow.Context, option *googleapi.RuntimeOption) (*googleapi.RuntimeStatus, error) {
+	var ra *activity.RuntimeActivity
+
+	logger := defaultRuntimeWorkflowLogger(ctx, option)
+
+	logger.Info("Waiting for runtime to be in steady state")
+	var status googleapi.RuntimeStatus
+	if err := workflow.ExecuteActivity(ctx, ra.GetRuntimeSteadyState, option).Get(ctx, &status); err != nil {
+		return nil, fmt.Errorf("failed to wait for runtime to be in steady state: %w", err)
+	}
+	return &status, nil
+}
+
+// startRuntime starts the runtime and waits for the operation to be done, with the activity options of ctx.
+func startRuntime(ctx workflow.Context, option *googleapi.RuntimeOption) error {
+	var ra *activity.RuntimeActivity
+
+	logger := defaultRuntimeWorkflowLogger(ctx, option)
+
+	var opName string
+	if err := workflow.ExecuteActivity(ctx, ra.StartRuntime, option).Get(ctx, &opName); err != nil {
+		return fmt.Errorf("failed to start runtime: %w", err)
+	}
+
+	logger.Info("Waiting for runtime started")
+	if err := workflow.ExecuteActivity(ctx, ra.RuntimeOperationCompleted, opName).Get(ctx, nil); err != nil {
+		return fmt.Errorf("failed to watch operation to start runtime: %w", err)
+	}
+	return nil
+}
+
+// stopRuntime stops the runtime and waits for the operation to be done, with the activity options of ctx.
+func stopRuntime(ctx workflow.Context, option *googleapi.RuntimeOption) error {
+	var ra *activity.RuntimeActivity
+
+	logger := defaultRuntimeWorkflowLogger(ctx, option)
+
+	var opName string
+	if err := workflow.ExecuteActivity(ctx, ra.StopRuntime, option).Get(ctx, &opName); err != nil {
+		return fmt.Errorf("failed to stop runtime: %w", err)
+	}
+
+	logger.Info("Waiting for runtime stopped")
+	if err := workflow.ExecuteActivity(ctx, ra.RuntimeOperationCompleted, opName).Get(ctx, nil); err != nil {
+		return fmt.Errorf("failed to watch operation to stop runtime: %w", err)
+	}
+	return nil
+}
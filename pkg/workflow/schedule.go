@@ -0,0 +1,149 @@
+package workflow
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/toVersus/wbtemporal/pkg/activity"
+	"github.com/toVersus/wbtemporal/pkg/executor/googleapi"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+)
+
+const (
+	CreateScheduleTaskQueue  = "CREATE_SCHEDULE_TASK_QUEUE"
+	ListSchedulesTaskQueue   = "LIST_SCHEDULES_TASK_QUEUE"
+	DeleteScheduleTaskQueue  = "DELETE_SCHEDULE_TASK_QUEUE"
+	TriggerScheduleTaskQueue = "TRIGGER_SCHEDULE_TASK_QUEUE"
+)
+
+// withScheduleActivityOptions returns the context to run activities for notebook schedules.
+func withScheduleActivityOptions(ctx workflow.Context) workflow.Context {
+	return workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		// アクティビティの実行時間のタイムアウト値
+		StartToCloseTimeout: 1 * time.Minute,
+		// アクティビティを 5 秒間隔で 36 回の合計 3 分間リトライする
+		// スケジュールの操作はインスタンスを伴わないので、インスタンスの削除を待つ時と同じ程度に設定
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:        5 * time.Second,
+			MaximumInterval:        5 * time.Second,
+			MaximumAttempts:        36,
+			NonRetryableErrorTypes: []string{activity.ErrLongRunningOperationFailed},
+		},
+	})
+}
+
+// CreateSchedule creates the notebook schedule running the execution template periodically.
+// The existing schedule is returned as is, even if the cron schedule or the template differs from the option.
+func CreateSchedule(ctx workflow.Context, option *googleapi.ScheduleOption) (*googleapi.ScheduleStatus, error) {
+	var wa *activity.WorkbenchActivity
+
+	logger := defaultScheduleWorkflowLogger(ctx, option)
+	ctx = withScheduleActivityOptions(ctx)
+
+	logger.Info("Checking for the existence of notebook schedule")
+	var exist bool
+	if err := workflow.ExecuteActivity(ctx, wa.ExistSchedule, option).Get(ctx, &exist); err != nil {
+		return nil, fmt.Errorf("failed to check for the existence of notebook schedule: %w", err)
+	}
+
+	if exist {
+		logger.Info("Notebook schedule already exists")
+	} else {
+		logger.Info("Creating notebook schedule", "CronSchedule", option.CronSchedule, "TimeZone", option.TimeZone)
+		var opName string
+		if err := workflow.ExecuteActivity(ctx, wa.CreateSchedule, option).Get(ctx, &opName); err != nil {
+			return nil, fmt.Errorf("failed to create notebook schedule: %w", err)
+		}
+
+		logger.Info("Waiting for notebook schedule created")
+		if err := workflow.ExecuteActivity(ctx, wa.OperationCompleted, opName).Get(ctx, nil); err != nil {
+			return nil, fmt.Errorf("failed to watch operation to create notebook schedule: %w", err)
+		}
+	}
+
+	var status googleapi.ScheduleStatus
+	if err := workflow.ExecuteActivity(ctx, wa.DescribeSchedule, option).Get(ctx, &status); err != nil {
+		return nil, fmt.Errorf("failed to describe notebook schedule: %w", err)
+	}
+
+	logger.Info("Notebook schedule created successfully!", "State", status.State)
+	return &status, nil
+}
+
+// ListSchedules returns the notebook schedules in the project and location of the option.
+func ListSchedules(ctx workflow.Context, option *googleapi.ScheduleOption) ([]googleapi.ScheduleStatus, error) {
+	var wa *activity.WorkbenchActivity
+
+	logger := defaultScheduleWorkflowLogger(ctx, option)
+	ctx = withScheduleActivityOptions(ctx)
+
+	logger.Info("Listing notebook schedules")
+	var schedules []googleapi.ScheduleStatus
+	if err := workflow.ExecuteActivity(ctx, wa.ListSchedules, option).Get(ctx, &schedules); err != nil {
+		return nil, fmt.Errorf("failed to list notebook schedules: %w", err)
+	}
+
+	logger.Info("Notebook schedules listed successfully!", "Count", len(schedules))
+	return schedules, nil
+}
+
+// DeleteSchedule deletes the notebook schedule. The executions created by the schedule are left as they are.
+func DeleteSchedule(ctx workflow.Context, option *googleapi.ScheduleOption) error {
+	var wa *activity.WorkbenchActivity
+
+	logger := defaultScheduleWorkflowLogger(ctx, option)
+	ctx = withScheduleActivityOptions(ctx)
+
+	logger.Info("Checking for the existence of notebook schedule")
+	var exist bool
+	if err := workflow.ExecuteActivity(ctx, wa.ExistSchedule, option).Get(ctx, &exist); err != nil {
+		return fmt.Errorf("failed to check for the existence of notebook schedule: %w", err)
+	}
+	if !exist {
+		logger.Info("Notebook schedule already deleted")
+		return nil
+	}
+
+	logger.Info("Deleting notebook schedule")
+	var opName string
+	if err := workflow.ExecuteActivity(ctx, wa.DeleteSchedule, option).Get(ctx, &opName); err != nil {
+		return fmt.Errorf("failed to delete notebook schedule: %w", err)
+	}
+
+	logger.Info("Waiting for notebook schedule deleted")
+	if err := workflow.ExecuteActivity(ctx, wa.OperationCompleted, opName).Get(ctx, nil); err != nil {
+		return fmt.Errorf("failed to watch operation to delete notebook schedule: %w", err)
+	}
+
+	logger.Info("Notebook schedule deleted successfully!")
+	return nil
+}
+
+// TriggerSchedule creates the execution from the notebook schedule immediately, and returns the schedule with the triggered execution.
+// It does not wait for the execution completed, use ExecuteNotebook to run the notebook and wait for the result.
+func TriggerSchedule(ctx workflow.Context, option *googleapi.ScheduleOption) (*googleapi.ScheduleStatus, error) {
+	var wa *activity.WorkbenchActivity
+
+	logger := defaultScheduleWorkflowLogger(ctx, option)
+	ctx = withScheduleActivityOptions(ctx)
+
+	logger.Info("Triggering notebook schedule")
+	var opName string
+	if err := workflow.ExecuteActivity(ctx, wa.TriggerSchedule, option).Get(ctx, &opName); err != nil {
+		return nil, fmt.Errorf("failed to trigger notebook schedule: %w", err)
+	}
+
+	logger.Info("Waiting for notebook schedule triggered")
+	if err := workflow.ExecuteActivity(ctx, wa.OperationCompleted, opName).Get(ctx, nil); err != nil {
+		return nil, fmt.Errorf("failed to watch operation to trigger notebook schedule: %w", err)
+	}
+
+	var status googleapi.ScheduleStatus
+	if err := workflow.ExecuteActivity(ctx, wa.DescribeSchedule, option).Get(ctx, &status); err != nil {
+		return nil, fmt.Errorf("failed to describe notebook schedule: %w", err)
+	}
+
+	logger.Info("Notebook schedule triggered successfully!")
+	return &status, nil
+}
@@ -1,7 +1,9 @@
 package workflow
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/toVersus/wbtemporal/pkg/activity"
@@ -15,6 +17,16 @@ const (
 	DeleteWorkbenchTaskQueue = "DELETE_WORKBENCH_TASK_QUEUE"
 	StartWorkbenchTaskQueue  = "START_WORKBENCH_TASK_QUEUE"
 	StopWorkbenchTaskQueue   = "STOP_WORKBENCH_TASK_QUEUE"
+
+	SetWorkbenchLabelsTaskQueue          = "SET_WORKBENCH_LABELS_TASK_QUEUE"
+	UpdateWorkbenchIdleShutdownTaskQueue = "UPDATE_WORKBENCH_IDLE_SHUTDOWN_TASK_QUEUE"
+	ResizeWorkbenchTaskQueue             = "RESIZE_WORKBENCH_TASK_QUEUE"
+	SetWorkbenchAcceleratorTaskQueue     = "SET_WORKBENCH_ACCELERATOR_TASK_QUEUE"
+	UpgradeWorkbenchTaskQueue            = "UPGRADE_WORKBENCH_TASK_QUEUE"
+	RollbackWorkbenchTaskQueue           = "ROLLBACK_WORKBENCH_TASK_QUEUE"
+	ResetWorkbenchTaskQueue              = "RESET_WORKBENCH_TASK_QUEUE"
+	DiagnoseWorkbenchTaskQueue           = "DIAGNOSE_WORKBENCH_TASK_QUEUE"
+	ExecuteNotebookTaskQueue             = "EXECUTE_NOTEBOOK_TASK_QUEUE"
 )
 
 func CreateWorkbench(ctx workflow.Context, option *googleapi.Option) (*googleapi.Status, error) {
@@ -43,7 +55,23 @@ func CreateWorkbench(ctx workflow.Context, option *googleapi.Option) (*googleapi
 
 	if exist {
 		logger.Info("Workbench instance already exists")
+		// 既存のインスタンスが停止している場合に起動するように変更したので、変更前に開始したワークフローは従来通り URL の取得だけを行う
+		if v := workflow.GetVersion(ctx, "ensure-running", workflow.DefaultVersion, 1); v == 1 {
+			if err := ensureRunning(ctx, option); err != nil {
+				return nil, err
+			}
+		}
 	} else {
+		// 環境を指定しない場合は従来通りアクティビティを追加しないので、既存のワークフローの履歴と互換性がある
+		if option.Environment != "" {
+			logger.Info("Resolving environment into image of Workbench instance", "Environment", option.Environment)
+			var resolved googleapi.Option
+			if err := workflow.ExecuteActivity(ctx, wa.ResolveEnvironment, option).Get(ctx, &resolved); err != nil {
+				return nil, fmt.Errorf("failed to resolve environment of Workbench instance: %w", err)
+			}
+			option = &resolved
+		}
+
 		logger.Info("Creating new Workbench instance")
 		var opName string
 		if err := workflow.ExecuteActivity(ctx, wa.Create, option).Get(ctx, &opName); err != nil {
@@ -62,10 +90,107 @@ func CreateWorkbench(ctx workflow.Context, option *googleapi.Option) (*googleapi
 		return nil, fmt.Errorf("failed to watch operation to create Workbench instance: %w", err)
 	}
 
+	// ブートストラップを指定しない場合は従来通りアクティビティを追加しないので、既存のワークフローの履歴と互換性がある
+	if option.Bootstrap != nil {
+		bootstrapCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+			StartToCloseTimeout: 1 * time.Minute,
+			// アクティビティを 10 秒間隔で 180 回の合計 30 分間リトライする
+			// パッケージのインストールやリポジトリのクローンに時間がかかることを考慮して長めに設定
+			RetryPolicy: &temporal.RetryPolicy{
+				InitialInterval:        10 * time.Second,
+				MaximumInterval:        10 * time.Second,
+				MaximumAttempts:        180,
+				NonRetryableErrorTypes: []string{activity.ErrBootstrapFailed},
+			},
+		})
+		logger.Info("Waiting for bootstrap of Workbench instance completed")
+		if err := workflow.ExecuteActivity(bootstrapCtx, wa.BootstrapCompleted, option).Get(ctx, nil); err != nil {
+			return nil, fmt.Errorf("failed to bootstrap Workbench instance: %w", err)
+		}
+	}
+
 	logger.Info("Workbench instance created successfully!")
 	return &status, nil
 }
 
+// ensureRunning starts the existing instance if it is stopped, after waiting for the instance in transition to settle.
+func ensureRunning(ctx workflow.Context, option *googleapi.Option) error {
+	logger := defaultGoogleAPIWorkflowLogger(ctx, option)
+
+	status, err := waitForSteadyState(ctx, option)
+	if err != nil {
+		return err
+	}
+	if !status.Status.IsStopped() {
+		return nil
+	}
+
+	logger.Info("Starting stopped Workbench instance")
+	return startInstance(ctx, option)
+}
+
+// waitForSteadyState waits for the instance in transition, e.g. stopping or upgrading, to be either running or stopped.
+func waitForSteadyState(ctx workflow.Context, option *googleapi.Option) (*googleapi.Status, error) {
+	var wa *activity.WorkbenchActivity
+
+	logger := defaultGoogleAPIWorkflowLogger(ctx, option)
+
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 1 * time.Minute,
+		// アクティビティを 10 秒間隔で 90 回の合計 15 分間リトライする
+		// 停止中やアップグレード中のインスタンスの状態が落ち着くのを待つため、起動を待つ時よりも長めに設定
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:        10 * time.Second,
+			MaximumInterval:        10 * time.Second,
+			MaximumAttempts:        90,
+			NonRetryableErrorTypes: []string{activity.ErrUnexpectedState},
+		},
+	})
+
+	logger.Info("Waiting for Workbench instance to be in steady state")
+	var status googleapi.Status
+	if err := workflow.ExecuteActivity(ctx, wa.GetSteadyState, option).Get(ctx, &status); err != nil {
+		return nil, fmt.Errorf("failed to wait for Workbench instance to be in steady state: %w", err)
+	}
+	return &status, nil
+}
+
+// startInstance starts the instance and waits for the operation to be done, with the activity options of ctx.
+func startInstance(ctx workflow.Context, option *googleapi.Option) error {
+	var wa *activity.WorkbenchActivity
+
+	logger := defaultGoogleAPIWorkflowLogger(ctx, option)
+
+	var opName string
+	if err := workflow.ExecuteActivity(ctx, wa.Start, option).Get(ctx, &opName); err != nil {
+		return fmt.Errorf("failed to start Workbench instance: %w", err)
+	}
+
+	logger.Info("Waiting for Workbench instance started")
+	if err := workflow.ExecuteActivity(ctx, wa.OperationCompleted, opName).Get(ctx, nil); err != nil {
+		return fmt.Errorf("failed to watch operation to start Workbench instance: %w", err)
+	}
+	return nil
+}
+
+// stopInstance stops the instance and waits for the operation to be done, with the activity options of ctx.
+func stopInstance(ctx workflow.Context, option *googleapi.Option) error {
+	var wa *activity.WorkbenchActivity
+
+	logger := defaultGoogleAPIWorkflowLogger(ctx, option)
+
+	var opName string
+	if err := workflow.ExecuteActivity(ctx, wa.Stop, option).Get(ctx, &opName); err != nil {
+		return fmt.Errorf("failed to stop Workbench instance: %w", err)
+	}
+
+	logger.Info("Waiting for Workbench instance stopped")
+	if err := workflow.ExecuteActivity(ctx, wa.OperationCompleted, opName).Get(ctx, nil); err != nil {
+		return fmt.Errorf("failed to watch operation to stop Workbench instance: %w", err)
+	}
+	return nil
+}
+
 func DeleteWorkbench(ctx workflow.Context, option *googleapi.Option) error {
 	var wa *activity.WorkbenchActivity
 
@@ -87,11 +212,14 @@ func DeleteWorkbench(ctx workflow.Context, option *googleapi.Option) error {
 	logger.Info("Checking for the existence of Workbench instance")
 	var exist bool
 	if err := workflow.ExecuteActivity(ctx, wa.Exist, option).Get(ctx, &exist); err != nil {
-		logger.Info("Workbench instance already not exists")
-		return nil
+		return fmt.Errorf("failed to check for the existence of Workbench instance: %w", err)
 	}
 	if !exist {
 		logger.Info("Workbench instance already deleted")
+		// 変更前に開始したワークフローは存在しない場合も削除を呼び出していたので、履歴との互換性のために従来通り続行する
+		if v := workflow.GetVersion(ctx, "skip-delete-missing", workflow.DefaultVersion, 1); v == 1 {
+			return nil
+		}
 	}
 
 	logger.Info("Deleting Workbench instance")
@@ -134,14 +262,8 @@ func StartWorkbench(ctx workflow.Context, option *googleapi.Option) (*googleapi.
 	}
 
 	logger.Info("Starting Workbench instance")
-	var opName string
-	if err := workflow.ExecuteActivity(ctx, wa.Start, option).Get(ctx, &opName); err != nil {
-		return nil, fmt.Errorf("failed to start Workbench instance: %w", err)
-	}
-
-	logger.Info("Waiting for Workbench instance started")
-	if err := workflow.ExecuteActivity(ctx, wa.OperationCompleted, opName).Get(ctx, nil); err != nil {
-		return nil, fmt.Errorf("failed to watch operation to start Workbench instance: %w", err)
+	if err := startInstance(ctx, option); err != nil {
+		return nil, err
 	}
 
 	logger.Info("Getting URL for accessing to Workbench")
@@ -179,16 +301,723 @@ func StopWorkbench(ctx workflow.Context, option *googleapi.Option) error {
 	}
 
 	logger.Info("Stopping Workbench instance")
+	if err := stopInstance(ctx, option); err != nil {
+		return err
+	}
+
+	logger.Info("Workbench instance stopped successfully!")
+	return nil
+}
+
+func SetWorkbenchLabels(ctx workflow.Context, option *googleapi.Option) (*googleapi.Status, error) {
+	var wa *activity.WorkbenchActivity
+
+	logger := defaultGoogleAPIWorkflowLogger(ctx, option)
+
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		// アクティビティの実行時間のタイムアウト値
+		StartToCloseTimeout: 1 * time.Minute,
+		// アクティビティを 5 秒間隔で 36 回の合計 3 分間リトライする
+		// ラベルの更新はインスタンスの再起動を伴わないので、停止を待つ時と同じリトライ戦略を設定
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:        5 * time.Second,
+			MaximumInterval:        5 * time.Second,
+			MaximumAttempts:        36,
+			NonRetryableErrorTypes: []string{activity.ErrLongRunningOperationFailed},
+		},
+	})
+
+	logger.Info("Checking for the existence of Workbench instance")
+	var exist bool
+	if err := workflow.ExecuteActivity(ctx, wa.Exist, option).Get(ctx, &exist); err != nil {
+		return nil, fmt.Errorf("failed to check for the existence of Workbench instance: %w", err)
+	}
+	if !exist {
+		return nil, temporal.NewNonRetryableApplicationError("workbench instance not found", activity.ErrNotFound, nil)
+	}
+
+	logger.Info("Setting labels of Workbench instance", "Labels", option.Labels)
 	var opName string
-	if err := workflow.ExecuteActivity(ctx, wa.Stop, option).Get(ctx, &opName); err != nil {
-		return fmt.Errorf("failed to stop Workbench instance: %w", err)
+	if err := workflow.ExecuteActivity(ctx, wa.SetLabels, option).Get(ctx, &opName); err != nil {
+		return nil, fmt.Errorf("failed to set labels of Workbench instance: %w", err)
 	}
 
-	logger.Info("Waiting for Workbench instance stopped")
+	logger.Info("Waiting for labels of Workbench instance updated")
 	if err := workflow.ExecuteActivity(ctx, wa.OperationCompleted, opName).Get(ctx, nil); err != nil {
-		return fmt.Errorf("failed to watch operation to stop Workbench instance: %w", err)
+		return nil, fmt.Errorf("failed to watch operation to set labels of Workbench instance: %w", err)
 	}
 
-	logger.Info("Workbench instance stopped successfully!")
+	var status googleapi.Status
+	if err := workflow.ExecuteActivity(ctx, wa.Describe, option).Get(ctx, &status); err != nil {
+		return nil, fmt.Errorf("failed to describe Workbench instance: %w", err)
+	}
+
+	logger.Info("Labels of Workbench instance updated successfully!")
+	return &status, nil
+}
+
+func UpdateWorkbenchIdleShutdown(ctx workflow.Context, option *googleapi.Option) (*googleapi.Status, error) {
+	var wa *activity.WorkbenchActivity
+
+	logger := defaultGoogleAPIWorkflowLogger(ctx, option)
+
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		// アクティビティの実行時間のタイムアウト値
+		StartToCloseTimeout: 1 * time.Minute,
+		// アクティビティを 5 秒間隔で 36 回の合計 3 分間リトライする
+		// メタデータの更新はインスタンスの再起動を伴わないので、ラベルの更新と同じリトライ戦略を設定
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:        5 * time.Second,
+			MaximumInterval:        5 * time.Second,
+			MaximumAttempts:        36,
+			NonRetryableErrorTypes: []string{activity.ErrLongRunningOperationFailed},
+		},
+	})
+
+	logger.Info("Checking for the existence of Workbench instance")
+	var exist bool
+	if err := workflow.ExecuteActivity(ctx, wa.Exist, option).Get(ctx, &exist); err != nil {
+		return nil, fmt.Errorf("failed to check for the existence of Workbench instance: %w", err)
+	}
+	if !exist {
+		return nil, temporal.NewNonRetryableApplicationError("workbench instance not found", activity.ErrNotFound, nil)
+	}
+
+	logger.Info("Setting idle timeout of Workbench instance", "IdleTimeout", option.IdleTimeout)
+	if err := workflow.ExecuteActivity(ctx, wa.SetIdleTimeout, option).Get(ctx, nil); err != nil {
+		return nil, fmt.Errorf("failed to set idle timeout of Workbench instance: %w", err)
+	}
+
+	logger.Info("Waiting for idle timeout of Workbench instance applied")
+	var status googleapi.Status
+	if err := workflow.ExecuteActivity(ctx, wa.IdleTimeoutApplied, option).Get(ctx, &status); err != nil {
+		return nil, fmt.Errorf("failed to watch idle timeout of Workbench instance applied: %w", err)
+	}
+
+	logger.Info("Idle timeout of Workbench instance updated successfully!")
+	return &status, nil
+}
+
+// ResizeWorkbench changes the machine type of the instance, which requires the instance to be stopped.
+// The running instance is stopped and started again with the new machine type. If the new machine type is rejected,
+// e.g. by invalid machine type or lack of quota, the machine type is rolled back so that the instance remains usable.
+func ResizeWorkbench(ctx workflow.Context, option *googleapi.Option) (*googleapi.Status, error) {
+	var wa *activity.WorkbenchActivity
+
+	logger := defaultGoogleAPIWorkflowLogger(ctx, option)
+
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		// アクティビティの実行時間のタイムアウト値
+		StartToCloseTimeout: 1 * time.Minute,
+		// アクティビティを 5 秒間隔で 72 回の合計 6 分間リトライする
+		// インスタンスの停止と起動を伴うので、起動を待つ時と同じリトライ戦略を設定
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:        5 * time.Second,
+			MaximumInterval:        5 * time.Second,
+			MaximumAttempts:        72,
+			NonRetryableErrorTypes: []string{activity.ErrLongRunningOperationFailed},
+		},
+	})
+
+	logger.Info("Checking for the existence of Workbench instance")
+	var exist bool
+	if err := workflow.ExecuteActivity(ctx, wa.Exist, option).Get(ctx, &exist); err != nil {
+		return nil, fmt.Errorf("failed to check for the existence of Workbench instance: %w", err)
+	}
+	if !exist {
+		return nil, temporal.NewNonRetryableApplicationError("workbench instance not found", activity.ErrNotFound, nil)
+	}
+
+	current, err := waitForSteadyState(ctx, option)
+	if err != nil {
+		return nil, err
+	}
+	if current.MachineType == option.MachineType {
+		logger.Info("Workbench instance already has the machine type", "MachineType", option.MachineType)
+		return current, nil
+	}
+	running := current.Status.IsRunning()
+
+	if running {
+		logger.Info("Stopping Workbench instance to change machine type")
+		if err := stopInstance(ctx, option); err != nil {
+			return nil, err
+		}
+	}
+
+	// 新しいマシンタイプが拒否された場合はリトライしても成功しないので、すぐに元のマシンタイプに戻す
+	resizeCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 1 * time.Minute,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:        5 * time.Second,
+			MaximumInterval:        5 * time.Second,
+			MaximumAttempts:        72,
+			NonRetryableErrorTypes: []string{activity.ErrLongRunningOperationFailed, activity.ErrResourceExhausted},
+		},
+	})
+
+	// クォータ不足などで拒否された変更要求をリトライし続けるとロールバックが遅れるので、変更要求だけは数回で諦める
+	requestCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 1 * time.Minute,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:        5 * time.Second,
+			MaximumInterval:        5 * time.Second,
+			MaximumAttempts:        3,
+			NonRetryableErrorTypes: []string{activity.ErrLongRunningOperationFailed, activity.ErrResourceExhausted},
+		},
+	})
+
+	logger.Info("Changing machine type of Workbench instance", "From", current.MachineType, "To", option.MachineType)
+	err = setMachineType(requestCtx, resizeCtx, option)
+	if err == nil && running {
+		logger.Info("Starting Workbench instance with new machine type")
+		err = startInstance(resizeCtx, option)
+	}
+	if err != nil {
+		if isResizeRejected(err) {
+			logger.Error("Machine type of Workbench instance rejected, rolling back", "MachineType", current.MachineType, "Error", err)
+			return nil, rollbackMachineType(ctx, option, current.MachineType, running, err)
+		}
+		// 変更前に開始したワークフローは失敗したら停止したまま終了していたので、新しく開始したワークフローのみ起動し直す
+		if v := workflow.GetVersion(ctx, "restart-failed-resize", workflow.DefaultVersion, 1); v == 1 && running {
+			logger.Error("Resize of Workbench instance failed, starting instance again", "Error", err)
+			if restartErr := ensureRunning(ctx, option); restartErr != nil {
+				return nil, errors.Join(err, restartErr)
+			}
+		}
+		return nil, err
+	}
+
+	var status googleapi.Status
+	if running {
+		logger.Info("Getting URL for accessing to Workbench")
+		if err := workflow.ExecuteActivity(ctx, wa.GetWorkspaceURL, option).Get(ctx, &status); err != nil {
+			return nil, fmt.Errorf("failed to watch operation to start Workbench instance: %w", err)
+		}
+	} else {
+		if err := workflow.ExecuteActivity(ctx, wa.Describe, option).Get(ctx, &status); err != nil {
+			return nil, fmt.Errorf("failed to describe Workbench instance: %w", err)
+		}
+	}
+
+	logger.Info("Workbench instance resized successfully!")
+	return &status, nil
+}
+
+// setMachineType changes the machine type of the stopped instance and waits for the operation to be done.
+// The change is requested with the activity options of requestCtx, and the operation is waited with ctx.
+func setMachineType(requestCtx, ctx workflow.Context, option *googleapi.Option) error {
+	var wa *activity.WorkbenchActivity
+
+	logger := defaultGoogleAPIWorkflowLogger(ctx, option)
+
+	var opName string
+	if err := workflow.ExecuteActivity(requestCtx, wa.SetMachineType, option).Get(ctx, &opName); err != nil {
+		return fmt.Errorf("failed to change machine type of Workbench instance: %w", err)
+	}
+
+	logger.Info("Waiting for machine type of Workbench instance changed")
+	if err := workflow.ExecuteActivity(ctx, wa.OperationCompleted, opName).Get(ctx, nil); err != nil {
+		return fmt.Errorf("failed to watch operation to change machine type of Workbench instance: %w", err)
+	}
 	return nil
 }
+
+// rollbackMachineType restores the original machine type, and starts the instance again if it was running before resize.
+// The cause of the rollback is returned as non-retryable error so that the workflow does not try the rejected machine type again.
+func rollbackMachineType(ctx workflow.Context, option *googleapi.Option, machineType string, running bool, cause error) error {
+	logger := defaultGoogleAPIWorkflowLogger(ctx, option)
+
+	original := *option
+	original.MachineType = machineType
+	// 起動に失敗した場合も、インスタンスの状態が落ち着いてから元に戻す
+	if _, err := waitForSteadyState(ctx, &original); err != nil {
+		return fmt.Errorf("failed to roll back machine type of Workbench instance: %w", errors.Join(err, cause))
+	}
+	if err := setMachineType(ctx, ctx, &original); err != nil {
+		return fmt.Errorf("failed to roll back machine type of Workbench instance: %w", errors.Join(err, cause))
+	}
+	if running {
+		logger.Info("Starting Workbench instance with original machine type")
+		if err := startInstance(ctx, &original); err != nil {
+			return fmt.Errorf("failed to start Workbench instance after rollback: %w", errors.Join(err, cause))
+		}
+	}
+
+	var appErr *temporal.ApplicationError
+	var errType string
+	if errors.As(cause, &appErr) {
+		errType = appErr.Type()
+	}
+	return temporal.NewNonRetryableApplicationError(
+		fmt.Sprintf("machine type %s rejected and rolled back to %s", option.MachineType, machineType), errType, cause)
+}
+
+// isResizeRejected reports whether the error is caused by the new machine type itself, which requires the rollback.
+func isResizeRejected(err error) bool {
+	var appErr *temporal.ApplicationError
+	if !errors.As(err, &appErr) {
+		return false
+	}
+	switch appErr.Type() {
+	case activity.ErrInvalidArgument, activity.ErrResourceExhausted, activity.ErrFailedPrecondition, activity.ErrLongRunningOperationFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+// SetWorkbenchAccelerator changes the accelerator attached to the instance, which requires the instance to be stopped.
+// The running instance is stopped and started again with the new accelerator, after checking that the zone offers it.
+func SetWorkbenchAccelerator(ctx workflow.Context, option *googleapi.Option) (*googleapi.Status, error) {
+	var wa *activity.WorkbenchActivity
+
+	logger := defaultGoogleAPIWorkflowLogger(ctx, option)
+
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		// アクティビティの実行時間のタイムアウト値
+		StartToCloseTimeout: 1 * time.Minute,
+		// アクティビティを 5 秒間隔で 72 回の合計 6 分間リトライする
+		// インスタンスの停止と起動を伴うので、起動を待つ時と同じリトライ戦略を設定
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:        5 * time.Second,
+			MaximumInterval:        5 * time.Second,
+			MaximumAttempts:        72,
+			NonRetryableErrorTypes: []string{activity.ErrLongRunningOperationFailed},
+		},
+	})
+
+	logger.Info("Checking for the existence of Workbench instance")
+	var exist bool
+	if err := workflow.ExecuteActivity(ctx, wa.Exist, option).Get(ctx, &exist); err != nil {
+		return nil, fmt.Errorf("failed to check for the existence of Workbench instance: %w", err)
+	}
+	if !exist {
+		return nil, temporal.NewNonRetryableApplicationError("workbench instance not found", activity.ErrNotFound, nil)
+	}
+
+	current, err := waitForSteadyState(ctx, option)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(current.AcceleratorType, option.AcceleratorType) && current.AcceleratorCount == option.AcceleratorCount {
+		logger.Info("Workbench instance already has the accelerator", "AcceleratorType", current.AcceleratorType, "AcceleratorCount", current.AcceleratorCount)
+		return current, nil
+	}
+
+	// アクセラレータをアタッチできるかはマシンタイプによって決まるので、現在のマシンタイプで確認する
+	target := *option
+	target.MachineType = current.MachineType
+	logger.Info("Checking accelerator is available for Workbench instance", "AcceleratorType", target.AcceleratorType, "AcceleratorCount", target.AcceleratorCount)
+	if err := workflow.ExecuteActivity(ctx, wa.CheckAccelerator, &target).Get(ctx, nil); err != nil {
+		return nil, fmt.Errorf("accelerator cannot be attached to Workbench instance: %w", err)
+	}
+
+	running := current.Status.IsRunning()
+	if running {
+		logger.Info("Stopping Workbench instance to change accelerator")
+		if err := stopInstance(ctx, option); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Info("Changing accelerator of Workbench instance",
+		"From", fmt.Sprintf("%s x %d", current.AcceleratorType, current.AcceleratorCount),
+		"To", fmt.Sprintf("%s x %d", target.AcceleratorType, target.AcceleratorCount))
+	var opName string
+	if err := workflow.ExecuteActivity(ctx, wa.SetAccelerator, &target).Get(ctx, &opName); err != nil {
+		return nil, fmt.Errorf("failed to change accelerator of Workbench instance: %w", err)
+	}
+
+	logger.Info("Waiting for accelerator of Workbench instance changed")
+	if err := workflow.ExecuteActivity(ctx, wa.OperationCompleted, opName).Get(ctx, nil); err != nil {
+		return nil, fmt.Errorf("failed to watch operation to change accelerator of Workbench instance: %w", err)
+	}
+
+	var status googleapi.Status
+	if running {
+		logger.Info("Starting Workbench instance with new accelerator")
+		if err := startInstance(ctx, option); err != nil {
+			return nil, err
+		}
+		logger.Info("Getting URL for accessing to Workbench")
+		if err := workflow.ExecuteActivity(ctx, wa.GetWorkspaceURL, option).Get(ctx, &status); err != nil {
+			return nil, fmt.Errorf("failed to watch operation to start Workbench instance: %w", err)
+		}
+	} else {
+		if err := workflow.ExecuteActivity(ctx, wa.Describe, option).Get(ctx, &status); err != nil {
+			return nil, fmt.Errorf("failed to describe Workbench instance: %w", err)
+		}
+	}
+
+	logger.Info("Accelerator of Workbench instance changed successfully!")
+	return &status, nil
+}
+
+// UpgradeWorkbench upgrades the image of the instance if newer one is available.
+// Notebooks API takes the snapshot of the boot disk before the upgrade, so the instance is rolled back to the snapshot
+// if the upgrade fails or the instance doesn't become active with the new image.
+func UpgradeWorkbench(ctx workflow.Context, option *googleapi.Option) (*googleapi.Status, error) {
+	var wa *activity.WorkbenchActivity
+
+	logger := defaultGoogleAPIWorkflowLogger(ctx, option)
+
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		// アクティビティの実行時間のタイムアウト値
+		StartToCloseTimeout: 1 * time.Minute,
+		// アクティビティを 5 秒間隔で 72 回の合計 6 分間リトライする
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:        5 * time.Second,
+			MaximumInterval:        5 * time.Second,
+			MaximumAttempts:        72,
+			NonRetryableErrorTypes: []string{activity.ErrLongRunningOperationFailed},
+		},
+	})
+
+	logger.Info("Checking for the existence of Workbench instance")
+	var exist bool
+	if err := workflow.ExecuteActivity(ctx, wa.Exist, option).Get(ctx, &exist); err != nil {
+		return nil, fmt.Errorf("failed to check for the existence of Workbench instance: %w", err)
+	}
+	if !exist {
+		return nil, temporal.NewNonRetryableApplicationError("workbench instance not found", activity.ErrNotFound, nil)
+	}
+
+	logger.Info("Checking whether Workbench instance is upgradeable")
+	var upgradeability googleapi.Upgradeability
+	if err := workflow.ExecuteActivity(ctx, wa.IsUpgradeable, option).Get(ctx, &upgradeability); err != nil {
+		return nil, fmt.Errorf("failed to check whether Workbench instance is upgradeable: %w", err)
+	}
+
+	var current googleapi.Status
+	if err := workflow.ExecuteActivity(ctx, wa.Describe, option).Get(ctx, &current); err != nil {
+		return nil, fmt.Errorf("failed to describe Workbench instance: %w", err)
+	}
+	if !upgradeability.Upgradeable {
+		logger.Info("Workbench instance is already up to date", "Info", upgradeability.Info)
+		return &current, nil
+	}
+
+	logger.Info("Upgrading Workbench instance", "Version", upgradeability.Version, "Image", upgradeability.Image)
+	var opName string
+	if err := workflow.ExecuteActivity(ctx, wa.Upgrade, option).Get(ctx, &opName); err != nil {
+		return nil, fmt.Errorf("failed to upgrade Workbench instance: %w", err)
+	}
+
+	upgradeCtx := withLongOperationActivityOptions(ctx)
+	var status googleapi.Status
+	logger.Info("Waiting for Workbench instance upgraded")
+	err := workflow.ExecuteActivity(upgradeCtx, wa.OperationCompleted, opName).Get(ctx, nil)
+	if err != nil {
+		err = fmt.Errorf("failed to watch operation to upgrade Workbench instance: %w", err)
+	} else {
+		logger.Info("Waiting for upgraded Workbench instance to be active")
+		if err = workflow.ExecuteActivity(upgradeCtx, wa.GetWorkspaceURL, option).Get(ctx, &status); err != nil {
+			err = fmt.Errorf("failed to get URL of upgraded Workbench instance: %w", err)
+		}
+	}
+	if err != nil {
+		logger.Error("Upgrade of Workbench instance failed, rolling back", "Error", err)
+		// アップグレードで新たに取得されたスナップショットにだけ戻し、以前のアップグレードより前の状態には戻さない
+		return nil, rollbackUpgrade(upgradeCtx, option, len(current.UpgradeHistory), err)
+	}
+
+	logger.Info("Workbench instance upgraded successfully!")
+	return &status, nil
+}
+
+// RollbackWorkbench restores the instance from option.Snapshot, or the snapshot taken before the latest upgrade if omitted.
+func RollbackWorkbench(ctx workflow.Context, option *googleapi.Option) (*googleapi.Status, error) {
+	var wa *activity.WorkbenchActivity
+
+	logger := defaultGoogleAPIWorkflowLogger(ctx, option)
+
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		// アクティビティの実行時間のタイムアウト値
+		StartToCloseTimeout: 1 * time.Minute,
+		// アクティビティを 5 秒間隔で 72 回の合計 6 分間リトライする
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:        5 * time.Second,
+			MaximumInterval:        5 * time.Second,
+			MaximumAttempts:        72,
+			NonRetryableErrorTypes: []string{activity.ErrLongRunningOperationFailed},
+		},
+	})
+
+	logger.Info("Checking for the existence of Workbench instance")
+	var exist bool
+	if err := workflow.ExecuteActivity(ctx, wa.Exist, option).Get(ctx, &exist); err != nil {
+		return nil, fmt.Errorf("failed to check for the existence of Workbench instance: %w", err)
+	}
+	if !exist {
+		return nil, temporal.NewNonRetryableApplicationError("workbench instance not found", activity.ErrNotFound, nil)
+	}
+
+	target := *option
+	if target.Snapshot == "" {
+		var current googleapi.Status
+		if err := workflow.ExecuteActivity(ctx, wa.Describe, option).Get(ctx, &current); err != nil {
+			return nil, fmt.Errorf("failed to describe Workbench instance: %w", err)
+		}
+		target.Snapshot = googleapi.LatestUpgradeSnapshot(current.UpgradeHistory)
+		if target.Snapshot == "" {
+			return nil, temporal.NewNonRetryableApplicationError("workbench instance has never been upgraded, no snapshot to roll back to", activity.ErrFailedPrecondition, nil)
+		}
+	}
+
+	upgradeCtx := withLongOperationActivityOptions(ctx)
+	logger.Info("Rolling back Workbench instance", "Snapshot", target.Snapshot)
+	if err := rollbackInstance(upgradeCtx, &target); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Waiting for rolled back Workbench instance to be active")
+	var status googleapi.Status
+	if err := workflow.ExecuteActivity(upgradeCtx, wa.GetWorkspaceURL, option).Get(ctx, &status); err != nil {
+		return nil, fmt.Errorf("failed to get URL of rolled back Workbench instance: %w", err)
+	}
+
+	logger.Info("Workbench instance rolled back successfully!")
+	return &status, nil
+}
+
+// withLongOperationActivityOptions returns the context to wait for the operations taking longer than starting the instance,
+// e.g. upgrade, rollback or diagnosis.
+func withLongOperationActivityOptions(ctx workflow.Context) workflow.Context {
+	return workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 1 * time.Minute,
+		// アクティビティを 10 秒間隔で 180 回の合計 30 分間リトライする
+		// イメージの入れ替えやスナップショットの取得、診断ログの収集を待つため、起動を待つ時よりも長めに設定
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:        10 * time.Second,
+			MaximumInterval:        10 * time.Second,
+			MaximumAttempts:        180,
+			NonRetryableErrorTypes: []string{activity.ErrLongRunningOperationFailed, activity.ErrUnexpectedState},
+		},
+	})
+}
+
+// rollbackUpgrade rolls back the failed upgrade to the snapshot taken by the upgrade, which is recorded after
+// the first `since` entries of the upgrade history. The cause of the rollback is returned as non-retryable error.
+func rollbackUpgrade(ctx workflow.Context, option *googleapi.Option, since int, cause error) error {
+	var wa *activity.WorkbenchActivity
+
+	logger := defaultGoogleAPIWorkflowLogger(ctx, option)
+
+	var status googleapi.Status
+	if err := workflow.ExecuteActivity(ctx, wa.Describe, option).Get(ctx, &status); err != nil {
+		return fmt.Errorf("failed to describe Workbench instance to roll back: %w", errors.Join(err, cause))
+	}
+	target := *option
+	if since <= len(status.UpgradeHistory) {
+		target.Snapshot = googleapi.LatestUpgradeSnapshot(status.UpgradeHistory[since:])
+	}
+	if target.Snapshot == "" {
+		return fmt.Errorf("no snapshot taken by the upgrade to roll back Workbench instance to: %w", cause)
+	}
+
+	logger.Info("Rolling back Workbench instance", "Snapshot", target.Snapshot)
+	if err := rollbackInstance(ctx, &target); err != nil {
+		return errors.Join(err, cause)
+	}
+	if err := workflow.ExecuteActivity(ctx, wa.GetWorkspaceURL, option).Get(ctx, nil); err != nil {
+		return fmt.Errorf("failed to get URL of rolled back Workbench instance: %w", errors.Join(err, cause))
+	}
+
+	var appErr *temporal.ApplicationError
+	var errType string
+	if errors.As(cause, &appErr) {
+		errType = appErr.Type()
+	}
+	return temporal.NewNonRetryableApplicationError(
+		fmt.Sprintf("upgrade failed and rolled back to snapshot %s", target.Snapshot), errType, cause)
+}
+
+// rollbackInstance restores the instance from option.Snapshot and waits for the operation to be done.
+func rollbackInstance(ctx workflow.Context, option *googleapi.Option) error {
+	var wa *activity.WorkbenchActivity
+
+	logger := defaultGoogleAPIWorkflowLogger(ctx, option)
+
+	var opName string
+	if err := workflow.ExecuteActivity(ctx, wa.Rollback, option).Get(ctx, &opName); err != nil {
+		return fmt.Errorf("failed to roll back Workbench instance: %w", err)
+	}
+
+	logger.Info("Waiting for Workbench instance rolled back")
+	if err := workflow.ExecuteActivity(ctx, wa.OperationCompleted, opName).Get(ctx, nil); err != nil {
+		return fmt.Errorf("failed to watch operation to roll back Workbench instance: %w", err)
+	}
+	return nil
+}
+
+// ResetWorkbench resets the running instance, e.g. when the notebook hangs and the instance cannot be stopped normally.
+func ResetWorkbench(ctx workflow.Context, option *googleapi.Option) (*googleapi.Status, error) {
+	var wa *activity.WorkbenchActivity
+
+	logger := defaultGoogleAPIWorkflowLogger(ctx, option)
+
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		// アクティビティの実行時間のタイムアウト値
+		StartToCloseTimeout: 1 * time.Minute,
+		// アクティビティを 5 秒間隔で 72 回の合計 6 分間リトライする
+		// リセット後の OS の起動を待つので、起動を待つ時と同じリトライ戦略を設定
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:        5 * time.Second,
+			MaximumInterval:        5 * time.Second,
+			MaximumAttempts:        72,
+			NonRetryableErrorTypes: []string{activity.ErrLongRunningOperationFailed},
+		},
+	})
+
+	logger.Info("Checking for the existence of Workbench instance")
+	var exist bool
+	if err := workflow.ExecuteActivity(ctx, wa.Exist, option).Get(ctx, &exist); err != nil {
+		return nil, fmt.Errorf("failed to check for the existence of Workbench instance: %w", err)
+	}
+	if !exist {
+		return nil, temporal.NewNonRetryableApplicationError("workbench instance not found", activity.ErrNotFound, nil)
+	}
+
+	current, err := waitForSteadyState(ctx, option)
+	if err != nil {
+		return nil, err
+	}
+	if !current.Status.IsRunning() {
+		return nil, temporal.NewNonRetryableApplicationError(
+			fmt.Sprintf("workbench instance cannot be reset in %s state, start it instead", current.Status), activity.ErrFailedPrecondition, nil)
+	}
+
+	logger.Info("Resetting Workbench instance")
+	var opName string
+	if err := workflow.ExecuteActivity(ctx, wa.Reset, option).Get(ctx, &opName); err != nil {
+		return nil, fmt.Errorf("failed to reset Workbench instance: %w", err)
+	}
+
+	logger.Info("Waiting for Workbench instance reset")
+	if err := workflow.ExecuteActivity(ctx, wa.OperationCompleted, opName).Get(ctx, nil); err != nil {
+		return nil, fmt.Errorf("failed to watch operation to reset Workbench instance: %w", err)
+	}
+
+	logger.Info("Getting URL for accessing to Workbench")
+	var status googleapi.Status
+	if err := workflow.ExecuteActivity(ctx, wa.GetWorkspaceURL, option).Get(ctx, &status); err != nil {
+		return nil, fmt.Errorf("failed to get URL of reset Workbench instance: %w", err)
+	}
+
+	logger.Info("Workbench instance reset successfully!")
+	return &status, nil
+}
+
+// DiagnoseWorkbench collects the diagnostic logs of the instance as configured in option.Diagnostic,
+// and returns the Cloud Storage location the logs are uploaded to.
+func DiagnoseWorkbench(ctx workflow.Context, option *googleapi.Option) (string, error) {
+	var wa *activity.WorkbenchActivity
+
+	logger := defaultGoogleAPIWorkflowLogger(ctx, option)
+
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		// アクティビティの実行時間のタイムアウト値
+		StartToCloseTimeout: 1 * time.Minute,
+		// アクティビティを 5 秒間隔で 36 回の合計 3 分間リトライする
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:        5 * time.Second,
+			MaximumInterval:        5 * time.Second,
+			MaximumAttempts:        36,
+			NonRetryableErrorTypes: []string{activity.ErrLongRunningOperationFailed},
+		},
+	})
+
+	if option.Diagnostic == nil {
+		return "", temporal.NewNonRetryableApplicationError("diagnostic configuration is required to diagnose Workbench instance", activity.ErrInvalidArgument, nil)
+	}
+
+	logger.Info("Checking for the existence of Workbench instance")
+	var exist bool
+	if err := workflow.ExecuteActivity(ctx, wa.Exist, option).Get(ctx, &exist); err != nil {
+		return "", fmt.Errorf("failed to check for the existence of Workbench instance: %w", err)
+	}
+	if !exist {
+		return "", temporal.NewNonRetryableApplicationError("workbench instance not found", activity.ErrNotFound, nil)
+	}
+
+	logger.Info("Diagnosing Workbench instance", "Location", option.Diagnostic.Location(),
+		"Repair", option.Diagnostic.Repair, "PacketCapture", option.Diagnostic.PacketCapture)
+	var opName string
+	if err := workflow.ExecuteActivity(ctx, wa.Diagnose, option).Get(ctx, &opName); err != nil {
+		return "", fmt.Errorf("failed to diagnose Workbench instance: %w", err)
+	}
+
+	logger.Info("Waiting for diagnostic logs of Workbench instance collected")
+	if err := workflow.ExecuteActivity(withLongOperationActivityOptions(ctx), wa.OperationCompleted, opName).Get(ctx, nil); err != nil {
+		return "", fmt.Errorf("failed to watch operation to diagnose Workbench instance: %w", err)
+	}
+
+	logger.Info("Workbench instance diagnosed successfully!")
+	return option.Diagnostic.Location(), nil
+}
+
+// ExecuteNotebook runs the notebook on Cloud Storage as a batch job without keeping Workbench instance up,
+// and returns the executed notebook and the state of the job. The failed execution is returned without error.
+func ExecuteNotebook(ctx workflow.Context, option *googleapi.ExecutionOption) (*googleapi.ExecutionStatus, error) {
+	var wa *activity.WorkbenchActivity
+
+	logger := defaultExecutionWorkflowLogger(ctx, option)
+
+	ctx = workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		// アクティビティの実行時間のタイムアウト値
+		StartToCloseTimeout: 1 * time.Minute,
+		// アクティビティを 5 秒間隔で 72 回の合計 6 分間リトライする
+		// 実行の作成を待つ時のリトライ戦略で、ジョブの完了は別のリトライ戦略で待つ
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:        5 * time.Second,
+			MaximumInterval:        5 * time.Second,
+			MaximumAttempts:        72,
+			NonRetryableErrorTypes: []string{activity.ErrLongRunningOperationFailed},
+		},
+	})
+
+	logger.Info("Checking for the existence of notebook execution")
+	var exist bool
+	if err := workflow.ExecuteActivity(ctx, wa.ExistExecution, option).Get(ctx, &exist); err != nil {
+		return nil, fmt.Errorf("failed to check for the existence of notebook execution: %w", err)
+	}
+
+	// ワークフローがリトライされた場合は作成済みの実行の完了を待つ
+	if exist {
+		logger.Info("Notebook execution already exists")
+	} else {
+		logger.Info("Creating notebook execution", "InputNotebookFile", option.InputNotebookFile)
+		var opName string
+		if err := workflow.ExecuteActivity(ctx, wa.CreateExecution, option).Get(ctx, &opName); err != nil {
+			return nil, fmt.Errorf("failed to create notebook execution: %w", err)
+		}
+
+		logger.Info("Waiting for notebook execution created")
+		if err := workflow.ExecuteActivity(ctx, wa.OperationCompleted, opName).Get(ctx, nil); err != nil {
+			return nil, fmt.Errorf("failed to watch operation to create notebook execution: %w", err)
+		}
+	}
+
+	executionCtx := workflow.WithActivityOptions(ctx, workflow.ActivityOptions{
+		StartToCloseTimeout: 1 * time.Minute,
+		// ノートブックの実行時間は内容次第なので回数は制限せず、1 分間隔で最大 24 時間リトライする
+		ScheduleToCloseTimeout: 24 * time.Hour,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval: 1 * time.Minute,
+			MaximumInterval: 1 * time.Minute,
+		},
+	})
+	logger.Info("Waiting for notebook execution completed")
+	var status googleapi.ExecutionStatus
+	if err := workflow.ExecuteActivity(executionCtx, wa.ExecutionCompleted, option).Get(ctx, &status); err != nil {
+		return nil, fmt.Errorf("failed to wait for notebook execution completed: %w", err)
+	}
+
+	if !status.State.IsSucceeded() {
+		logger.Warn("Notebook execution finished unsuccessfully", "State", status.State, "JobURI", status.JobURI)
+		return &status, nil
+	}
+	logger.Info("Notebook executed successfully!", "OutputNotebookFile", status.OutputNotebookFile)
+	return &status, nil
+}
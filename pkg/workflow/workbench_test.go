@@ -0,0 +1,631 @@
+package workflow_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	sdkactivity "go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/toVersus/wbtemporal/pkg/activity"
+	"github.com/toVersus/wbtemporal/pkg/executor/googleapi"
+	"github.com/toVersus/wbtemporal/pkg/workflow"
+)
+
+// activityRecorder records every attempt of the activities started in the test environment.
+type activityRecorder struct {
+	attempts []string
+}
+
+func recordActivities(env *testsuite.TestWorkflowEnvironment) *activityRecorder {
+	r := &activityRecorder{}
+	env.SetOnActivityStartedListener(func(info *sdkactivity.Info, _ context.Context, _ converter.EncodedValues) {
+		r.attempts = append(r.attempts, info.ActivityType.Name)
+	})
+	return r
+}
+
+// calls returns the activities in the order they were called, folding retries of the same activity into one call.
+func (r *activityRecorder) calls() []string {
+	var calls []string
+	for _, name := range r.attempts {
+		if len(calls) == 0 || calls[len(calls)-1] != name {
+			calls = append(calls, name)
+		}
+	}
+	return calls
+}
+
+// count returns the number of attempts of the activity.
+func (r *activityRecorder) count(name string) int {
+	n := 0
+	for _, attempt := range r.attempts {
+		if attempt == name {
+			n++
+		}
+	}
+	return n
+}
+
+func assertCalls(t *testing.T, r *activityRecorder, want ...string) {
+	t.Helper()
+	if got := r.calls(); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected activities %v, got %v", want, got)
+	}
+}
+
+// hasErrorType reports whether the error chain contains an application error of the type.
+func hasErrorType(err error, errType string) bool {
+	var appErr *temporal.ApplicationError
+	for errors.As(err, &appErr) {
+		if appErr.Type() == errType {
+			return true
+		}
+		err = appErr.Unwrap()
+	}
+	return false
+}
+
+var (
+	errNotDone          = errors.New("operation is not done yet")
+	errOperationAborted = temporal.NewNonRetryableApplicationError("non-retryable error found in watch operation", activity.ErrLongRunningOperationFailed, errors.New("operation aborted"))
+)
+
+func testWorkbenchOption() *googleapi.Option {
+	return &googleapi.Option{
+		Name:        "test-workbench",
+		Email:       "owner@example.com",
+		Zone:        "us-central1-a",
+		Location:    "us-central1",
+		ProjectId:   "test-project",
+		MachineType: "n1-standard-4",
+	}
+}
+
+func testWorkbenchStatus() *googleapi.Status {
+	return &googleapi.Status{Name: "test-workbench", URL: "https://example.notebooks.googleusercontent.com", Status: "ACTIVE"}
+}
+
+func TestCreateWorkbench(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestWorkflowEnvironment()
+	r := recordActivities(env)
+	var wa *activity.WorkbenchActivity
+	env.OnActivity(wa.Exist, mock.Anything, mock.Anything).Return(false, nil)
+	env.OnActivity(wa.Create, mock.Anything, mock.Anything).Return("create-op", nil)
+	// 作成の完了までリトライして待つことを確認する
+	env.OnActivity(wa.OperationCompleted, mock.Anything, "create-op").Return(errNotDone).Times(2)
+	env.OnActivity(wa.OperationCompleted, mock.Anything, "create-op").Return(nil).Once()
+	env.OnActivity(wa.GetWorkspaceURL, mock.Anything, mock.Anything).Return(testWorkbenchStatus(), nil)
+
+	env.ExecuteWorkflow(workflow.CreateWorkbench, testWorkbenchOption())
+	if err := env.GetWorkflowError(); err != nil {
+		t.Fatalf("workflow failed: %v", err)
+	}
+	var status googleapi.Status
+	if err := env.GetWorkflowResult(&status); err != nil {
+		t.Fatalf("failed to get workflow result: %v", err)
+	}
+	if status.URL == "" {
+		t.Errorf("expected URL of Workbench instance")
+	}
+	assertCalls(t, r, "Exist", "Create", "OperationCompleted", "GetWorkspaceURL")
+	if n := r.count("OperationCompleted"); n != 3 {
+		t.Errorf("expected 3 attempts to wait for operation, got %d", n)
+	}
+}
+
+func TestCreateWorkbenchAlreadyExists(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestWorkflowEnvironment()
+	r := recordActivities(env)
+	var wa *activity.WorkbenchActivity
+	env.OnActivity(wa.Exist, mock.Anything, mock.Anything).Return(true, nil)
+	env.OnActivity(wa.GetSteadyState, mock.Anything, mock.Anything).Return(testWorkbenchStatus(), nil)
+	env.OnActivity(wa.GetWorkspaceURL, mock.Anything, mock.Anything).Return(testWorkbenchStatus(), nil)
+
+	env.ExecuteWorkflow(workflow.CreateWorkbench, testWorkbenchOption())
+	if err := env.GetWorkflowError(); err != nil {
+		t.Fatalf("workflow failed: %v", err)
+	}
+	assertCalls(t, r, "Exist", "GetSteadyState", "GetWorkspaceURL")
+}
+
+func TestCreateWorkbenchStartsStoppedInstance(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestWorkflowEnvironment()
+	r := recordActivities(env)
+	var wa *activity.WorkbenchActivity
+	stopped := testWorkbenchStatus()
+	stopped.Status = "STOPPED"
+	env.OnActivity(wa.Exist, mock.Anything, mock.Anything).Return(true, nil)
+	// 停止処理中のインスタンスは停止が完了するまで待ってから起動することを確認する
+	env.OnActivity(wa.GetSteadyState, mock.Anything, mock.Anything).Return(nil, errors.New("workbench instance is in transition: STOPPING")).Once()
+	env.OnActivity(wa.GetSteadyState, mock.Anything, mock.Anything).Return(stopped, nil).Once()
+	env.OnActivity(wa.Start, mock.Anything, mock.Anything).Return("start-op", nil)
+	env.OnActivity(wa.OperationCompleted, mock.Anything, "start-op").Return(nil)
+	env.OnActivity(wa.GetWorkspaceURL, mock.Anything, mock.Anything).Return(testWorkbenchStatus(), nil)
+
+	env.ExecuteWorkflow(workflow.CreateWorkbench, testWorkbenchOption())
+	if err := env.GetWorkflowError(); err != nil {
+		t.Fatalf("workflow failed: %v", err)
+	}
+	assertCalls(t, r, "Exist", "GetSteadyState", "Start", "OperationCompleted", "GetWorkspaceURL")
+}
+
+func TestCreateWorkbenchUnexpectedState(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestWorkflowEnvironment()
+	r := recordActivities(env)
+	var wa *activity.WorkbenchActivity
+	env.OnActivity(wa.Exist, mock.Anything, mock.Anything).Return(true, nil)
+	env.OnActivity(wa.GetSteadyState, mock.Anything, mock.Anything).Return(nil,
+		temporal.NewNonRetryableApplicationError("workbench instance cannot be made running in DELETED state", activity.ErrUnexpectedState, nil))
+
+	env.ExecuteWorkflow(workflow.CreateWorkbench, testWorkbenchOption())
+	if err := env.GetWorkflowError(); !hasErrorType(err, activity.ErrUnexpectedState) {
+		t.Fatalf("expected %s error, got %v", activity.ErrUnexpectedState, err)
+	}
+	assertCalls(t, r, "Exist", "GetSteadyState")
+	if n := r.count("GetSteadyState"); n != 1 {
+		t.Errorf("expected unexpected state not to be retried, got %d attempts", n)
+	}
+}
+
+func TestCreateWorkbenchOperationNotDone(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestWorkflowEnvironment()
+	r := recordActivities(env)
+	var wa *activity.WorkbenchActivity
+	env.OnActivity(wa.Exist, mock.Anything, mock.Anything).Return(false, nil)
+	env.OnActivity(wa.Create, mock.Anything, mock.Anything).Return("create-op", nil)
+	env.OnActivity(wa.OperationCompleted, mock.Anything, "create-op").Return(errNotDone)
+
+	env.ExecuteWorkflow(workflow.CreateWorkbench, testWorkbenchOption())
+	if err := env.GetWorkflowError(); err == nil {
+		t.Fatal("expected workflow to fail when operation is never done")
+	}
+	assertCalls(t, r, "Exist", "Create", "OperationCompleted")
+	if n := r.count("OperationCompleted"); n != 72 {
+		t.Errorf("expected 72 attempts to wait for operation, got %d", n)
+	}
+}
+
+func TestCreateWorkbenchOperationFailed(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestWorkflowEnvironment()
+	r := recordActivities(env)
+	var wa *activity.WorkbenchActivity
+	env.OnActivity(wa.Exist, mock.Anything, mock.Anything).Return(false, nil)
+	env.OnActivity(wa.Create, mock.Anything, mock.Anything).Return("create-op", nil)
+	env.OnActivity(wa.OperationCompleted, mock.Anything, "create-op").Return(errOperationAborted)
+
+	env.ExecuteWorkflow(workflow.CreateWorkbench, testWorkbenchOption())
+	if err := env.GetWorkflowError(); !hasErrorType(err, activity.ErrLongRunningOperationFailed) {
+		t.Fatalf("expected %s error, got %v", activity.ErrLongRunningOperationFailed, err)
+	}
+	assertCalls(t, r, "Exist", "Create", "OperationCompleted")
+	if n := r.count("OperationCompleted"); n != 1 {
+		t.Errorf("expected failed operation not to be retried, got %d attempts", n)
+	}
+}
+
+func TestDeleteWorkbench(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestWorkflowEnvironment()
+	r := recordActivities(env)
+	var wa *activity.WorkbenchActivity
+	env.OnActivity(wa.Exist, mock.Anything, mock.Anything).Return(true, nil)
+	env.OnActivity(wa.Delete, mock.Anything, mock.Anything).Return("delete-op", nil)
+	env.OnActivity(wa.OperationCompleted, mock.Anything, "delete-op").Return(errNotDone).Once()
+	env.OnActivity(wa.OperationCompleted, mock.Anything, "delete-op").Return(nil).Once()
+
+	env.ExecuteWorkflow(workflow.DeleteWorkbench, testWorkbenchOption())
+	if err := env.GetWorkflowError(); err != nil {
+		t.Fatalf("workflow failed: %v", err)
+	}
+	assertCalls(t, r, "Exist", "Delete", "OperationCompleted")
+}
+
+func TestDeleteWorkbenchAlreadyDeleted(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestWorkflowEnvironment()
+	r := recordActivities(env)
+	var wa *activity.WorkbenchActivity
+	env.OnActivity(wa.Exist, mock.Anything, mock.Anything).Return(false, nil)
+
+	env.ExecuteWorkflow(workflow.DeleteWorkbench, testWorkbenchOption())
+	if err := env.GetWorkflowError(); err != nil {
+		t.Fatalf("workflow failed: %v", err)
+	}
+	assertCalls(t, r, "Exist")
+}
+
+func TestDeleteWorkbenchExistFailed(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestWorkflowEnvironment()
+	r := recordActivities(env)
+	var wa *activity.WorkbenchActivity
+	env.OnActivity(wa.Exist, mock.Anything, mock.Anything).Return(false,
+		temporal.NewNonRetryableApplicationError("permission denied to operate workbench instance", activity.ErrPermissionDenied, errors.New("permission denied")))
+
+	// 存在確認に失敗した場合は削除済みとみなさずに、型付きのエラーで失敗することを確認する
+	env.ExecuteWorkflow(workflow.DeleteWorkbench, testWorkbenchOption())
+	if err := env.GetWorkflowError(); !hasErrorType(err, activity.ErrPermissionDenied) {
+		t.Fatalf("expected %s error, got %v", activity.ErrPermissionDenied, err)
+	}
+	assertCalls(t, r, "Exist")
+	if n := r.count("Exist"); n != 1 {
+		t.Errorf("expected permission denied not to be retried, got %d attempts", n)
+	}
+}
+
+func TestDeleteWorkbenchOperationNotDone(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestWorkflowEnvironment()
+	r := recordActivities(env)
+	var wa *activity.WorkbenchActivity
+	env.OnActivity(wa.Exist, mock.Anything, mock.Anything).Return(true, nil)
+	env.OnActivity(wa.Delete, mock.Anything, mock.Anything).Return("delete-op", nil)
+	env.OnActivity(wa.OperationCompleted, mock.Anything, "delete-op").Return(errNotDone)
+
+	env.ExecuteWorkflow(workflow.DeleteWorkbench, testWorkbenchOption())
+	if err := env.GetWorkflowError(); err == nil {
+		t.Fatal("expected workflow to fail when operation is never done")
+	}
+	if n := r.count("OperationCompleted"); n != 36 {
+		t.Errorf("expected 36 attempts to wait for operation, got %d", n)
+	}
+}
+
+func TestStartWorkbench(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestWorkflowEnvironment()
+	r := recordActivities(env)
+	var wa *activity.WorkbenchActivity
+	env.OnActivity(wa.Exist, mock.Anything, mock.Anything).Return(true, nil)
+	env.OnActivity(wa.Start, mock.Anything, mock.Anything).Return("start-op", nil)
+	env.OnActivity(wa.OperationCompleted, mock.Anything, "start-op").Return(nil)
+	env.OnActivity(wa.GetWorkspaceURL, mock.Anything, mock.Anything).Return(testWorkbenchStatus(), nil)
+
+	env.ExecuteWorkflow(workflow.StartWorkbench, testWorkbenchOption())
+	if err := env.GetWorkflowError(); err != nil {
+		t.Fatalf("workflow failed: %v", err)
+	}
+	assertCalls(t, r, "Exist", "Start", "OperationCompleted", "GetWorkspaceURL")
+}
+
+func TestStartWorkbenchOperationFailed(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestWorkflowEnvironment()
+	r := recordActivities(env)
+	var wa *activity.WorkbenchActivity
+	env.OnActivity(wa.Exist, mock.Anything, mock.Anything).Return(true, nil)
+	env.OnActivity(wa.Start, mock.Anything, mock.Anything).Return("start-op", nil)
+	env.OnActivity(wa.OperationCompleted, mock.Anything, "start-op").Return(errOperationAborted)
+
+	env.ExecuteWorkflow(workflow.StartWorkbench, testWorkbenchOption())
+	if err := env.GetWorkflowError(); !hasErrorType(err, activity.ErrLongRunningOperationFailed) {
+		t.Fatalf("expected %s error, got %v", activity.ErrLongRunningOperationFailed, err)
+	}
+	assertCalls(t, r, "Exist", "Start", "OperationCompleted")
+}
+
+func TestStopWorkbench(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestWorkflowEnvironment()
+	r := recordActivities(env)
+	var wa *activity.WorkbenchActivity
+	env.OnActivity(wa.Exist, mock.Anything, mock.Anything).Return(true, nil)
+	env.OnActivity(wa.Stop, mock.Anything, mock.Anything).Return("stop-op", nil)
+	env.OnActivity(wa.OperationCompleted, mock.Anything, "stop-op").Return(nil)
+
+	env.ExecuteWorkflow(workflow.StopWorkbench, testWorkbenchOption())
+	if err := env.GetWorkflowError(); err != nil {
+		t.Fatalf("workflow failed: %v", err)
+	}
+	assertCalls(t, r, "Exist", "Stop", "OperationCompleted")
+}
+
+func TestStopWorkbenchOperationNotDone(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestWorkflowEnvironment()
+	r := recordActivities(env)
+	var wa *activity.WorkbenchActivity
+	env.OnActivity(wa.Exist, mock.Anything, mock.Anything).Return(true, nil)
+	env.OnActivity(wa.Stop, mock.Anything, mock.Anything).Return("stop-op", nil)
+	env.OnActivity(wa.OperationCompleted, mock.Anything, "stop-op").Return(errNotDone)
+
+	env.ExecuteWorkflow(workflow.StopWorkbench, testWorkbenchOption())
+	if err := env.GetWorkflowError(); err == nil {
+		t.Fatal("expected workflow to fail when operation is never done")
+	}
+	if n := r.count("OperationCompleted"); n != 72 {
+		t.Errorf("expected 72 attempts to wait for operation, got %d", n)
+	}
+}
+
+// machineType matches the activity option requesting the machine type.
+func machineType(t string) interface{} {
+	return mock.MatchedBy(func(option *googleapi.Option) bool { return option.MachineType == t })
+}
+
+// mockResize mocks the activities to resize the instance from n1-standard-4 to n1-highmem-8 and to roll it back.
+// The mocks for the failure are registered by the tests before these, so that they take precedence.
+func mockResize(env *testsuite.TestWorkflowEnvironment, state googleapi.InstanceState) {
+	var wa *activity.WorkbenchActivity
+	current := testWorkbenchStatus()
+	current.Status = state
+	current.MachineType = "n1-standard-4"
+	env.OnActivity(wa.Exist, mock.Anything, mock.Anything).Return(true, nil)
+	env.OnActivity(wa.GetSteadyState, mock.Anything, mock.Anything).Return(current, nil)
+	env.OnActivity(wa.Stop, mock.Anything, mock.Anything).Return("stop-op", nil)
+	env.OnActivity(wa.SetMachineType, mock.Anything, machineType("n1-highmem-8")).Return("resize-op", nil)
+	env.OnActivity(wa.SetMachineType, mock.Anything, machineType("n1-standard-4")).Return("rollback-op", nil)
+	env.OnActivity(wa.Start, mock.Anything, mock.Anything).Return("start-op", nil)
+	env.OnActivity(wa.OperationCompleted, mock.Anything, mock.Anything).Return(nil)
+	env.OnActivity(wa.GetWorkspaceURL, mock.Anything, mock.Anything).Return(testWorkbenchStatus(), nil)
+	env.OnActivity(wa.Describe, mock.Anything, mock.Anything).Return(current, nil)
+}
+
+func testResizeOption() *googleapi.Option {
+	option := testWorkbenchOption()
+	option.MachineType = "n1-highmem-8"
+	return option
+}
+
+func TestResizeWorkbench(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestWorkflowEnvironment()
+	r := recordActivities(env)
+	mockResize(env, googleapi.InstanceStateActive)
+
+	env.ExecuteWorkflow(workflow.ResizeWorkbench, testResizeOption())
+	if err := env.GetWorkflowError(); err != nil {
+		t.Fatalf("workflow failed: %v", err)
+	}
+	assertCalls(t, r, "Exist", "GetSteadyState", "Stop", "OperationCompleted", "SetMachineType", "OperationCompleted",
+		"Start", "OperationCompleted", "GetWorkspaceURL")
+}
+
+func TestResizeWorkbenchStopped(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestWorkflowEnvironment()
+	r := recordActivities(env)
+	mockResize(env, googleapi.InstanceStateStopped)
+
+	// 停止しているインスタンスは停止したままマシンタイプだけを変更することを確認する
+	env.ExecuteWorkflow(workflow.ResizeWorkbench, testResizeOption())
+	if err := env.GetWorkflowError(); err != nil {
+		t.Fatalf("workflow failed: %v", err)
+	}
+	assertCalls(t, r, "Exist", "GetSteadyState", "SetMachineType", "OperationCompleted", "Describe")
+}
+
+func TestResizeWorkbenchRollback(t *testing.T) {
+	var wa *activity.WorkbenchActivity
+	quotaExceeded := temporal.NewNonRetryableApplicationError("resource exhausted in the zone", activity.ErrResourceExhausted, errors.New("quota exceeded"))
+
+	tests := []struct {
+		name    string
+		setup   func(env *testsuite.TestWorkflowEnvironment)
+		errType string
+		calls   []string
+	}{
+		{
+			name: "request rejected",
+			setup: func(env *testsuite.TestWorkflowEnvironment) {
+				env.OnActivity(wa.SetMachineType, mock.Anything, machineType("n1-highmem-8")).Return("", quotaExceeded)
+			},
+			errType: activity.ErrResourceExhausted,
+			calls: []string{"Exist", "GetSteadyState", "Stop", "OperationCompleted", "SetMachineType",
+				"GetSteadyState", "SetMachineType", "OperationCompleted", "Start", "OperationCompleted"},
+		},
+		{
+			name: "operation aborted",
+			setup: func(env *testsuite.TestWorkflowEnvironment) {
+				env.OnActivity(wa.OperationCompleted, mock.Anything, "resize-op").Return(errOperationAborted)
+			},
+			errType: activity.ErrLongRunningOperationFailed,
+			calls: []string{"Exist", "GetSteadyState", "Stop", "OperationCompleted", "SetMachineType", "OperationCompleted",
+				"GetSteadyState", "SetMachineType", "OperationCompleted", "Start", "OperationCompleted"},
+		},
+		{
+			name: "start rejected",
+			setup: func(env *testsuite.TestWorkflowEnvironment) {
+				env.OnActivity(wa.OperationCompleted, mock.Anything, "start-op").Return(errOperationAborted).Once()
+			},
+			errType: activity.ErrLongRunningOperationFailed,
+			calls: []string{"Exist", "GetSteadyState", "Stop", "OperationCompleted", "SetMachineType", "OperationCompleted",
+				"Start", "OperationCompleted", "GetSteadyState", "SetMachineType", "OperationCompleted", "Start", "OperationCompleted"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var ts testsuite.WorkflowTestSuite
+			env := ts.NewTestWorkflowEnvironment()
+			r := recordActivities(env)
+			tt.setup(env)
+			mockResize(env, googleapi.InstanceStateActive)
+
+			// 拒否されたマシンタイプは元に戻し、起動していたインスタンスを起動し直してから型付きのエラーで失敗することを確認する
+			env.ExecuteWorkflow(workflow.ResizeWorkbench, testResizeOption())
+			if err := env.GetWorkflowError(); !hasErrorType(err, tt.errType) {
+				t.Fatalf("expected %s error, got %v", tt.errType, err)
+			}
+			assertCalls(t, r, tt.calls...)
+		})
+	}
+}
+
+func TestResizeWorkbenchRestartsAfterFailure(t *testing.T) {
+	var wa *activity.WorkbenchActivity
+	active := testWorkbenchStatus()
+	active.MachineType = "n1-standard-4"
+	stopped := testWorkbenchStatus()
+	stopped.Status = googleapi.InstanceStateStopped
+	stopped.MachineType = "n1-standard-4"
+
+	tests := []struct {
+		name  string
+		setup func(env *testsuite.TestWorkflowEnvironment)
+		calls []string
+	}{
+		{
+			name: "request failed",
+			setup: func(env *testsuite.TestWorkflowEnvironment) {
+				env.OnActivity(wa.SetMachineType, mock.Anything, machineType("n1-highmem-8")).Return("", errors.New("service unavailable"))
+			},
+			calls: []string{"Exist", "GetSteadyState", "Stop", "OperationCompleted", "SetMachineType",
+				"GetSteadyState", "Start", "OperationCompleted"},
+		},
+		{
+			name: "operation not done",
+			setup: func(env *testsuite.TestWorkflowEnvironment) {
+				env.OnActivity(wa.OperationCompleted, mock.Anything, "resize-op").Return(errNotDone)
+			},
+			calls: []string{"Exist", "GetSteadyState", "Stop", "OperationCompleted", "SetMachineType", "OperationCompleted",
+				"GetSteadyState", "Start", "OperationCompleted"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var ts testsuite.WorkflowTestSuite
+			env := ts.NewTestWorkflowEnvironment()
+			r := recordActivities(env)
+			env.OnActivity(wa.GetSteadyState, mock.Anything, mock.Anything).Return(active, nil).Once()
+			env.OnActivity(wa.GetSteadyState, mock.Anything, mock.Anything).Return(stopped, nil).Once()
+			tt.setup(env)
+			mockResize(env, googleapi.InstanceStateActive)
+
+			// マシンタイプの変更が拒否された以外の理由で失敗した場合も、起動していたインスタンスを起動し直してから失敗することを確認する
+			env.ExecuteWorkflow(workflow.ResizeWorkbench, testResizeOption())
+			if err := env.GetWorkflowError(); err == nil {
+				t.Fatal("expected workflow to fail")
+			}
+			assertCalls(t, r, tt.calls...)
+		})
+	}
+}
+
+// mockUpgrade mocks the activities to upgrade the instance, which has been upgraded once before, and to roll it back.
+// The mocks for the failure are registered by the tests before these, so that they take precedence.
+func mockUpgrade(env *testsuite.TestWorkflowEnvironment) {
+	var wa *activity.WorkbenchActivity
+	previous := googleapi.UpgradeHistoryEntry{Snapshot: "old-snapshot", Action: googleapi.UpgradeActionUpgrade, State: googleapi.UpgradeStateSucceeded}
+	current := testWorkbenchStatus()
+	current.UpgradeHistory = []googleapi.UpgradeHistoryEntry{previous}
+	upgraded := testWorkbenchStatus()
+	upgraded.UpgradeHistory = []googleapi.UpgradeHistoryEntry{previous,
+		{Snapshot: "new-snapshot", Action: googleapi.UpgradeActionUpgrade, State: googleapi.UpgradeStateFailed}}
+	env.OnActivity(wa.Exist, mock.Anything, mock.Anything).Return(true, nil)
+	env.OnActivity(wa.IsUpgradeable, mock.Anything, mock.Anything).Return(&googleapi.Upgradeability{Upgradeable: true, Version: "m110"}, nil)
+	env.OnActivity(wa.Describe, mock.Anything, mock.Anything).Return(current, nil).Once()
+	env.OnActivity(wa.Describe, mock.Anything, mock.Anything).Return(upgraded, nil)
+	env.OnActivity(wa.Upgrade, mock.Anything, mock.Anything).Return("upgrade-op", nil)
+	// 以前のアップグレードのスナップショットではなく、今回のアップグレードのスナップショットに戻すことを確認する
+	env.OnActivity(wa.Rollback, mock.Anything, mock.MatchedBy(func(option *googleapi.Option) bool {
+		return option.Snapshot == "new-snapshot"
+	})).Return("rollback-op", nil)
+	env.OnActivity(wa.OperationCompleted, mock.Anything, mock.Anything).Return(nil)
+	env.OnActivity(wa.GetWorkspaceURL, mock.Anything, mock.Anything).Return(testWorkbenchStatus(), nil)
+}
+
+func TestUpgradeWorkbench(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestWorkflowEnvironment()
+	r := recordActivities(env)
+	mockUpgrade(env)
+
+	env.ExecuteWorkflow(workflow.UpgradeWorkbench, testWorkbenchOption())
+	if err := env.GetWorkflowError(); err != nil {
+		t.Fatalf("workflow failed: %v", err)
+	}
+	assertCalls(t, r, "Exist", "IsUpgradeable", "Describe", "Upgrade", "OperationCompleted", "GetWorkspaceURL")
+}
+
+func TestUpgradeWorkbenchUpToDate(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestWorkflowEnvironment()
+	r := recordActivities(env)
+	var wa *activity.WorkbenchActivity
+	env.OnActivity(wa.IsUpgradeable, mock.Anything, mock.Anything).Return(&googleapi.Upgradeability{Upgradeable: false}, nil)
+	mockUpgrade(env)
+
+	env.ExecuteWorkflow(workflow.UpgradeWorkbench, testWorkbenchOption())
+	if err := env.GetWorkflowError(); err != nil {
+		t.Fatalf("workflow failed: %v", err)
+	}
+	assertCalls(t, r, "Exist", "IsUpgradeable", "Describe")
+}
+
+func TestUpgradeWorkbenchRollback(t *testing.T) {
+	var wa *activity.WorkbenchActivity
+	notActive := temporal.NewNonRetryableApplicationError("workbench instance cannot be made running in SUSPENDED state", activity.ErrUnexpectedState, nil)
+
+	tests := []struct {
+		name    string
+		setup   func(env *testsuite.TestWorkflowEnvironment)
+		errType string
+		calls   []string
+	}{
+		{
+			name: "operation aborted",
+			setup: func(env *testsuite.TestWorkflowEnvironment) {
+				env.OnActivity(wa.OperationCompleted, mock.Anything, "upgrade-op").Return(errOperationAborted)
+			},
+			errType: activity.ErrLongRunningOperationFailed,
+			calls: []string{"Exist", "IsUpgradeable", "Describe", "Upgrade", "OperationCompleted",
+				"Describe", "Rollback", "OperationCompleted", "GetWorkspaceURL"},
+		},
+		{
+			name: "not active after upgrade",
+			setup: func(env *testsuite.TestWorkflowEnvironment) {
+				env.OnActivity(wa.GetWorkspaceURL, mock.Anything, mock.Anything).Return(nil, notActive).Once()
+			},
+			errType: activity.ErrUnexpectedState,
+			calls: []string{"Exist", "IsUpgradeable", "Describe", "Upgrade", "OperationCompleted", "GetWorkspaceURL",
+				"Describe", "Rollback", "OperationCompleted", "GetWorkspaceURL"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var ts testsuite.WorkflowTestSuite
+			env := ts.NewTestWorkflowEnvironment()
+			r := recordActivities(env)
+			tt.setup(env)
+			mockUpgrade(env)
+
+			env.ExecuteWorkflow(workflow.UpgradeWorkbench, testWorkbenchOption())
+			if err := env.GetWorkflowError(); !hasErrorType(err, tt.errType) {
+				t.Fatalf("expected %s error, got %v", tt.errType, err)
+			}
+			assertCalls(t, r, tt.calls...)
+		})
+	}
+}
+
+func TestUpgradeWorkbenchNoSnapshot(t *testing.T) {
+	var ts testsuite.WorkflowTestSuite
+	env := ts.NewTestWorkflowEnvironment()
+	r := recordActivities(env)
+	var wa *activity.WorkbenchActivity
+	// アップグレードがスナップショットを取得する前に失敗した場合は、以前のスナップショットに戻さないことを確認する
+	env.OnActivity(wa.Describe, mock.Anything, mock.Anything).Return(testWorkbenchStatus(), nil)
+	env.OnActivity(wa.OperationCompleted, mock.Anything, "upgrade-op").Return(errOperationAborted)
+	mockUpgrade(env)
+
+	env.ExecuteWorkflow(workflow.UpgradeWorkbench, testWorkbenchOption())
+	if err := env.GetWorkflowError(); !hasErrorType(err, activity.ErrLongRunningOperationFailed) {
+		t.Fatalf("expected %s error, got %v", activity.ErrLongRunningOperationFailed, err)
+	}
+	assertCalls(t, r, "Exist", "IsUpgradeable", "Describe", "Upgrade", "OperationCompleted", "Describe")
+}